@@ -14,6 +14,7 @@ var (
 
 type MetricMaker interface {
 	Name() string
+	LogName() string
 	MakeMetric(
 		measurement string,
 		fields map[string]interface{},
@@ -23,6 +24,11 @@ type MetricMaker interface {
 	) telegraf.Metric
 }
 
+// errorCounter is implemented by the plugins counting their errors.
+type errorCounter interface {
+	IncrErrors()
+}
+
 func NewAccumulator(
 	maker MetricMaker,
 	metrics chan telegraf.Metric,
@@ -105,8 +111,11 @@ func (ac *accumulator) AddError(err error) {
 		return
 	}
 	NErrors.Incr(1)
+	if c, ok := ac.maker.(errorCounter); ok {
+		c.IncrErrors()
+	}
 	//TODO suppress/throttle consecutive duplicate errors?
-	log.Printf("E! Error in plugin [%s]: %s", ac.maker.Name(), err)
+	log.Printf("E! Error in plugin [%s]: %s", ac.maker.LogName(), err)
 }
 
 // SetPrecision takes two time.Duration objects. If the first is non-zero,
@@ -315,6 +315,9 @@ type TestMetricMaker struct {
 func (tm *TestMetricMaker) Name() string {
 	return "TestPlugin"
 }
+func (tm *TestMetricMaker) LogName() string {
+	return tm.Name()
+}
 func (tm *TestMetricMaker) MakeMetric(
 	measurement string,
 	fields map[string]interface{},
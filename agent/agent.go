@@ -4,26 +4,75 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/internal/buffer"
 	"github.com/influxdata/telegraf/internal/config"
 	"github.com/influxdata/telegraf/internal/models"
 	"github.com/influxdata/telegraf/selfstat"
 )
 
+// unsafeFileChars matches the characters replaced in the directory names of
+// the disk buffers.
+var unsafeFileChars = regexp.MustCompile(`[^\w.-]`)
+
 // Agent runs telegraf and collects data based on the given config
 type Agent struct {
 	Config *config.Config
+
+	inputStatus  []*pluginStatus
+	outputStatus []*pluginStatus
+	// flushNow requests an immediate flush of the outputs
+	flushNow chan struct{}
+	// metricC receives the metrics of the inputs, it is taken over by the
+	// agent of a reloaded configuration along with the service inputs
+	metricC chan telegraf.Metric
+
+	// reused are the outputs taken over from the agent of the previous
+	// configuration, which are already connected.
+	reused map[*models.RunningOutput]bool
+	// handedOver are the outputs taken over by the agent of a reloaded
+	// configuration, which are left open when the agent stops.
+	handedOver map[*models.RunningOutput]bool
+	// reusedInputs and handedOverInputs are the inputs taken over, the
+	// service inputs among them are already started and are left running
+	// when the agent stops.
+	reusedInputs     map[*models.RunningInput]bool
+	handedOverInputs map[*models.RunningInput]bool
 }
 
 // NewAgent returns an Agent struct based off the given Config
 func NewAgent(config *config.Config) (*Agent, error) {
 	a := &Agent{
-		Config: config,
+		Config:           config,
+		flushNow:         make(chan struct{}, 1),
+		metricC:          make(chan telegraf.Metric, 100),
+		reused:           make(map[*models.RunningOutput]bool),
+		handedOver:       make(map[*models.RunningOutput]bool),
+		reusedInputs:     make(map[*models.RunningInput]bool),
+		handedOverInputs: make(map[*models.RunningInput]bool),
+	}
+
+	var names []string
+	for _, input := range config.Inputs {
+		names = append(names, input.Name())
+	}
+	for _, id := range pluginIDs(names) {
+		a.inputStatus = append(a.inputStatus, newPluginStatus(id))
+	}
+	names = names[:0]
+	for _, output := range config.Outputs {
+		names = append(names, "outputs."+output.Name)
+	}
+	for _, id := range pluginIDs(names) {
+		a.outputStatus = append(a.outputStatus, newPluginStatus(id))
 	}
 
 	if !a.Config.Agent.OmitHostname {
@@ -39,41 +88,192 @@ func NewAgent(config *config.Config) (*Agent, error) {
 		config.Tags["host"] = a.Config.Agent.Hostname
 	}
 
+	if err := a.initPlugins(); err != nil {
+		return nil, err
+	}
+
 	return a, nil
 }
 
+// initPlugins initializes the plugins implementing telegraf.Initializer, so
+// that invalid configurations are reported before any plugin is started.
+func (a *Agent) initPlugins() error {
+	for _, input := range a.Config.Inputs {
+		if p, ok := input.Input.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("could not initialize input %s: %s", input.LogName(), err)
+			}
+		}
+	}
+	for _, processor := range a.Config.Processors {
+		if p, ok := processor.Processor.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("could not initialize processor %s: %s", processor.LogName(), err)
+			}
+		}
+	}
+	for _, aggregator := range a.Config.Aggregators {
+		if err := aggregator.Init(); err != nil {
+			return fmt.Errorf("could not initialize aggregator %s: %s", aggregator.LogName(), err)
+		}
+	}
+	for _, output := range a.Config.Outputs {
+		if p, ok := output.Output.(telegraf.Initializer); ok {
+			if err := p.Init(); err != nil {
+				return fmt.Errorf("could not initialize output %s: %s", output.LogName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// ReuseOutputs takes over the outputs of the previous agent identical to the
+// outputs of this agent, with their connections and buffered metrics, so
+// that they are kept across a reload of the configuration. This agent must
+// not be running yet, the previous agent may still be running and leaves
+// these outputs open when it stops. It returns the names of the outputs
+// taken over.
+func (a *Agent) ReuseOutputs(previous *Agent) []string {
+	// the disk buffers of the outputs are set up by the agent configuration
+	if a.Config.Agent.BufferDirectory != previous.Config.Agent.BufferDirectory ||
+		a.Config.Agent.MetricDiskBufferLimit != previous.Config.Agent.MetricDiskBufferLimit {
+		return nil
+	}
+
+	var names []string
+	for i, j := range config.MatchPlugins("outputs", previous.Config, a.Config) {
+		if j < 0 {
+			continue
+		}
+		o := previous.Config.Outputs[j]
+		// the buffers are sized by the agent options unless overridden
+		if o.MetricBatchSize != a.Config.Outputs[i].MetricBatchSize ||
+			o.MetricBufferLimit != a.Config.Outputs[i].MetricBufferLimit {
+			continue
+		}
+		a.Config.Outputs[i] = o
+		a.reused[o] = true
+		previous.handedOver[o] = true
+		names = append(names, a.outputStatus[i].id)
+	}
+	return names
+}
+
+// ReusePlugins takes over the plugins of the previous agent identical to the
+// plugins of this agent, as ReuseOutputs does for the outputs, so that the
+// inputs, processors and aggregators keep their state across a reload of the
+// configuration. The service inputs taken over are left running, with the
+// metrics channel of the previous agent. It returns the names of the
+// plugins taken over.
+func (a *Agent) ReusePlugins(previous *Agent) []string {
+	var names []string
+	for i, j := range config.MatchPlugins("inputs", previous.Config, a.Config) {
+		// the inputs add the global tags to their metrics
+		if j < 0 || !reflect.DeepEqual(a.Config.Tags, previous.Config.Tags) {
+			continue
+		}
+		input := previous.Config.Inputs[j]
+		a.Config.Inputs[i] = input
+		a.reusedInputs[input] = true
+		previous.handedOverInputs[input] = true
+		names = append(names, a.inputStatus[i].id)
+	}
+	if len(a.reusedInputs) > 0 {
+		a.metricC = previous.metricC
+	}
+
+	for i, j := range config.MatchPlugins("processors", previous.Config, a.Config) {
+		if j < 0 {
+			continue
+		}
+		a.Config.Processors[i] = previous.Config.Processors[j]
+		names = append(names, a.Config.Processors[i].LogName())
+	}
+
+	for i, j := range config.MatchPlugins("aggregators", previous.Config, a.Config) {
+		if j < 0 {
+			continue
+		}
+		a.Config.Aggregators[i] = previous.Config.Aggregators[j]
+		names = append(names, a.Config.Aggregators[i].LogName())
+	}
+
+	return append(names, a.ReuseOutputs(previous)...)
+}
+
 // Connect connects to all configured outputs
 func (a *Agent) Connect() error {
-	for _, o := range a.Config.Outputs {
+	dirs := diskBufferDirs(a.Config.Agent.BufferDirectory, a.Config.Outputs)
+	for i, o := range a.Config.Outputs {
+		if a.reused[o] {
+			continue
+		}
+		if a.Config.Agent.BufferDirectory != "" {
+			q, err := buffer.OpenDiskQueue(dirs[i], a.Config.Agent.MetricDiskBufferLimit)
+			if err != nil {
+				return fmt.Errorf("could not open the disk buffer of output %s: %s",
+					o.LogName(), err)
+			}
+			if n := q.Len(); n > 0 {
+				log.Printf("I! Output %s has %d metrics in its disk buffer\n",
+					o.LogName(), n)
+			}
+			o.SetDiskBuffer(q)
+		}
 		switch ot := o.Output.(type) {
 		case telegraf.ServiceOutput:
 			if err := ot.Start(); err != nil {
 				log.Printf("E! Service for output %s failed to start, exiting\n%s\n",
-					o.Name, err.Error())
+					o.LogName(), err.Error())
 				return err
 			}
 		}
 
-		log.Printf("D! Attempting connection to output: %s\n", o.Name)
+		log.Printf("D! Attempting connection to output: %s\n", o.LogName())
 		err := o.Output.Connect()
 		if err != nil {
 			log.Printf("E! Failed to connect to output %s, retrying in 15s, "+
-				"error was '%s' \n", o.Name, err)
+				"error was '%s' \n", o.LogName(), err)
 			time.Sleep(15 * time.Second)
 			err = o.Output.Connect()
 			if err != nil {
 				return err
 			}
 		}
-		log.Printf("D! Successfully connected to output: %s\n", o.Name)
+		log.Printf("D! Successfully connected to output: %s\n", o.LogName())
 	}
 	return nil
 }
 
+// diskBufferDirs returns the directories of the disk buffers of the outputs,
+// named after their alias if any, otherwise after the checksum of their
+// options so that they don't depend on the order of the outputs. Identical
+// outputs are numbered in configuration order.
+func diskBufferDirs(root string, outputs []*models.RunningOutput) []string {
+	count := make(map[string]int)
+	dirs := make([]string, len(outputs))
+	for i, o := range outputs {
+		name := o.LogName()
+		if o.Config.Alias == "" && o.Config.Checksum != "" {
+			name = "outputs." + o.Name + "-" + o.Config.Checksum
+		}
+		dir := name
+		if n := count[name]; n > 0 {
+			dir = fmt.Sprintf("%s#%d", name, n)
+		}
+		count[name]++
+		dirs[i] = filepath.Join(root, unsafeFileChars.ReplaceAllString(dir, "_"))
+	}
+	return dirs
+}
+
 // Close closes the connection to all configured outputs
 func (a *Agent) Close() error {
 	var err error
 	for _, o := range a.Config.Outputs {
+		if a.handedOver[o] {
+			continue
+		}
 		err = o.Output.Close()
 		switch ot := o.Output.(type) {
 		case telegraf.ServiceOutput:
@@ -88,7 +288,7 @@ func panicRecover(input *models.RunningInput) {
 		trace := make([]byte, 2048)
 		runtime.Stack(trace, true)
 		log.Printf("E! FATAL: Input [%s] panicked: %s, Stack:\n%s\n",
-			input.Name(), err, trace)
+			input.LogName(), err, trace)
 		log.Println("E! PLEASE REPORT THIS PANIC ON GITHUB with " +
 			"stack trace, configuration, and OS information: " +
 			"https://github.com/influxdata/telegraf/issues/new")
@@ -100,41 +300,127 @@ func panicRecover(input *models.RunningInput) {
 func (a *Agent) gatherer(
 	shutdown chan struct{},
 	input *models.RunningInput,
+	status *pluginStatus,
 	interval time.Duration,
 	metricC chan telegraf.Metric,
 ) {
 	defer panicRecover(input)
 
-	GatherTime := selfstat.RegisterTiming("gather",
-		"gather_time_ns",
-		map[string]string{"input": input.Config.Name},
-	)
+	gatherTags := map[string]string{"input": input.Config.Name}
+	if input.Config.Alias != "" {
+		gatherTags["alias"] = input.Config.Alias
+	}
+	GatherTime := selfstat.RegisterTiming("gather", "gather_time_ns", gatherTags)
+
+	acc := &statusAccumulator{
+		Accumulator: NewAccumulator(input, metricC),
+		status:      status,
+	}
+	acc.SetPrecision(a.precision(input), interval)
 
-	acc := NewAccumulator(input, metricC)
-	acc.SetPrecision(a.Config.Agent.Precision.Duration,
-		a.Config.Agent.Interval.Duration)
+	// the agent is aligned on its own interval, an input with another
+	// interval is aligned on its interval before its first gather
+	var wait time.Duration
+	if a.Config.Agent.RoundInterval {
+		wait = intervalAlignment(time.Now(), interval, a.Config.Agent.Interval.Duration)
+	}
+	// the ticker starts after the offset, shifting all the gathers
+	if !sleep(wait+a.collectionOffset(input), shutdown) {
+		return
+	}
 
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
+	jitter := a.collectionJitter(input)
 	for {
-		internal.RandomSleep(a.Config.Agent.CollectionJitter.Duration, shutdown)
+		internal.RandomSleep(jitter, shutdown)
 
-		start := time.Now()
-		gatherWithTimeout(shutdown, input, acc, interval)
-		elapsed := time.Since(start)
+		// paused inputs skip their gather until resumed
+		if !status.isPaused() {
+			start := time.Now()
+			gatherWithTimeout(shutdown, input, acc, interval)
+			elapsed := time.Since(start)
 
-		GatherTime.Incr(elapsed.Nanoseconds())
+			GatherTime.Incr(elapsed.Nanoseconds())
+			status.done(start, nil)
+		}
 
 		select {
 		case <-shutdown:
 			return
 		case <-ticker.C:
 			continue
+		case <-status.trigger:
+			continue
 		}
 	}
 }
 
+// interval returns the gather interval of the input, the interval of the
+// agent unless the input has its own.
+func (a *Agent) interval(input *models.RunningInput) time.Duration {
+	if input.Config.Interval != 0 {
+		return input.Config.Interval
+	}
+	return a.Config.Agent.Interval.Duration
+}
+
+// collectionJitter returns the collection jitter of the input, the jitter of
+// the agent unless the input has its own.
+func (a *Agent) collectionJitter(input *models.RunningInput) time.Duration {
+	if input.Config.CollectionJitter != 0 {
+		return input.Config.CollectionJitter
+	}
+	return a.Config.Agent.CollectionJitter.Duration
+}
+
+// collectionOffset returns the collection offset of the input, the offset of
+// the agent unless the input has its own.
+func (a *Agent) collectionOffset(input *models.RunningInput) time.Duration {
+	if input.Config.CollectionOffset != 0 {
+		return input.Config.CollectionOffset
+	}
+	return a.Config.Agent.CollectionOffset.Duration
+}
+
+// sleep sleeps for the given duration, and returns false if the shutdown
+// channel is closed before.
+func sleep(d time.Duration, shutdown chan struct{}) bool {
+	if d <= 0 {
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-shutdown:
+		return false
+	}
+}
+
+// intervalAlignment returns the time to wait from now for the gathers of an
+// input to be rounded to its interval, ie, on :00 for a 1m interval. It is
+// zero when the interval divides the interval of the agent, the agent being
+// rounded to its interval when it starts.
+func intervalAlignment(now time.Time, interval, agentInterval time.Duration) time.Duration {
+	if interval <= 0 || agentInterval%interval == 0 {
+		return 0
+	}
+	i := int64(interval)
+	return time.Duration(i - (now.UnixNano() % i))
+}
+
+// precision returns the precision of the metrics of the input, the
+// precision of the agent unless the input has its own.
+func (a *Agent) precision(input *models.RunningInput) time.Duration {
+	if input.Config.Precision != 0 {
+		return input.Config.Precision
+	}
+	return a.Config.Agent.Precision.Duration
+}
+
 // gatherWithTimeout gathers from the given input, with the given timeout.
 //   when the given timeout is reached, gatherWithTimeout logs an error message
 //   but continues waiting for it to return. This is to avoid leaving behind
@@ -148,9 +434,10 @@ func gatherWithTimeout(
 ) {
 	ticker := time.NewTicker(timeout)
 	defer ticker.Stop()
-	done := make(chan error)
+	// the gather may outlast the shutdown, ie, of a reloaded configuration
+	done := make(chan error, 1)
 	go func() {
-		done <- input.Input.Gather(acc)
+		done <- input.Gather(acc)
 	}()
 
 	for {
@@ -198,14 +485,13 @@ func (a *Agent) Test() error {
 		}
 
 		acc := NewAccumulator(input, metricC)
-		acc.SetPrecision(a.Config.Agent.Precision.Duration,
-			a.Config.Agent.Interval.Duration)
+		acc.SetPrecision(a.precision(input), a.interval(input))
 		input.SetTrace(true)
 		input.SetDefaultTags(a.Config.Tags)
 
 		fmt.Printf("* Plugin: %s, Collection 1\n", input.Name())
 		if input.Config.Interval != 0 {
-			fmt.Printf("* Internal: %s\n", input.Config.Interval)
+			fmt.Printf("* Interval: %s\n", input.Config.Interval)
 		}
 
 		if err := input.Input.Gather(acc); err != nil {
@@ -232,26 +518,109 @@ func (a *Agent) flush() {
 	var wg sync.WaitGroup
 
 	wg.Add(len(a.Config.Outputs))
-	for _, o := range a.Config.Outputs {
-		go func(output *models.RunningOutput) {
+	for i, o := range a.Config.Outputs {
+		go func(output *models.RunningOutput, status *pluginStatus) {
 			defer wg.Done()
-			err := output.Write()
-			if err != nil {
-				log.Printf("E! Error writing to output [%s]: %s\n",
-					output.Name, err.Error())
-			}
-		}(o)
+			flushOutput(output, status)
+		}(o, a.outputStatus[i])
 	}
 
 	wg.Wait()
 }
 
+// flushOutput writes the cached metrics of an output.
+func flushOutput(output *models.RunningOutput, status *pluginStatus) {
+	start := time.Now()
+	err := output.Write()
+	if err != nil {
+		log.Printf("E! Error writing to output [%s]: %s\n",
+			output.LogName(), err.Error())
+	}
+	status.done(start, err)
+}
+
+// flushInterval returns the flush interval of the output, the interval of
+// the agent unless the output has its own.
+func (a *Agent) flushInterval(output *models.RunningOutput) time.Duration {
+	if output.Config.FlushInterval != 0 {
+		return output.Config.FlushInterval
+	}
+	return a.Config.Agent.FlushInterval.Duration
+}
+
+// flushJitter returns the flush jitter of the output, the jitter of the
+// agent unless the output has its own.
+func (a *Agent) flushJitter(output *models.RunningOutput) time.Duration {
+	if output.Config.FlushJitter != 0 {
+		return output.Config.FlushJitter
+	}
+	return a.Config.Agent.FlushJitter.Duration
+}
+
+// outputFlusher flushes an output on its own flush interval, or when
+// requested on flushNow, independently of the other outputs so that a slow
+// output does not delay them.
+func (a *Agent) outputFlusher(
+	shutdown chan struct{},
+	output *models.RunningOutput,
+	status *pluginStatus,
+	flushNow chan struct{},
+) {
+	ticker := time.NewTicker(a.flushInterval(output))
+	defer ticker.Stop()
+	semaphore := make(chan struct{}, 1)
+	// a requested flush waits for the ongoing one, as the metrics it is
+	// requested for may have been added after that one started
+	waiting := make(chan struct{}, 1)
+	for {
+		select {
+		case <-shutdown:
+			return
+		case <-ticker.C:
+			go func() {
+				select {
+				case semaphore <- struct{}{}:
+					internal.RandomSleep(a.flushJitter(output), shutdown)
+					flushOutput(output, status)
+					<-semaphore
+				default:
+					// skipping this flush because one is already happening
+					log.Printf("W! Skipping a scheduled flush of output [%s] "+
+						"because there is already a flush ongoing.", output.LogName())
+				}
+			}()
+		case <-flushNow:
+			select {
+			case waiting <- struct{}{}:
+				go func() {
+					semaphore <- struct{}{}
+					<-waiting
+					flushOutput(output, status)
+					<-semaphore
+				}()
+			default:
+				// a flush is already waiting for the ongoing one
+			}
+		}
+	}
+}
+
 // flusher monitors the metrics input channel and flushes on the minimum interval
 func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, aggC chan telegraf.Metric) error {
 	// Inelegant, but this sleep is to allow the Gather threads to run, so that
 	// the flusher will flush after metrics are collected.
 	time.Sleep(time.Millisecond * 300)
 
+	// each output is flushed on its own schedule, and once it has a full
+	// batch, so that a slow output does not hold up the metrics of the others
+	var flushNow []chan struct{}
+	for i, o := range a.Config.Outputs {
+		c := make(chan struct{}, 1)
+		flushNow = append(flushNow, c)
+		o.BatchReady = c
+		go a.outputFlusher(shutdown, o, a.outputStatus[i], c)
+	}
+
 	// create an output metric channel and a gorouting that continuously passes
 	// each metric onto the output plugins & aggregators.
 	outMetricC := make(chan telegraf.Metric, 100)
@@ -320,8 +689,6 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 		}
 	}()
 
-	ticker := time.NewTicker(a.Config.Agent.FlushInterval.Duration)
-	semaphore := make(chan struct{}, 1)
 	for {
 		select {
 		case <-shutdown:
@@ -330,19 +697,14 @@ func (a *Agent) flusher(shutdown chan struct{}, metricC chan telegraf.Metric, ag
 			wg.Wait()
 			a.flush()
 			return nil
-		case <-ticker.C:
-			go func() {
+		case <-a.flushNow:
+			for _, c := range flushNow {
 				select {
-				case semaphore <- struct{}{}:
-					internal.RandomSleep(a.Config.Agent.FlushJitter.Duration, shutdown)
-					a.flush()
-					<-semaphore
+				case c <- struct{}{}:
 				default:
-					// skipping this flush because one is already happening
-					log.Println("W! Skipping a scheduled flush because there is" +
-						" already a flush ongoing.")
+					// a flush of this output is already requested
 				}
-			}()
+			}
 		case metric := <-metricC:
 			// NOTE potential bottleneck here as we put each metric through the
 			// processors serially.
@@ -367,27 +729,49 @@ func (a *Agent) Run(shutdown chan struct{}) error {
 		a.Config.Agent.Hostname, a.Config.Agent.FlushInterval.Duration)
 
 	// channel shared between all input threads for accumulating metrics
-	metricC := make(chan telegraf.Metric, 100)
+	metricC := a.metricC
 	aggC := make(chan telegraf.Metric, 100)
 
 	// Start all ServicePlugins
 	for _, input := range a.Config.Inputs {
+		if a.reusedInputs[input] {
+			// started by the agent of the previous configuration
+			continue
+		}
 		input.SetDefaultTags(a.Config.Tags)
 		switch p := input.Input.(type) {
 		case telegraf.ServiceInput:
 			acc := NewAccumulator(input, metricC)
 			// Service input plugins should set their own precision of their
-			// metrics.
-			acc.SetPrecision(time.Nanosecond, 0)
+			// metrics, unless the precision of the input is configured.
+			if input.Config.Precision != 0 {
+				acc.SetPrecision(input.Config.Precision, 0)
+			} else {
+				acc.SetPrecision(time.Nanosecond, 0)
+			}
 			if err := p.Start(acc); err != nil {
 				log.Printf("E! Service for input %s failed to start, exiting\n%s\n",
-					input.Name(), err.Error())
+					input.LogName(), err.Error())
 				return err
 			}
-			defer p.Stop()
+			defer func(input *models.RunningInput, p telegraf.ServiceInput) {
+				if !a.handedOverInputs[input] {
+					p.Stop()
+				}
+			}(input, p)
 		}
 	}
 
+	if a.Config.Agent.ControlSocket != "" {
+		cs, err := startControlServer(a, a.Config.Agent.ControlSocket)
+		if err != nil {
+			log.Printf("E! Unable to start control socket %s: %s\n",
+				a.Config.Agent.ControlSocket, err.Error())
+			return err
+		}
+		defer cs.Close()
+	}
+
 	// Round collection to nearest interval by sleeping
 	if a.Config.Agent.RoundInterval {
 		i := int64(a.Config.Agent.Interval.Duration)
@@ -415,16 +799,11 @@ func (a *Agent) Run(shutdown chan struct{}) error {
 	}
 
 	wg.Add(len(a.Config.Inputs))
-	for _, input := range a.Config.Inputs {
-		interval := a.Config.Agent.Interval.Duration
-		// overwrite global interval if this plugin has it's own.
-		if input.Config.Interval != 0 {
-			interval = input.Config.Interval
-		}
-		go func(in *models.RunningInput, interv time.Duration) {
+	for i, input := range a.Config.Inputs {
+		go func(in *models.RunningInput, status *pluginStatus, interv time.Duration) {
 			defer wg.Done()
-			a.gatherer(shutdown, in, interv, metricC)
-		}(input, interval)
+			a.gatherer(shutdown, in, status, interv, metricC)
+		}(input, a.inputStatus[i], a.interval(input))
 	}
 
 	wg.Wait()
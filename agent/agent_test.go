@@ -1,16 +1,25 @@
 package agent
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/internal/models"
 
 	// needing to load the plugins
 	_ "github.com/influxdata/telegraf/plugins/inputs/all"
 	// needing to load the outputs
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
+	// needing to load the processors and aggregators
+	_ "github.com/influxdata/telegraf/plugins/aggregators/all"
+	_ "github.com/influxdata/telegraf/plugins/processors/all"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAgent_OmitHostname(t *testing.T) {
@@ -109,3 +118,181 @@ func TestAgent_LoadOutput(t *testing.T) {
 	a, _ = NewAgent(c)
 	assert.Equal(t, 3, len(a.Config.Outputs))
 }
+
+func newReloadAgent(t *testing.T, conf string) *Agent {
+	f, err := ioutil.TempFile("", "telegraf")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(conf)
+	require.NoError(t, err)
+	f.Close()
+
+	c := config.NewConfig()
+	c.Agent.OmitHostname = true
+	require.NoError(t, c.LoadConfig(f.Name()))
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+	return a
+}
+
+func TestAgent_ReuseOutputs(t *testing.T) {
+	previous := newReloadAgent(t, `
+[[outputs.file]]
+  files = ["/dev/null"]
+[[outputs.file]]
+  files = ["stdout"]
+`)
+	a := newReloadAgent(t, `
+[[outputs.file]]
+  files = ["stderr"]
+[[outputs.file]]
+  files = ["stdout"]
+`)
+
+	assert.Equal(t, []string{"outputs.file#1"}, a.ReuseOutputs(previous))
+	assert.True(t, a.Config.Outputs[1] == previous.Config.Outputs[1])
+	assert.False(t, a.Config.Outputs[0] == previous.Config.Outputs[1])
+	assert.True(t, previous.handedOver[previous.Config.Outputs[1]])
+	assert.False(t, previous.handedOver[previous.Config.Outputs[0]])
+
+	// the buffers are sized by the agent configuration
+	a = newReloadAgent(t, `
+[agent]
+  metric_buffer_limit = 100
+[[outputs.file]]
+  files = ["stdout"]
+`)
+	assert.Empty(t, a.ReuseOutputs(previous))
+
+	// unless overridden by the output
+	previous = newReloadAgent(t, `
+[[outputs.file]]
+  files = ["stdout"]
+  metric_buffer_limit = 200
+`)
+	a = newReloadAgent(t, `
+[agent]
+  metric_buffer_limit = 100
+[[outputs.file]]
+  files = ["stdout"]
+  metric_buffer_limit = 200
+`)
+	assert.Equal(t, []string{"outputs.file#0"}, a.ReuseOutputs(previous))
+	assert.Equal(t, 200, a.Config.Outputs[0].MetricBufferLimit)
+}
+
+func TestAgent_ReusePlugins(t *testing.T) {
+	const plugins = `
+[[inputs.socket_listener]]
+  service_address = "udp://127.0.0.1:0"
+[[aggregators.minmax]]
+  period = "30s"
+[[outputs.file]]
+  files = ["stdout"]
+`
+	previous := newReloadAgent(t, plugins+`
+[[processors.override]]
+  name_suffix = "_a"
+`)
+	a := newReloadAgent(t, plugins+`
+[[processors.override]]
+  name_suffix = "_b"
+`)
+
+	assert.Equal(t, []string{"inputs.socket_listener#0", "aggregators.minmax", "outputs.file#0"},
+		a.ReusePlugins(previous))
+	assert.True(t, a.Config.Inputs[0] == previous.Config.Inputs[0])
+	assert.True(t, a.Config.Aggregators[0] == previous.Config.Aggregators[0])
+	assert.False(t, a.Config.Processors[0] == previous.Config.Processors[0])
+	// the service input keeps running with the metrics channel
+	assert.True(t, a.reusedInputs[a.Config.Inputs[0]])
+	assert.True(t, previous.handedOverInputs[previous.Config.Inputs[0]])
+	assert.True(t, a.metricC == previous.metricC)
+
+	// the inputs add the global tags
+	a = newReloadAgent(t, plugins+`
+[global_tags]
+  dc = "us-east-1"
+[[processors.override]]
+  name_suffix = "_a"
+`)
+	assert.Equal(t, []string{"processors.override", "aggregators.minmax", "outputs.file#0"},
+		a.ReusePlugins(previous))
+	assert.False(t, a.Config.Inputs[0] == previous.Config.Inputs[0])
+	assert.False(t, a.metricC == previous.metricC)
+}
+
+func TestAgent_IntervalAlignment(t *testing.T) {
+	now := time.Unix(1500000013, 0)
+
+	// aligned with the agent
+	assert.Equal(t, time.Duration(0), intervalAlignment(now, 10*time.Second, 10*time.Second))
+	assert.Equal(t, time.Duration(0), intervalAlignment(now, 5*time.Second, 10*time.Second))
+
+	// aligned on their own interval
+	assert.Equal(t, 47*time.Second, intervalAlignment(now, time.Minute, 10*time.Second))
+	assert.Equal(t, 2*time.Second, intervalAlignment(now, 3*time.Second, 10*time.Second))
+}
+
+func TestAgent_CollectionJitterAndOffset(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.CollectionJitter.Duration = time.Second
+	c.Agent.CollectionOffset.Duration = 2 * time.Second
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	input := &models.RunningInput{Config: &models.InputConfig{Name: "cpu"}}
+	assert.Equal(t, time.Second, a.collectionJitter(input))
+	assert.Equal(t, 2*time.Second, a.collectionOffset(input))
+
+	input.Config.CollectionJitter = 3 * time.Second
+	input.Config.CollectionOffset = 4 * time.Second
+	assert.Equal(t, 3*time.Second, a.collectionJitter(input))
+	assert.Equal(t, 4*time.Second, a.collectionOffset(input))
+}
+
+func TestAgent_DiskBufferDirs(t *testing.T) {
+	newOutput := func(alias, checksum string) *models.RunningOutput {
+		return &models.RunningOutput{
+			Name: "influxdb",
+			Config: &models.OutputConfig{
+				Name:     "influxdb",
+				Alias:    alias,
+				Checksum: checksum,
+			},
+		}
+	}
+	outputs := []*models.RunningOutput{
+		newOutput("", "0a1b"),
+		newOutput("primary", "2c3d"),
+		newOutput("", "4e5f"),
+		newOutput("", "0a1b"),
+	}
+	assert.Equal(t, []string{
+		filepath.Join("buffer", "outputs.influxdb-0a1b"),
+		filepath.Join("buffer", "outputs.influxdb__primary"),
+		filepath.Join("buffer", "outputs.influxdb-4e5f"),
+		filepath.Join("buffer", "outputs.influxdb-0a1b_1"),
+	}, diskBufferDirs("buffer", outputs))
+
+	// the directories do not depend on the order of the outputs
+	dirs := diskBufferDirs("buffer", outputs[2:3])
+	assert.Equal(t, filepath.Join("buffer", "outputs.influxdb-4e5f"), dirs[0])
+}
+
+func TestAgent_FlushIntervalAndJitter(t *testing.T) {
+	c := config.NewConfig()
+	c.Agent.FlushInterval.Duration = 10 * time.Second
+	c.Agent.FlushJitter.Duration = time.Second
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	output := &models.RunningOutput{Config: &models.OutputConfig{Name: "file"}}
+	assert.Equal(t, 10*time.Second, a.flushInterval(output))
+	assert.Equal(t, time.Second, a.flushJitter(output))
+
+	output.Config.FlushInterval = 30 * time.Second
+	output.Config.FlushJitter = 5 * time.Second
+	assert.Equal(t, 30*time.Second, a.flushInterval(output))
+	assert.Equal(t, 5*time.Second, a.flushJitter(output))
+}
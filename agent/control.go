@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/influxdata/telegraf/logger"
+)
+
+// ControlRequest is a command sent over the control socket. The supported
+// commands are:
+//   instances   list the loaded plugin instances and their option checksums
+//   status      list the inputs and outputs with their health
+//   gather      gather immediately from the inputs selected by Plugin
+//   flush       flush all outputs immediately
+//   log_level   change the log level to Level (debug, info, warn or error)
+//   pause       stop gathering from the inputs selected by Plugin
+//   resume      resume gathering from the inputs selected by Plugin
+// Plugin selects either a single instance by ID, ie, "inputs.http#1", or all
+// instances of a plugin, ie, "inputs.http". An empty Plugin selects all inputs.
+type ControlRequest struct {
+	Command string `json:"command"`
+	Plugin  string `json:"plugin,omitempty"`
+	Level   string `json:"level,omitempty"`
+}
+
+// ControlResponse is the answer of the agent to a ControlRequest.
+type ControlResponse struct {
+	Error     string                  `json:"error,omitempty"`
+	Instances []config.PluginInstance `json:"instances,omitempty"`
+	Plugins   []PluginStatus          `json:"plugins,omitempty"`
+}
+
+// controlServer answers requests on the agent control socket.
+type controlServer struct {
+	agent    *Agent
+	listener net.Listener
+}
+
+// startControlServer listens on the given unix socket path. A stale socket
+// left behind by a previous run is removed first.
+func startControlServer(a *Agent, path string) (*controlServer, error) {
+	if _, err := os.Stat(path); err == nil {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	s := &controlServer{
+		agent:    a,
+		listener: listener,
+	}
+	go s.serve()
+	return s, nil
+}
+
+func (s *controlServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			// listener was closed
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *controlServer) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	var req ControlRequest
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		resp.Error = fmt.Sprintf("invalid request: %s", err)
+	} else {
+		resp = s.execute(req)
+	}
+
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		log.Printf("E! Unable to answer control request %q: %s", req.Command, err)
+	}
+}
+
+func (s *controlServer) execute(req ControlRequest) ControlResponse {
+	switch req.Command {
+	case "instances":
+		return ControlResponse{Instances: s.agent.Config.Instances}
+	case "status":
+		var resp ControlResponse
+		for _, status := range s.agent.inputStatus {
+			resp.Plugins = append(resp.Plugins, status.status())
+		}
+		for _, status := range s.agent.outputStatus {
+			resp.Plugins = append(resp.Plugins, status.status())
+		}
+		return resp
+	case "gather", "pause", "resume":
+		var resp ControlResponse
+		for _, status := range s.agent.inputStatus {
+			if !status.matches(req.Plugin) {
+				continue
+			}
+			switch req.Command {
+			case "gather":
+				status.gatherNow()
+			case "pause":
+				status.setPaused(true)
+			case "resume":
+				status.setPaused(false)
+			}
+			resp.Plugins = append(resp.Plugins, status.status())
+		}
+		if len(resp.Plugins) == 0 {
+			return ControlResponse{Error: fmt.Sprintf("no input matches %q", req.Plugin)}
+		}
+		log.Printf("I! Control socket: %s %s", req.Command, req.Plugin)
+		return resp
+	case "flush":
+		select {
+		case s.agent.flushNow <- struct{}{}:
+		default:
+		}
+		return ControlResponse{}
+	case "log_level":
+		if err := logger.SetLevel(req.Level); err != nil {
+			return ControlResponse{Error: err.Error()}
+		}
+		log.Printf("I! Control socket: log level set to %s", req.Level)
+		return ControlResponse{}
+	default:
+		return ControlResponse{Error: fmt.Sprintf("unknown command %q", req.Command)}
+	}
+}
+
+func (s *controlServer) Close() error {
+	return s.listener.Close()
+}
+
+// SendControlRequest sends a request to the agent listening on the given
+// control socket and returns its response.
+func SendControlRequest(path string, req ControlRequest) (*ControlResponse, error) {
+	conn, err := net.DialTimeout("unix", path, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp ControlResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("agent returned error: %s", resp.Error)
+	}
+	return &resp, nil
+}
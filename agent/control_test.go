@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlServer_Instances(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "telegraf.sock")
+
+	c := config.NewConfig()
+	c.Instances = []config.PluginInstance{
+		{Name: "inputs.cpu", Checksum: "abc", Settings: map[string]string{}},
+	}
+	a, err := NewAgent(c)
+	require.NoError(t, err)
+
+	cs, err := startControlServer(a, path)
+	require.NoError(t, err)
+	defer cs.Close()
+
+	resp, err := SendControlRequest(path, ControlRequest{Command: "instances"})
+	require.NoError(t, err)
+	assert.Equal(t, c.Instances, resp.Instances)
+
+	_, err = SendControlRequest(path, ControlRequest{Command: "bogus"})
+	assert.Error(t, err)
+}
+
+func TestControlServer_PauseResume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "telegraf.sock")
+
+	a, err := NewAgent(config.NewConfig())
+	require.NoError(t, err)
+	for _, id := range pluginIDs([]string{"inputs.http", "inputs.http", "inputs.cpu"}) {
+		a.inputStatus = append(a.inputStatus, newPluginStatus(id))
+	}
+
+	cs, err := startControlServer(a, path)
+	require.NoError(t, err)
+	defer cs.Close()
+
+	resp, err := SendControlRequest(path,
+		ControlRequest{Command: "pause", Plugin: "inputs.http"})
+	require.NoError(t, err)
+	require.Len(t, resp.Plugins, 2)
+	assert.True(t, a.inputStatus[0].isPaused())
+	assert.True(t, a.inputStatus[1].isPaused())
+	assert.False(t, a.inputStatus[2].isPaused())
+
+	_, err = SendControlRequest(path,
+		ControlRequest{Command: "resume", Plugin: "inputs.http#1"})
+	require.NoError(t, err)
+	assert.True(t, a.inputStatus[0].isPaused())
+	assert.False(t, a.inputStatus[1].isPaused())
+
+	_, err = SendControlRequest(path,
+		ControlRequest{Command: "gather", Plugin: "inputs.mem"})
+	assert.Error(t, err)
+
+	resp, err = SendControlRequest(path, ControlRequest{Command: "status"})
+	require.NoError(t, err)
+	assert.Equal(t, "inputs.http#0", resp.Plugins[0].ID)
+	assert.True(t, resp.Plugins[0].Paused)
+	assert.True(t, resp.Plugins[2].Healthy)
+}
+
+func TestPluginStatus_Health(t *testing.T) {
+	s := newPluginStatus("inputs.cpu#0")
+	s.addError(errors.New("failed"))
+	s.done(time.Now(), nil)
+	assert.False(t, s.status().Healthy)
+	assert.Equal(t, "failed", s.status().LastError)
+
+	s.done(time.Now(), nil)
+	assert.True(t, s.status().Healthy)
+	assert.Equal(t, "", s.status().LastError)
+}
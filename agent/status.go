@@ -0,0 +1,135 @@
+package agent
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// PluginStatus reports the health of a running plugin instance.
+type PluginStatus struct {
+	// ID identifies the instance, ie, "inputs.http#0"
+	ID     string `json:"id"`
+	Paused bool   `json:"paused,omitempty"`
+	// Healthy is false when the last gather or write returned an error,
+	// LastError is the error of that run
+	Healthy   bool      `json:"healthy"`
+	LastRun   time.Time `json:"last_run"`
+	LastError string    `json:"last_error,omitempty"`
+}
+
+// pluginStatus tracks the health of an input or output and lets the control
+// socket pause an input or trigger an immediate gather.
+type pluginStatus struct {
+	sync.Mutex
+	id        string
+	paused    bool
+	lastRun   time.Time
+	lastError string
+	// errors seen during the last and the current run
+	lastErrors int
+	errors     int
+
+	trigger chan struct{}
+}
+
+func newPluginStatus(id string) *pluginStatus {
+	return &pluginStatus{
+		id:      id,
+		trigger: make(chan struct{}, 1),
+	}
+}
+
+// pluginIDs returns an instance ID for each name, numbering the instances of
+// the same plugin in configuration order.
+func pluginIDs(names []string) []string {
+	count := make(map[string]int)
+	ids := make([]string, len(names))
+	for i, name := range names {
+		ids[i] = fmt.Sprintf("%s#%d", name, count[name])
+		count[name]++
+	}
+	return ids
+}
+
+// matches returns true if the plugin is selected by the given ID or by its
+// plugin name, ie, "inputs.http" selects all http inputs.
+func (s *pluginStatus) matches(plugin string) bool {
+	if plugin == "" || plugin == s.id {
+		return true
+	}
+	if i := len(plugin); i < len(s.id) && s.id[:i] == plugin && s.id[i] == '#' {
+		return true
+	}
+	return false
+}
+
+func (s *pluginStatus) addError(err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.errors++
+	s.lastError = err.Error()
+}
+
+func (s *pluginStatus) done(t time.Time, err error) {
+	if err != nil {
+		s.addError(err)
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.lastRun = t
+	s.lastErrors = s.errors
+	s.errors = 0
+	// the error of a previous run is not reported along a successful one
+	if s.lastErrors == 0 {
+		s.lastError = ""
+	}
+}
+
+func (s *pluginStatus) isPaused() bool {
+	s.Lock()
+	defer s.Unlock()
+	return s.paused
+}
+
+func (s *pluginStatus) setPaused(paused bool) {
+	s.Lock()
+	defer s.Unlock()
+	s.paused = paused
+}
+
+// gatherNow requests an immediate gather, unless one is already pending.
+func (s *pluginStatus) gatherNow() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *pluginStatus) status() PluginStatus {
+	s.Lock()
+	defer s.Unlock()
+	return PluginStatus{
+		ID:        s.id,
+		Paused:    s.paused,
+		Healthy:   s.lastErrors == 0,
+		LastRun:   s.lastRun,
+		LastError: s.lastError,
+	}
+}
+
+// statusAccumulator records the errors of an input in its pluginStatus.
+type statusAccumulator struct {
+	telegraf.Accumulator
+	status *pluginStatus
+}
+
+func (a *statusAccumulator) AddError(err error) {
+	if err == nil {
+		return
+	}
+	a.status.addError(err)
+	a.Accumulator.AddError(err)
+}
@@ -9,8 +9,10 @@ import (
 	"os"
 	"os/signal"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/influxdata/telegraf/agent"
 	"github.com/influxdata/telegraf/internal/config"
@@ -21,6 +23,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/outputs"
 	_ "github.com/influxdata/telegraf/plugins/outputs/all"
 	_ "github.com/influxdata/telegraf/plugins/processors/all"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/all"
 	"github.com/kardianos/service"
 )
 
@@ -32,8 +35,10 @@ var fQuiet = flag.Bool("quiet", false,
 	"run in quiet mode")
 var fTest = flag.Bool("test", false, "gather metrics, print them out, and exit")
 var fConfig = flag.String("config", "", "configuration file to load")
+var fWatchConfig = flag.Bool("watch-config", false,
+	"reload the configuration when its files change")
 var fConfigDirectory = flag.String("config-directory", "",
-	"directory containing additional *.conf files")
+	"directory containing additional *.conf files, or glob pattern of the files")
 var fVersion = flag.Bool("version", false, "display the version")
 var fSampleConfig = flag.Bool("sample-config", false,
 	"print out full sample configuration")
@@ -82,11 +87,20 @@ Usage:
 The commands & flags are:
 
   config              print out full sample configuration to stdout
+  config diff --against-running
+                      show the plugin instances that would be added, removed
+                      or changed if the running agent loaded --config
+  control <command>   send a command to the running agent on its control
+                      socket: status, flush, gather [plugin], pause [plugin],
+                      resume [plugin], log-level <level>
   version             print the version to stdout
 
   --config <file>     configuration file to load
   --test              gather metrics once, print them to stdout, and exit
-  --config-directory  directory containing additional *.conf files
+  --config-directory  directory containing additional *.conf files, or glob
+                      pattern of the files, ie, "/etc/telegraf/telegraf.d/*.conf"
+  --watch-config      reload the configuration when its files change, as on
+                      SIGHUP
   --input-filter      filter the input plugins to enable, separator is :
   --output-filter     filter the output plugins to enable, separator is :
   --usage             print usage for a plugin, ie, 'telegraf --usage mysql'
@@ -113,6 +127,12 @@ Examples:
 
   # run telegraf with pprof
   telegraf --config telegraf.conf --pprof-addr localhost:6060
+
+  # compare a new config file with the agent running on its control_socket
+  telegraf --config new.conf config diff --against-running
+
+  # stop gathering from the second http input of the running agent
+  telegraf --config telegraf.conf control pause inputs.http#1
 `
 
 var stop chan struct{}
@@ -124,113 +144,323 @@ func reloadLoop(
 	aggregatorFilters []string,
 	processorFilters []string,
 ) {
-	reload := make(chan bool, 1)
-	reload <- true
-	for <-reload {
-		reload <- false
-
-		// If no other options are specified, load the config file and run.
-		c := config.NewConfig()
-		c.OutputFilters = outputFilters
-		c.InputFilters = inputFilters
-		err := c.LoadConfig(*fConfig)
+	// If no other options are specified, load the config file and run.
+	ag, err := newAgent(inputFilters, outputFilters)
+	if err != nil {
+		log.Fatal("E! " + err.Error())
+	}
+
+	if *fTest {
+		err = ag.Test()
 		if err != nil {
 			log.Fatal("E! " + err.Error())
 		}
+		os.Exit(0)
+	}
 
-		if *fConfigDirectory != "" {
-			err = c.LoadDirectory(*fConfigDirectory)
-			if err != nil {
-				log.Fatal("E! " + err.Error())
-			}
-		}
-		if !*fTest && len(c.Outputs) == 0 {
-			log.Fatalf("E! Error: no outputs found, did you provide a valid config file?")
-		}
-		if len(c.Inputs) == 0 {
-			log.Fatalf("E! Error: no inputs found, did you provide a valid config file?")
-		}
+	if *fPidfile != "" {
+		f, err := os.OpenFile(*fPidfile, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Printf("E! Unable to create pidfile: %s", err)
+		} else {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
 
-		if int64(c.Agent.Interval.Duration) <= 0 {
-			log.Fatalf("E! Agent interval must be positive, found %s",
-				c.Agent.Interval.Duration)
-		}
+			f.Close()
 
-		if int64(c.Agent.FlushInterval.Duration) <= 0 {
-			log.Fatalf("E! Agent flush_interval must be positive; found %s",
-				c.Agent.Interval.Duration)
+			defer func() {
+				err := os.Remove(*fPidfile)
+				if err != nil {
+					log.Printf("E! Unable to remove pidfile: %s", err)
+				}
+			}()
 		}
+	}
 
-		ag, err := agent.NewAgent(c)
+	for ag != nil {
+		err = ag.Connect()
 		if err != nil {
 			log.Fatal("E! " + err.Error())
 		}
+		ag = runAgent(ag, stop, inputFilters, outputFilters)
+	}
+}
 
-		// Setup logging
-		logger.SetupLogging(
-			ag.Config.Agent.Debug || *fDebug,
-			ag.Config.Agent.Quiet || *fQuiet,
-			ag.Config.Agent.Logfile,
-		)
+// newAgent loads the configuration and creates its agent, setting up the
+// logging of the agent.
+func newAgent(inputFilters, outputFilters []string) (*agent.Agent, error) {
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		return nil, err
+	}
 
-		if *fTest {
-			err = ag.Test()
-			if err != nil {
-				log.Fatal("E! " + err.Error())
-			}
-			os.Exit(0)
-		}
+	if !*fTest && len(c.Outputs) == 0 {
+		return nil, fmt.Errorf("Error: no outputs found, did you provide a valid config file?")
+	}
+	if len(c.Inputs) == 0 {
+		return nil, fmt.Errorf("Error: no inputs found, did you provide a valid config file?")
+	}
 
-		err = ag.Connect()
-		if err != nil {
-			log.Fatal("E! " + err.Error())
-		}
+	if int64(c.Agent.Interval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent interval must be positive, found %s",
+			c.Agent.Interval.Duration)
+	}
 
-		shutdown := make(chan struct{})
-		signals := make(chan os.Signal)
-		signal.Notify(signals, os.Interrupt, syscall.SIGHUP)
-		go func() {
+	if int64(c.Agent.FlushInterval.Duration) <= 0 {
+		return nil, fmt.Errorf("Agent flush_interval must be positive; found %s",
+			c.Agent.Interval.Duration)
+	}
+
+	ag, err := agent.NewAgent(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Setup logging
+	logger.SetupLogging(
+		ag.Config.Agent.Debug || *fDebug,
+		ag.Config.Agent.Quiet || *fQuiet,
+		ag.Config.Agent.Logfile,
+	)
+	return ag, nil
+}
+
+// runAgent runs the agent until it is stopped, or until its configuration
+// is reloaded on SIGHUP or on a change of its files with --watch-config. It
+// returns the agent of the reloaded configuration, or nil when stopped.
+func runAgent(
+	ag *agent.Agent,
+	stop chan struct{},
+	inputFilters []string,
+	outputFilters []string,
+) *agent.Agent {
+	var next *agent.Agent
+	shutdown := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	var changed <-chan struct{}
+	if *fWatchConfig {
+		changed = watchConfig(ag.Config.Files, *fConfigDirectory, shutdown)
+	}
+
+	go func() {
+		for {
 			select {
 			case sig := <-signals:
 				if sig == os.Interrupt {
 					close(shutdown)
+					return
 				}
-				if sig == syscall.SIGHUP {
-					log.Printf("I! Reloading Telegraf config\n")
-					<-reload
-					reload <- true
-					close(shutdown)
-				}
+				log.Printf("I! Reloading Telegraf config\n")
+			case <-changed:
+				log.Printf("I! Config files changed, reloading Telegraf config\n")
 			case <-stop:
 				close(shutdown)
+				return
 			}
-		}()
-
-		log.Printf("I! Starting Telegraf %s\n", displayVersion())
-		log.Printf("I! Loaded outputs: %s", strings.Join(c.OutputNames(), " "))
-		log.Printf("I! Loaded inputs: %s", strings.Join(c.InputNames(), " "))
-		log.Printf("I! Tags enabled: %s", c.ListTags())
 
-		if *fPidfile != "" {
-			f, err := os.OpenFile(*fPidfile, os.O_CREATE|os.O_WRONLY, 0644)
+			// the running agent is kept when the new configuration is
+			// invalid
+			var err error
+			next, err = reloadAgent(ag, inputFilters, outputFilters)
 			if err != nil {
-				log.Printf("E! Unable to create pidfile: %s", err)
-			} else {
-				fmt.Fprintf(f, "%d\n", os.Getpid())
-
-				f.Close()
-
-				defer func() {
-					err := os.Remove(*fPidfile)
-					if err != nil {
-						log.Printf("E! Unable to remove pidfile: %s", err)
-					}
-				}()
+				log.Printf("E! Unable to reload the config, keeping the running config: %s", err)
+				continue
+			}
+			close(shutdown)
+			return
+		}
+	}()
+
+	log.Printf("I! Starting Telegraf %s\n", displayVersion())
+	log.Printf("I! Loaded outputs: %s", strings.Join(ag.Config.OutputNames(), " "))
+	log.Printf("I! Loaded inputs: %s", strings.Join(ag.Config.InputNames(), " "))
+	log.Printf("I! Tags enabled: %s", ag.Config.ListTags())
+
+	ag.Run(shutdown)
+	select {
+	case <-shutdown:
+		return next
+	default:
+		// the agent failed to start
+		return nil
+	}
+}
+
+// reloadAgent creates the agent of the reloaded configuration, taking over
+// the unchanged plugins of the running agent, the outputs with their
+// buffered metrics. Only the changed plugins are restarted.
+func reloadAgent(ag *agent.Agent, inputFilters, outputFilters []string) (*agent.Agent, error) {
+	next, err := newAgent(inputFilters, outputFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range config.DiffInstances(ag.Config.Instances, next.Config.Instances) {
+		log.Printf("I! Config change: %s", change)
+	}
+	for _, name := range next.ReusePlugins(ag) {
+		log.Printf("D! Keeping %s running", name)
+	}
+	return next, nil
+}
+
+// watchConfig polls the configuration files and directory, and signals on
+// the returned channel when a file is modified, added or removed.
+func watchConfig(files []string, directory string, shutdown chan struct{}) <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+		last := configFilesState(files, directory)
+		for {
+			select {
+			case <-shutdown:
+				return
+			case <-ticker.C:
 			}
+			state := configFilesState(files, directory)
+			if state == last {
+				continue
+			}
+			last = state
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return changed
+}
+
+// configFilesState returns the sizes and modification times of the
+// configuration files, and of the files of the directory.
+func configFilesState(files []string, directory string) string {
+	paths := append([]string(nil), files...)
+	if directory != "" {
+		// unreadable files are compared as missing
+		directoryFiles, _ := config.DirectoryFiles(directory)
+		paths = append(paths, directoryFiles...)
+	}
+
+	var state []string
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			state = append(state, fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano()))
+		}
+	}
+	sort.Strings(state)
+	return strings.Join(state, "\n")
+}
+
+// configDiff prints the plugin instances that the running agent would add,
+// remove or change when loading the configuration given with --config.
+func configDiff(args []string, inputFilters, outputFilters []string) error {
+	fs := flag.NewFlagSet("config diff", flag.ExitOnError)
+	againstRunning := fs.Bool("against-running", false,
+		"compare with the agent listening on the configured control_socket")
+	fs.Parse(args)
+	if !*againstRunning {
+		return fmt.Errorf("config diff requires --against-running")
+	}
+
+	c, err := loadConfig(inputFilters, outputFilters)
+	if err != nil {
+		return err
+	}
+	if c.Agent.ControlSocket == "" {
+		return fmt.Errorf("no control_socket set in the [agent] configuration")
+	}
+
+	resp, err := agent.SendControlRequest(c.Agent.ControlSocket,
+		agent.ControlRequest{Command: "instances"})
+	if err != nil {
+		return fmt.Errorf("unable to query running agent on %s: %s",
+			c.Agent.ControlSocket, err)
+	}
+
+	changes := config.DiffInstances(resp.Instances, c.Instances)
+	if len(changes) == 0 {
+		fmt.Println("No plugin instances would change")
+		return nil
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	return nil
+}
+
+// control sends a command to the running agent on its control socket.
+func control(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("control requires a command")
+	}
+	req := agent.ControlRequest{Command: args[0]}
+	switch args[0] {
+	case "status", "flush":
+	case "gather", "pause", "resume":
+		if len(args) > 1 {
+			req.Plugin = args[1]
 		}
+	case "log-level":
+		if len(args) < 2 {
+			return fmt.Errorf("log-level requires a level")
+		}
+		req.Command = "log_level"
+		req.Level = args[1]
+	default:
+		return fmt.Errorf("unknown control command %q", args[0])
+	}
 
-		ag.Run(shutdown)
+	c, err := loadConfig(nil, nil)
+	if err != nil {
+		return err
 	}
+	if c.Agent.ControlSocket == "" {
+		return fmt.Errorf("no control_socket set in the [agent] configuration")
+	}
+
+	resp, err := agent.SendControlRequest(c.Agent.ControlSocket, req)
+	if err != nil {
+		return err
+	}
+	for _, p := range resp.Plugins {
+		state := "healthy"
+		if !p.Healthy {
+			state = "unhealthy"
+		}
+		if p.Paused {
+			state += ", paused"
+		}
+		lastRun := "never"
+		if !p.LastRun.IsZero() {
+			lastRun = p.LastRun.Format(time.RFC3339)
+		}
+		fmt.Printf("%s: %s, last run %s", p.ID, state, lastRun)
+		if p.LastError != "" {
+			fmt.Printf(", last error: %s", p.LastError)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// loadConfig loads the files given with --config and --config-directory.
+func loadConfig(inputFilters, outputFilters []string) (*config.Config, error) {
+	c := config.NewConfig()
+	c.OutputFilters = outputFilters
+	c.InputFilters = inputFilters
+	if err := c.LoadConfig(*fConfig); err != nil {
+		return nil, err
+	}
+	if *fConfigDirectory != "" {
+		if err := c.LoadDirectory(*fConfigDirectory); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 func usageExit(rc int) {
@@ -314,13 +544,27 @@ func main() {
 		case "version":
 			fmt.Printf("Telegraf %s (git: %s %s)\n", displayVersion(), branch, commit)
 			return
+		case "control":
+			if err := control(args[1:]); err != nil {
+				log.Fatal("E! " + err.Error())
+			}
+			return
 		case "config":
-			config.PrintSampleConfig(
+			if len(args) > 1 && args[1] == "diff" {
+				if err := configDiff(args[2:], inputFilters, outputFilters); err != nil {
+					log.Fatal("E! " + err.Error())
+				}
+				return
+			}
+			err := config.PrintSampleConfig(
 				inputFilters,
 				outputFilters,
 				aggregatorFilters,
 				processorFilters,
 			)
+			if err != nil {
+				log.Fatal("E! " + err.Error())
+			}
 			return
 		}
 	}
@@ -343,12 +587,15 @@ func main() {
 		fmt.Printf("Telegraf %s (git: %s %s)\n", displayVersion(), branch, commit)
 		return
 	case *fSampleConfig:
-		config.PrintSampleConfig(
+		err := config.PrintSampleConfig(
 			inputFilters,
 			outputFilters,
 			aggregatorFilters,
 			processorFilters,
 		)
+		if err != nil {
+			log.Fatal("E! " + err.Error())
+		}
 		return
 	case *fUsage != "":
 		err := config.PrintInputConfig(*fUsage)
@@ -38,8 +38,10 @@ func (b *Buffer) Len() int {
 	return len(b.buf)
 }
 
-// Add adds metrics to the buffer.
-func (b *Buffer) Add(metrics ...telegraf.Metric) {
+// Add adds metrics to the buffer, and returns the number of metrics dropped
+// to make room for them.
+func (b *Buffer) Add(metrics ...telegraf.Metric) int {
+	dropped := 0
 	for i, _ := range metrics {
 		MetricsWritten.Incr(1)
 		select {
@@ -47,11 +49,13 @@ func (b *Buffer) Add(metrics ...telegraf.Metric) {
 		default:
 			b.mu.Lock()
 			MetricsDropped.Incr(1)
+			dropped++
 			<-b.buf
 			b.buf <- metrics[i]
 			b.mu.Unlock()
 		}
 	}
+	return dropped
 }
 
 // Batch returns a batch of metrics of size batchSize.
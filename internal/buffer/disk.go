@@ -0,0 +1,224 @@
+package buffer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+const batchExt = ".batch"
+
+// DiskQueue is a queue of batches of metrics persisted in a directory, one
+// file per batch, so that they survive a restart of telegraf.
+type DiskQueue struct {
+	dir   string
+	limit int
+
+	// batches holds the batch files, oldest first.
+	batches []diskBatch
+	seq     uint64
+	n       int
+
+	mu sync.Mutex
+}
+
+type diskBatch struct {
+	name  string
+	count int
+}
+
+// OpenDiskQueue returns a DiskQueue persisting its batches in dir, creating
+// the directory if needed. The batches left in the directory by a previous
+// run are queued back in order.
+//   limit is the maximum number of metrics in the queue, 0 for no limit. If
+//   Push is called when the queue is full, then the oldest batch(es) will be
+//   dropped.
+func OpenDiskQueue(dir string, limit int) (*DiskQueue, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &DiskQueue{dir: dir, limit: limit}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || !strings.HasSuffix(name, batchExt) {
+			continue
+		}
+		seq, count, ok := parseBatchName(name)
+		if !ok {
+			continue
+		}
+		q.batches = append(q.batches, diskBatch{name: name, count: count})
+		q.n += count
+		if seq >= q.seq {
+			q.seq = seq + 1
+		}
+	}
+	sort.Slice(q.batches, func(i, j int) bool {
+		return q.batches[i].name < q.batches[j].name
+	})
+	return q, nil
+}
+
+// Len returns the number of metrics in the queue.
+func (q *DiskQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.n
+}
+
+// Push writes a batch to the end of the queue, and returns the number of
+// metrics dropped to make room for it.
+func (q *DiskQueue) Push(batch []telegraf.Metric) (int, error) {
+	if len(batch) == 0 {
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	for _, m := range batch {
+		buf.WriteString(strconv.Itoa(int(m.Type())))
+		buf.WriteByte(' ')
+		buf.Write(m.Serialize())
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := diskBatch{
+		name:  fmt.Sprintf("%020d-%d%s", q.seq, len(batch), batchExt),
+		count: len(batch),
+	}
+	// write to a temporary file first so that a crash never leaves a
+	// partial batch behind, it is synced before being renamed as the rename
+	// may otherwise reach the disk before its content.
+	tmp := filepath.Join(q.dir, b.name+".tmp")
+	if err := writeSynced(tmp, buf.Bytes()); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	if err := os.Rename(tmp, filepath.Join(q.dir, b.name)); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	q.seq++
+	q.batches = append(q.batches, b)
+	q.n += b.count
+
+	dropped := 0
+	for q.limit > 0 && q.n > q.limit && len(q.batches) > 1 {
+		dropped += q.batches[0].count
+		q.remove()
+	}
+	return dropped, nil
+}
+
+// Peek returns the oldest batch of the queue without removing it.
+func (q *DiskQueue) Peek() ([]telegraf.Metric, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.batches) == 0 {
+		return nil, nil
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(q.dir, q.batches[0].name))
+	if err != nil {
+		return nil, err
+	}
+	var batch []telegraf.Metric
+	for _, line := range bytes.Split(contents, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		m, err := parseBatchLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", q.batches[0].name, err)
+		}
+		batch = append(batch, m)
+	}
+	return batch, nil
+}
+
+// Pop removes the oldest batch of the queue.
+func (q *DiskQueue) Pop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.batches) > 0 {
+		q.remove()
+	}
+}
+
+func (q *DiskQueue) remove() {
+	os.Remove(filepath.Join(q.dir, q.batches[0].name))
+	q.n -= q.batches[0].count
+	q.batches = q.batches[1:]
+}
+
+// parseBatchName parses the sequence number and the number of metrics of a
+// batch file name.
+func parseBatchName(name string) (uint64, int, bool) {
+	parts := strings.SplitN(strings.TrimSuffix(name, batchExt), "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	seq, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, false
+	}
+	return seq, count, true
+}
+
+// parseBatchLine parses a line of a batch file, made of the value type of
+// the metric followed by the metric in line protocol.
+func parseBatchLine(line []byte) (telegraf.Metric, error) {
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid line %q", line)
+	}
+	tp, err := strconv.Atoi(string(line[:i]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid value type %q", line[:i])
+	}
+	metrics, err := metric.Parse(append(line[i+1:len(line):len(line)], '\n'))
+	if err != nil {
+		return nil, err
+	}
+	if len(metrics) != 1 {
+		return nil, fmt.Errorf("invalid line %q", line)
+	}
+	m := metrics[0]
+	return metric.New(m.Name(), m.Tags(), m.Fields(), m.Time(),
+		telegraf.ValueType(tp))
+}
+
+// writeSynced writes the data to the file, and syncs it to the disk.
+func writeSynced(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0640)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
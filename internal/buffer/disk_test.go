@@ -0,0 +1,138 @@
+package buffer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func metricStrings(metrics []telegraf.Metric) []string {
+	var out []string
+	for _, m := range metrics {
+		out = append(out, m.String())
+	}
+	return out
+}
+
+func TestDiskQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := OpenDiskQueue(dir, 0)
+	require.NoError(t, err)
+	assert.Zero(t, q.Len())
+	batch, err := q.Peek()
+	require.NoError(t, err)
+	assert.Nil(t, batch)
+
+	dropped, err := q.Push(metricList[:2])
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	dropped, err = q.Push(metricList[2:])
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	assert.Equal(t, 5, q.Len())
+
+	batch, err = q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[:2]), metricStrings(batch))
+	q.Pop()
+	assert.Equal(t, 3, q.Len())
+
+	batch, err = q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[2:]), metricStrings(batch))
+	q.Pop()
+	assert.Zero(t, q.Len())
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestDiskQueueReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := OpenDiskQueue(filepath.Join(dir, "output"), 0)
+	require.NoError(t, err)
+	for i := range metricList {
+		_, err := q.Push(metricList[i : i+1])
+		require.NoError(t, err)
+	}
+	q.Pop()
+
+	q, err = OpenDiskQueue(filepath.Join(dir, "output"), 0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, q.Len())
+	_, err = q.Push(metricList[:1])
+	require.NoError(t, err)
+
+	var got []telegraf.Metric
+	for q.Len() > 0 {
+		batch, err := q.Peek()
+		require.NoError(t, err)
+		got = append(got, batch...)
+		q.Pop()
+	}
+	expected := append([]telegraf.Metric{}, metricList[1:]...)
+	expected = append(expected, metricList[0])
+	assert.Equal(t, metricStrings(expected), metricStrings(got))
+}
+
+func TestDiskQueueLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := OpenDiskQueue(dir, 3)
+	require.NoError(t, err)
+	dropped, err := q.Push(metricList[:2])
+	require.NoError(t, err)
+	assert.Zero(t, dropped)
+	dropped, err = q.Push(metricList[2:4])
+	require.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+	assert.Equal(t, 2, q.Len())
+
+	batch, err := q.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, metricStrings(metricList[2:4]), metricStrings(batch))
+}
+
+func TestDiskQueueValueType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	m, err := metric.New("requests",
+		map[string]string{"host": "a"},
+		map[string]interface{}{"count": int64(42), "rate": 1.5},
+		time.Unix(0, 0),
+		telegraf.Counter,
+	)
+	require.NoError(t, err)
+
+	q, err := OpenDiskQueue(dir, 0)
+	require.NoError(t, err)
+	_, err = q.Push([]telegraf.Metric{m})
+	require.NoError(t, err)
+
+	batch, err := q.Peek()
+	require.NoError(t, err)
+	require.Len(t, batch, 1)
+	assert.Equal(t, telegraf.Counter, batch[0].Type())
+	assert.Equal(t, m.Fields(), batch[0].Fields())
+	assert.Equal(t, m.Tags(), batch[0].Tags())
+	assert.Equal(t, m.Time(), batch[0].Time())
+}
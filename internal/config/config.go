@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"sort"
@@ -19,10 +20,12 @@ import (
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/internal/models"
+	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/plugins/aggregators"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/outputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/parsers/dropwizard"
 	"github.com/influxdata/telegraf/plugins/processors"
 	"github.com/influxdata/telegraf/plugins/serializers"
 
@@ -38,8 +41,11 @@ var (
 	// Default output plugins
 	outputDefaults = []string{"influxdb"}
 
-	// envVarRe is a regex to find environment variables in the config file
-	envVarRe = regexp.MustCompile(`\$\w+`)
+	// envVarRe is a regex to find environment variables in the config file,
+	// either $VAR or ${VAR}, with an optional default as ${VAR:-default}
+	// used when VAR is unset or empty, or ${VAR-default} used when VAR is
+	// unset
+	envVarRe = regexp.MustCompile(`\$(?:(\w+)|\{(\w+)(?:(:?-)([^}]*))?\})`)
 
 	envVarEscaper = strings.NewReplacer(
 		`"`, `\"`,
@@ -61,6 +67,16 @@ type Config struct {
 	Aggregators []*models.RunningAggregator
 	// Processors have a slice wrapper type because they need to be sorted
 	Processors models.RunningProcessors
+
+	// Instances identifies every loaded plugin instance, used to compare
+	// configurations.
+	Instances []PluginInstance
+
+	// Files are the configuration files loaded, in order.
+	Files []string
+
+	// SecretStores resolve the @{id:key} references of the options, by id.
+	SecretStores map[string]telegraf.SecretStore
 }
 
 func NewConfig() *Config {
@@ -78,6 +94,7 @@ func NewConfig() *Config {
 		Processors:    make([]*models.RunningProcessor, 0),
 		InputFilters:  make([]string, 0),
 		OutputFilters: make([]string, 0),
+		SecretStores:  make(map[string]telegraf.SecretStore),
 	}
 	return c
 }
@@ -104,6 +121,11 @@ type AgentConfig struct {
 	// same time, which can have a measurable effect on the system.
 	CollectionJitter internal.Duration
 
+	// CollectionOffset shifts the collection by a fixed amount. Each plugin
+	// gathers this long after the rounded interval, ie, with an interval of
+	// 10s and an offset of 2s the plugins collect on :02, :12, :22, etc.
+	CollectionOffset internal.Duration
+
 	// FlushInterval is the Interval at which to flush data
 	FlushInterval internal.Duration
 
@@ -124,6 +146,16 @@ type AgentConfig struct {
 	// not be less than 2 times MetricBatchSize.
 	MetricBufferLimit int
 
+	// BufferDirectory is the directory where the outputs spill the failed
+	// writes which do not fit in their buffer, to write them once the output
+	// recovers, including after a restart. Empty disables the disk buffer.
+	BufferDirectory string
+
+	// MetricDiskBufferLimit is the max number of metrics that each output
+	// plugin keeps in BufferDirectory, 0 for no limit. When full, the oldest
+	// metrics are dropped.
+	MetricDiskBufferLimit int
+
 	// FlushBufferWhenFull tells Telegraf to flush the metric buffer whenever
 	// it fills up, regardless of FlushInterval. Setting this option to true
 	// does _not_ deactivate FlushInterval.
@@ -144,6 +176,10 @@ type AgentConfig struct {
 	Quiet        bool
 	Hostname     string
 	OmitHostname bool
+
+	// ControlSocket is the path of the unix socket used to inspect and
+	// control the running agent. Empty disables the control socket.
+	ControlSocket string
 }
 
 // Inputs returns a list of strings of the configured inputs.
@@ -192,6 +228,7 @@ var header = `# Telegraf Configuration
 # Environment variables can be used anywhere in this config file, simply prepend
 # them with $. For strings the variable must be within quotes (ie, "$STR_VAR"),
 # for numbers and booleans they should be plain (ie, $INT_VAR, $BOOL_VAR)
+# The ${VAR:-default} form falls back to the default when VAR is unset or empty.
 
 
 # Global tags can be specified here in key="value" format.
@@ -220,15 +257,30 @@ var header = `# Telegraf Configuration
   ## are dropped first when this buffer fills.
   ## This buffer only fills when writes fail to output plugin(s).
   metric_buffer_limit = 10000
+  ## Both metric_batch_size and metric_buffer_limit can be set per output.
+
+  ## When set, the failed writes which do not fit in metric_buffer_limit are
+  ## spilled to a subdirectory of buffer_directory for each output instead of
+  ## being dropped, and written once the output recovers, even after a
+  ## restart. At most metric_disk_buffer_limit metrics are kept for each
+  ## output, 0 for no limit, the oldest being dropped first.
+  # buffer_directory = "/var/lib/telegraf/buffer"
+  # metric_disk_buffer_limit = 1000000
 
   ## Collection jitter is used to jitter the collection by a random amount.
   ## Each plugin will sleep for a random time within jitter before collecting.
   ## This can be used to avoid many plugins querying things like sysfs at the
   ## same time, which can have a measurable effect on the system.
   collection_jitter = "0s"
+  ## Collection offset is used to shift the collection by a fixed amount.
+  ## ie, an offset of 2s and interval 10s means the plugins collect on :02,
+  ## :12, :22, etc. Like the jitter, it can be set per input.
+  collection_offset = "0s"
 
   ## Default flushing interval for all outputs. You shouldn't set this below
   ## interval. Maximum flush_interval will be flush_interval + flush_jitter
+  ## Each output is flushed on its own, so a slow output doesn't delay the
+  ## others, and can set its own flush_interval and flush_jitter.
   flush_interval = "10s"
   ## Jitter the flush interval by a random amount. This is primarily to avoid
   ## large write spikes for users running a large number of telegraf instances.
@@ -257,6 +309,11 @@ var header = `# Telegraf Configuration
   ## If set to true, do no set the "host" tag in the telegraf agent.
   omit_hostname = false
 
+  ## Path of a unix socket used to inspect the running agent, ie, with
+  ## 'telegraf --config telegraf.conf config diff --against-running'.
+  ## The control socket is disabled when empty.
+  # control_socket = "/var/run/telegraf/telegraf.sock"
+
 
 ###############################################################################
 #                            OUTPUT PLUGINS                                   #
@@ -291,13 +348,23 @@ var serviceInputHeader = `
 ###############################################################################
 `
 
-// PrintSampleConfig prints the sample config
+// PrintSampleConfig prints the sample config. When any filter is given only
+// the selected plugins are printed, producing a minimal config: sections
+// without a filter are left out, except for the default inputs and outputs
+// so that the config remains runnable.
 func PrintSampleConfig(
 	inputFilters []string,
 	outputFilters []string,
 	aggregatorFilters []string,
 	processorFilters []string,
-) {
+) error {
+	if err := checkFilters(inputFilters, outputFilters,
+		aggregatorFilters, processorFilters); err != nil {
+		return err
+	}
+	minimal := len(inputFilters) != 0 || len(outputFilters) != 0 ||
+		len(aggregatorFilters) != 0 || len(processorFilters) != 0
+
 	fmt.Printf(header)
 
 	// print output plugins
@@ -308,7 +375,7 @@ func PrintSampleConfig(
 		// Print non-default outputs, commented
 		var pnames []string
 		for pname := range outputs.Outputs {
-			if !sliceContains(pname, outputDefaults) {
+			if !minimal && !sliceContains(pname, outputDefaults) {
 				pnames = append(pnames, pname)
 			}
 		}
@@ -317,10 +384,11 @@ func PrintSampleConfig(
 	}
 
 	// print processor plugins
-	fmt.Printf(processorHeader)
 	if len(processorFilters) != 0 {
+		fmt.Print(processorHeader)
 		printFilteredProcessors(processorFilters, false)
-	} else {
+	} else if !minimal {
+		fmt.Print(processorHeader)
 		pnames := []string{}
 		for pname := range processors.Processors {
 			pnames = append(pnames, pname)
@@ -330,10 +398,11 @@ func PrintSampleConfig(
 	}
 
 	// pring aggregator plugins
-	fmt.Printf(aggregatorHeader)
 	if len(aggregatorFilters) != 0 {
+		fmt.Print(aggregatorHeader)
 		printFilteredAggregators(aggregatorFilters, false)
-	} else {
+	} else if !minimal {
+		fmt.Print(aggregatorHeader)
 		pnames := []string{}
 		for pname := range aggregators.Aggregators {
 			pnames = append(pnames, pname)
@@ -343,7 +412,7 @@ func PrintSampleConfig(
 	}
 
 	// print input plugins
-	fmt.Printf(inputHeader)
+	fmt.Print(inputHeader)
 	if len(inputFilters) != 0 {
 		printFilteredInputs(inputFilters, false)
 	} else {
@@ -351,13 +420,54 @@ func PrintSampleConfig(
 		// Print non-default inputs, commented
 		var pnames []string
 		for pname := range inputs.Inputs {
-			if !sliceContains(pname, inputDefaults) {
+			if !minimal && !sliceContains(pname, inputDefaults) {
 				pnames = append(pnames, pname)
 			}
 		}
 		sort.Strings(pnames)
 		printFilteredInputs(pnames, true)
 	}
+	return nil
+}
+
+// checkFilters returns an error naming the filtered plugins that do not
+// exist, so that a typo does not silently produce an incomplete config.
+func checkFilters(
+	inputFilters []string,
+	outputFilters []string,
+	aggregatorFilters []string,
+	processorFilters []string,
+) error {
+	// the filters given on the command line start and end with an empty
+	// name
+	var unknown []string
+	for _, name := range inputFilters {
+		if _, ok := inputs.Inputs[name]; ok || name == "" {
+			continue
+		}
+		if _, ok := inputFormats[name]; !ok {
+			unknown = append(unknown, "inputs."+name)
+		}
+	}
+	for _, name := range outputFilters {
+		if _, ok := outputs.Outputs[name]; !ok && name != "" {
+			unknown = append(unknown, "outputs."+name)
+		}
+	}
+	for _, name := range aggregatorFilters {
+		if _, ok := aggregators.Aggregators[name]; !ok && name != "" {
+			unknown = append(unknown, "aggregators."+name)
+		}
+	}
+	for _, name := range processorFilters {
+		if _, ok := processors.Processors[name]; !ok && name != "" {
+			unknown = append(unknown, "processors."+name)
+		}
+	}
+	if len(unknown) != 0 {
+		return fmt.Errorf("unknown plugins: %s", strings.Join(unknown, ", "))
+	}
+	return nil
 }
 
 func printFilteredProcessors(processorFilters []string, commented bool) {
@@ -396,6 +506,42 @@ func printFilteredAggregators(aggregatorFilters []string, commented bool) {
 	}
 }
 
+// inputFormats maps the data formats accepted by the input filters of the
+// sample config, as if they were inputs, to the input reading them. The
+// input is printed with its data_format set to the format.
+var inputFormats = map[string]string{
+	"dropwizard": "http",
+}
+
+// dataFormatInput prints the sample config of an input with its data_format
+// set.
+type dataFormatInput struct {
+	printer
+	dataFormat string
+}
+
+var dataFormatLine = regexp.MustCompile(`(?m)^(\s*)#?\s*data_format = ".*"$`)
+
+func (d dataFormatInput) SampleConfig() string {
+	return dataFormatLine.ReplaceAllString(d.printer.SampleConfig(),
+		`${1}data_format = "`+d.dataFormat+`"`)
+}
+
+// sampleInput returns the name and the sample config of the input selected
+// by the given input filter, and whether it is a service input.
+func sampleInput(filter string) (string, printer, bool) {
+	name := filter
+	if input, ok := inputFormats[filter]; ok {
+		name = input
+	}
+	input := inputs.Inputs[name]()
+	_, service := input.(telegraf.ServiceInput)
+	if name != filter {
+		return name, dataFormatInput{input, filter}, service
+	}
+	return name, input, service
+}
+
 func printFilteredInputs(inputFilters []string, commented bool) {
 	// Filter inputs
 	var pnames []string
@@ -404,36 +550,34 @@ func printFilteredInputs(inputFilters []string, commented bool) {
 			pnames = append(pnames, pname)
 		}
 	}
+	for format := range inputFormats {
+		if sliceContains(format, inputFilters) {
+			pnames = append(pnames, format)
+		}
+	}
 	sort.Strings(pnames)
 
-	// cache service inputs to print them at the end
-	servInputs := make(map[string]telegraf.ServiceInput)
-	// for alphabetical looping:
-	servInputNames := []string{}
+	// service inputs are printed at the end
+	var servInputNames []string
 
 	// Print Inputs
 	for _, pname := range pnames {
-		creator := inputs.Inputs[pname]
-		input := creator()
-
-		switch p := input.(type) {
-		case telegraf.ServiceInput:
-			servInputs[pname] = p
+		name, input, service := sampleInput(pname)
+		if service {
 			servInputNames = append(servInputNames, pname)
 			continue
 		}
-
-		printConfig(pname, input, "inputs", commented)
+		printConfig(name, input, "inputs", commented)
 	}
 
 	// Print Service Inputs
-	if len(servInputs) == 0 {
+	if len(servInputNames) == 0 {
 		return
 	}
-	sort.Strings(servInputNames)
-	fmt.Printf(serviceInputHeader)
-	for _, name := range servInputNames {
-		printConfig(name, servInputs[name], "inputs", commented)
+	fmt.Print(serviceInputHeader)
+	for _, pname := range servInputNames {
+		name, input, _ := sampleInput(pname)
+		printConfig(name, input, "inputs", commented)
 	}
 }
 
@@ -460,6 +604,59 @@ type printer interface {
 	SampleConfig() string
 }
 
+// commonOptions are the options available for all the plugins of each
+// kind, printed commented before the sample config of each plugin unless
+// already part of it, as the sample config may end with subtables.
+var commonOptions = map[string][]string{
+	"inputs": {
+		`alias = ""`,
+		`log_level = "info"`,
+		`interval = "10s"`,
+		`collection_jitter = "0s"`,
+		`collection_offset = "0s"`,
+	},
+	"outputs": {
+		`alias = ""`,
+		`log_level = "info"`,
+		`flush_interval = "10s"`,
+		`flush_jitter = "0s"`,
+		`metric_batch_size = 1000`,
+		`metric_buffer_limit = 10000`,
+		`metric_rate_limit = 0`,
+		`byte_rate_limit = 0`,
+	},
+	"processors": {
+		`alias = ""`,
+		`log_level = "info"`,
+		`order = 1`,
+	},
+	"aggregators": {
+		`alias = ""`,
+		`log_level = "info"`,
+		`period = "30s"`,
+		`delay = "100ms"`,
+		`drop_original = false`,
+	},
+}
+
+// commonOptionLines returns the commented lines of the common options of
+// the plugin kind missing from the sample config.
+func commonOptionLines(op string, config string) []string {
+	var lines []string
+	for _, option := range commonOptions[op] {
+		key := option[:strings.Index(option, " ")]
+		set := regexp.MustCompile(`(?m)^\s*#?\s*` + key + `\s*=`)
+		if !set.MatchString(config) {
+			lines = append(lines, "  # "+option)
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return append([]string{"  ## Options common to all " + op + ", see docs/CONFIGURATION.md"},
+		lines...)
+}
+
 func printConfig(name string, p printer, op string, commented bool) {
 	comment := ""
 	if commented {
@@ -469,18 +666,25 @@ func printConfig(name string, p printer, op string, commented bool) {
 		op, name)
 
 	config := p.SampleConfig()
-	if config == "" {
+	common := commonOptionLines(op, config)
+	if config == "" && len(common) == 0 {
 		fmt.Printf("\n%s  # no configuration\n\n", comment)
-	} else {
-		lines := strings.Split(config, "\n")
-		for i, line := range lines {
-			if i == 0 || i == len(lines)-1 {
-				fmt.Print("\n")
-				continue
-			}
-			fmt.Print(strings.TrimRight(comment+line, " ") + "\n")
+		return
+	}
+
+	lines := common
+	if config != "" {
+		sample := strings.Split(strings.TrimRight(config, " \n"), "\n")[1:]
+		if len(common) > 0 && len(sample) > 0 {
+			lines = append(lines, "")
 		}
+		lines = append(lines, sample...)
 	}
+	fmt.Print("\n")
+	for _, line := range lines {
+		fmt.Print(strings.TrimRight(comment+line, " ") + "\n")
+	}
+	fmt.Print("\n")
 }
 
 func sliceContains(name string, list []string) bool {
@@ -512,26 +716,86 @@ func PrintOutputConfig(name string) error {
 	return nil
 }
 
+// LoadDirectory loads the configuration files of the directory, or matched
+// by the glob pattern, in the order of DirectoryFiles. Each file is loaded as
+// by LoadConfig, on top of the files loaded before it.
 func (c *Config) LoadDirectory(path string) error {
-	walkfn := func(thispath string, info os.FileInfo, _ error) error {
-		if info == nil {
-			log.Printf("W! Telegraf is not permitted to read %s", thispath)
-			return nil
+	files, err := DirectoryFiles(path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := c.LoadConfig(file); err != nil {
+			return err
 		}
-		if info.IsDir() {
-			return nil
+	}
+	return nil
+}
+
+// DirectoryFiles returns the configuration files of a --config-directory in
+// the order they are loaded. The path is either a directory, whose *.conf
+// files and those of its subdirectories are returned, or a glob pattern, ie,
+// "/etc/telegraf/telegraf.d/*.conf", whose matching files are returned and
+// matching directories are walked as above. The files are sorted by path,
+// the files of a directory being returned where the directory sorts, and the
+// hidden files and directories, ie, the "..data" directory of the kubernetes
+// config maps, are skipped.
+func DirectoryFiles(path string) ([]string, error) {
+	matches := []string{path}
+	if strings.ContainsAny(path, "*?[") {
+		var err error
+		if matches, err = filepath.Glob(path); err != nil {
+			return nil, fmt.Errorf("invalid config directory pattern %s: %s", path, err)
+		}
+	}
+
+	var files []string
+	for _, match := range matches {
+		if isHidden(match) {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			log.Printf("W! Telegraf is not permitted to read %s", match)
+			continue
+		}
+		if !info.IsDir() {
+			files = append(files, match)
+			continue
 		}
-		name := info.Name()
-		if len(name) < 6 || name[len(name)-5:] != ".conf" {
+
+		walkfn := func(thispath string, info os.FileInfo, _ error) error {
+			if info == nil {
+				log.Printf("W! Telegraf is not permitted to read %s", thispath)
+				return nil
+			}
+			if thispath != match && isHidden(thispath) {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if info.IsDir() {
+				return nil
+			}
+			name := info.Name()
+			if len(name) < 6 || name[len(name)-5:] != ".conf" {
+				return nil
+			}
+			files = append(files, thispath)
 			return nil
 		}
-		err := c.LoadConfig(thispath)
-		if err != nil {
-			return err
+		if err := filepath.Walk(match, walkfn); err != nil {
+			return nil, err
 		}
-		return nil
 	}
-	return filepath.Walk(path, walkfn)
+	return files, nil
+}
+
+// isHidden returns whether the base name of the path starts with a dot.
+func isHidden(path string) bool {
+	name := filepath.Base(path)
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
 }
 
 // Try to find a default config file at these locations (in order):
@@ -570,6 +834,7 @@ func (c *Config) LoadConfig(path string) error {
 	if err != nil {
 		return fmt.Errorf("Error parsing %s, %s", path, err)
 	}
+	c.Files = append(c.Files, path)
 
 	// Parse tags tables first:
 	for _, tableName := range []string{"tags", "global_tags"} {
@@ -597,6 +862,37 @@ func (c *Config) LoadConfig(path string) error {
 		}
 	}
 
+	// Parse secret stores table, before the plugins referencing them:
+	if val, ok := tbl.Fields["secretstores"]; ok {
+		subTable, ok := val.(*ast.Table)
+		if !ok {
+			return fmt.Errorf("%s: invalid configuration", path)
+		}
+		// stores are loaded in order, as a store can reference the secrets
+		// of the stores before it
+		names := make([]string, 0, len(subTable.Fields))
+		for storeName := range subTable.Fields {
+			names = append(names, storeName)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return tablePos(subTable.Fields[names[i]]) <
+				tablePos(subTable.Fields[names[j]])
+		})
+		for _, storeName := range names {
+			switch storeSubTable := subTable.Fields[storeName].(type) {
+			case []*ast.Table:
+				for _, t := range storeSubTable {
+					if err = c.addSecretStore(storeName, t); err != nil {
+						return fmt.Errorf("Error parsing %s, %s", path, err)
+					}
+				}
+			default:
+				return fmt.Errorf("Unsupported config format: %s, file %s",
+					storeName, path)
+			}
+		}
+	}
+
 	// Parse all the rest of the plugins:
 	for name, val := range tbl.Fields {
 		subTable, ok := val.(*ast.Table)
@@ -605,7 +901,7 @@ func (c *Config) LoadConfig(path string) error {
 		}
 
 		switch name {
-		case "agent", "global_tags", "tags":
+		case "agent", "global_tags", "tags", "secretstores":
 		case "outputs":
 			for pluginName, pluginVal := range subTable.Fields {
 				switch pluginSubTable := pluginVal.(type) {
@@ -682,11 +978,45 @@ func (c *Config) LoadConfig(path string) error {
 	}
 
 	if len(c.Processors) > 1 {
-		sort.Sort(c.Processors)
+		c.sortProcessors()
 	}
 	return nil
 }
 
+// sortProcessors sorts the processors by their order, along with their
+// instances so that both stay in the same order.
+func (c *Config) sortProcessors() {
+	var positions []int
+	for i, instance := range c.Instances {
+		if strings.HasPrefix(instance.Name, "processors.") {
+			positions = append(positions, i)
+		}
+	}
+	if len(positions) != len(c.Processors) {
+		sort.Stable(c.Processors)
+		return
+	}
+
+	order := make([]int, len(c.Processors))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return c.Processors[order[i]].Config.Order < c.Processors[order[j]].Config.Order
+	})
+
+	processors := make(models.RunningProcessors, len(order))
+	instances := make([]PluginInstance, len(order))
+	for i, j := range order {
+		processors[i] = c.Processors[j]
+		instances[i] = c.Instances[positions[j]]
+	}
+	c.Processors = processors
+	for i, pos := range positions {
+		c.Instances[pos] = instances[i]
+	}
+}
+
 // trimBOM trims the Byte-Order-Marks from the beginning of the file.
 // this is for Windows compatibility only.
 // see https://github.com/influxdata/telegraf/issues/1378
@@ -710,35 +1040,63 @@ func parseFile(fpath string) (*ast.Table, error) {
 	// ugh windows why
 	contents = trimBOM(contents)
 
-	env_vars := envVarRe.FindAll(contents, -1)
-	for _, env_var := range env_vars {
-		env_val, ok := os.LookupEnv(strings.TrimPrefix(string(env_var), "$"))
-		if ok {
-			env_val = escapeEnv(env_val)
-			contents = bytes.Replace(contents, env_var, []byte(env_val), 1)
-		}
-	}
+	contents = expandEnv(contents)
 
 	return toml.Parse(contents)
 }
 
+// expandEnv replaces the environment variables of the contents with their
+// values escaped for a TOML string. A variable which is unset and without a
+// default is left as is.
+func expandEnv(contents []byte) []byte {
+	return envVarRe.ReplaceAllFunc(contents, func(env_var []byte) []byte {
+		match := envVarRe.FindSubmatch(env_var)
+		name := string(match[1])
+		if name == "" {
+			name = string(match[2])
+		}
+
+		env_val, ok := os.LookupEnv(name)
+		switch {
+		case string(match[3]) == ":-" && env_val == "":
+			// the default is written in the file, it is already escaped
+			return match[4]
+		case string(match[3]) == "-" && !ok:
+			return match[4]
+		case !ok:
+			return env_var
+		}
+		return []byte(escapeEnv(env_val))
+	})
+}
+
 func (c *Config) addAggregator(name string, table *ast.Table) error {
 	creator, ok := aggregators.Aggregators[name]
 	if !ok {
 		return fmt.Errorf("Undefined but requested aggregator: %s", name)
 	}
 	aggregator := creator()
+	instance := newPluginInstance("aggregators."+name, table)
+	if err := c.resolveInstanceSecrets(&instance, table); err != nil {
+		return err
+	}
 
 	conf, err := buildAggregator(name, table)
 	if err != nil {
 		return err
 	}
+	logLevel := buildLogLevel(table)
 
 	if err := toml.UnmarshalTable(table, aggregator); err != nil {
 		return err
 	}
 
-	c.Aggregators = append(c.Aggregators, models.NewRunningAggregator(aggregator, conf))
+	ra := models.NewRunningAggregator(aggregator, conf)
+	if err := setLogger(aggregator, ra.LogName(), logLevel); err != nil {
+		return err
+	}
+	c.Aggregators = append(c.Aggregators, ra)
+	c.Instances = append(c.Instances, instance)
 	return nil
 }
 
@@ -748,11 +1106,27 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested processor: %s", name)
 	}
 	processor := creator()
+	instance := newPluginInstance("processors."+name, table)
+	if err := c.resolveInstanceSecrets(&instance, table); err != nil {
+		return err
+	}
+
+	// Processors with a SetParser function parse data of arbitrary types,
+	// as the inputs do.
+	switch t := processor.(type) {
+	case parsers.ParserInput:
+		parser, err := buildParser(name, table)
+		if err != nil {
+			return err
+		}
+		t.SetParser(parser)
+	}
 
 	processorConfig, err := buildProcessor(name, table)
 	if err != nil {
 		return err
 	}
+	logLevel := buildLogLevel(table)
 
 	if err := toml.UnmarshalTable(table, processor); err != nil {
 		return err
@@ -763,8 +1137,12 @@ func (c *Config) addProcessor(name string, table *ast.Table) error {
 		Processor: processor,
 		Config:    processorConfig,
 	}
+	if err := setLogger(processor, rf.LogName(), logLevel); err != nil {
+		return err
+	}
 
 	c.Processors = append(c.Processors, rf)
+	c.Instances = append(c.Instances, instance)
 	return nil
 }
 
@@ -777,6 +1155,10 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested output: %s", name)
 	}
 	output := creator()
+	instance := newPluginInstance("outputs."+name, table)
+	if err := c.resolveInstanceSecrets(&instance, table); err != nil {
+		return err
+	}
 
 	// If the output has a SetSerializer function, then this means it can write
 	// arbitrary types of output, so build the serializer and set it.
@@ -793,14 +1175,27 @@ func (c *Config) addOutput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	outputConfig.Checksum = instance.Checksum
+	logLevel := buildLogLevel(table)
 
 	if err := toml.UnmarshalTable(table, output); err != nil {
 		return err
 	}
 
+	batchSize, bufferLimit := c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit
+	if outputConfig.MetricBatchSize != 0 {
+		batchSize = outputConfig.MetricBatchSize
+	}
+	if outputConfig.MetricBufferLimit != 0 {
+		bufferLimit = outputConfig.MetricBufferLimit
+	}
 	ro := models.NewRunningOutput(name, output, outputConfig,
-		c.Agent.MetricBatchSize, c.Agent.MetricBufferLimit)
+		batchSize, bufferLimit)
+	if err := setLogger(output, ro.LogName(), logLevel); err != nil {
+		return err
+	}
 	c.Outputs = append(c.Outputs, ro)
+	c.Instances = append(c.Instances, instance)
 	return nil
 }
 
@@ -818,12 +1213,22 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 		return fmt.Errorf("Undefined but requested input: %s", name)
 	}
 	input := creator()
+	instance := newPluginInstance("inputs."+name, table)
+	if err := c.resolveInstanceSecrets(&instance, table); err != nil {
+		return err
+	}
 
 	// If the input has a SetParser function, then this means it can accept
 	// arbitrary types of input, so build the parser and set it.
+	var parserConfig *parsers.Config
 	switch t := input.(type) {
 	case parsers.ParserInput:
-		parser, err := buildParser(name, table)
+		var err error
+		parserConfig, err = buildParserConfig(name, table)
+		if err != nil {
+			return err
+		}
+		parser, err := parsers.NewParser(parserConfig)
 		if err != nil {
 			return err
 		}
@@ -834,13 +1239,28 @@ func (c *Config) addInput(name string, table *ast.Table) error {
 	if err != nil {
 		return err
 	}
+	logLevel := buildLogLevel(table)
 
 	if err := toml.UnmarshalTable(table, input); err != nil {
 		return err
 	}
 
+	// The namepass patterns match the measurement names once renamed, so
+	// they are only passed on to inputs whose parser and plugin keep the
+	// names unchanged.
+	if t, ok := input.(inputs.NamePassInput); ok && len(pluginConfig.Filter.NamePass) > 0 &&
+		parserConfig != nil && keepsMetricNames(parserConfig) &&
+		pluginConfig.NameOverride == "" && pluginConfig.MeasurementPrefix == "" &&
+		pluginConfig.MeasurementSuffix == "" {
+		t.SetNamePass(pluginConfig.Filter.NamePass)
+	}
+
 	rp := models.NewRunningInput(input, pluginConfig)
+	if err := setLogger(input, rp.LogName(), logLevel); err != nil {
+		return err
+	}
 	c.Inputs = append(c.Inputs, rp)
+	c.Instances = append(c.Instances, instance)
 	return nil
 }
 
@@ -858,6 +1278,7 @@ func buildAggregator(name string, tbl *ast.Table) (*models.AggregatorConfig, err
 
 	conf := &models.AggregatorConfig{
 		Name:   name,
+		Alias:  buildAlias(tbl),
 		Delay:  time.Millisecond * 100,
 		Period: time.Second * 30,
 	}
@@ -952,7 +1373,7 @@ func buildAggregator(name string, tbl *ast.Table) (*models.AggregatorConfig, err
 // builds the filter and returns a
 // models.ProcessorConfig to be inserted into models.RunningProcessor
 func buildProcessor(name string, tbl *ast.Table) (*models.ProcessorConfig, error) {
-	conf := &models.ProcessorConfig{Name: name}
+	conf := &models.ProcessorConfig{Name: name, Alias: buildAlias(tbl)}
 	unsupportedFields := []string{"tagexclude", "taginclude", "fielddrop", "fieldpass"}
 	for _, field := range unsupportedFields {
 		if _, ok := tbl.Fields[field]; ok {
@@ -982,6 +1403,58 @@ func buildProcessor(name string, tbl *ast.Table) (*models.ProcessorConfig, error
 	return conf, nil
 }
 
+// buildAlias returns the alias of a plugin instance, naming the instance in
+// the logs and self-metrics, and removes it from the table.
+func buildAlias(tbl *ast.Table) string {
+	var alias string
+	if node, ok := tbl.Fields["alias"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				alias = str.Value
+			}
+		}
+	}
+	delete(tbl.Fields, "alias")
+	return alias
+}
+
+// buildLogLevel returns the log level of a plugin instance, and removes it
+// from the table.
+func buildLogLevel(tbl *ast.Table) string {
+	var level string
+	if node, ok := tbl.Fields["log_level"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				level = str.Value
+			}
+		}
+	}
+	delete(tbl.Fields, "log_level")
+	return level
+}
+
+// loggerType is the type of the Log field of the plugins.
+var loggerType = reflect.TypeOf((*telegraf.Logger)(nil)).Elem()
+
+// setLogger sets the Log field of a plugin to the logger of the named
+// instance, if the plugin has an exported Log field of type telegraf.Logger.
+func setLogger(plugin interface{}, name, level string) error {
+	l, err := logger.NewPluginLogger(name, level)
+	if err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	v := reflect.ValueOf(plugin)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	field := v.Elem().FieldByName("Log")
+	if field.IsValid() && field.CanSet() && field.Type() == loggerType {
+		field.Set(reflect.ValueOf(l))
+	}
+	return nil
+}
+
 // buildFilter builds a Filter
 // (tagpass/tagdrop/namepass/namedrop/fieldpass/fielddrop) to
 // be inserted into the models.OutputConfig/models.InputConfig
@@ -1123,7 +1596,7 @@ func buildFilter(tbl *ast.Table) (models.Filter, error) {
 // builds the filter and returns a
 // models.InputConfig to be inserted into models.RunningInput
 func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
-	cp := &models.InputConfig{Name: name}
+	cp := &models.InputConfig{Name: name, Alias: buildAlias(tbl)}
 	if node, ok := tbl.Fields["interval"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1137,6 +1610,45 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["precision"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				cp.Precision = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["collection_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				cp.CollectionJitter = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["collection_offset"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				cp.CollectionOffset = dur
+			}
+		}
+	}
+
 	if node, ok := tbl.Fields["name_prefix"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1174,6 +1686,9 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 	delete(tbl.Fields, "name_suffix")
 	delete(tbl.Fields, "name_override")
 	delete(tbl.Fields, "interval")
+	delete(tbl.Fields, "precision")
+	delete(tbl.Fields, "collection_jitter")
+	delete(tbl.Fields, "collection_offset")
 	delete(tbl.Fields, "tags")
 	var err error
 	cp.Filter, err = buildFilter(tbl)
@@ -1187,6 +1702,16 @@ func buildInput(name string, tbl *ast.Table) (*models.InputConfig, error) {
 // a parsers.Parser object, and creates it, which can then be added onto
 // an Input object.
 func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
+	c, err := buildParserConfig(name, tbl)
+	if err != nil {
+		return nil, err
+	}
+	return parsers.NewParser(c)
+}
+
+// buildParserConfig grabs the necessary entries from the ast.Table for
+// creating a parsers.Parser object.
+func buildParserConfig(name string, tbl *ast.Table) (*parsers.Config, error) {
 	c := &parsers.Config{}
 
 	if node, ok := tbl.Fields["data_format"]; ok {
@@ -1279,6 +1804,20 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 			}
 		}
 	}
+	if node, ok := tbl.Fields["dropwizard_registries_path"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardRegistriesPath = str.Value
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_registry_tag"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardRegistryTag = str.Value
+			}
+		}
+	}
 	if node, ok := tbl.Fields["dropwizard_time_path"]; ok {
 		if kv, ok := node.(*ast.KeyValue); ok {
 			if str, ok := kv.Value.(*ast.String); ok {
@@ -1313,6 +1852,230 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 		}
 	}
 
+	if node, ok := tbl.Fields["dropwizard_exclude_jvm_metrics"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardExcludeJVMMetrics, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					log.Printf("Error parsing boolean value for %s: %s\n", name, err)
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["dropwizard_strip_prefixes"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.DropwizardStripPrefixes = append(c.DropwizardStripPrefixes, str.Value)
+					}
+				}
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["dropwizard_rename"]; ok {
+		if subtbls, ok := node.([]*ast.Table); ok {
+			for _, subtbl := range subtbls {
+				var rename dropwizard.Rename
+				if node, ok := subtbl.Fields["pattern"]; ok {
+					if kv, ok := node.(*ast.KeyValue); ok {
+						if str, ok := kv.Value.(*ast.String); ok {
+							rename.Pattern = str.Value
+						}
+					}
+				}
+				if node, ok := subtbl.Fields["replacement"]; ok {
+					if kv, ok := node.(*ast.KeyValue); ok {
+						if str, ok := kv.Value.(*ast.String); ok {
+							rename.Replacement = str.Value
+						}
+					}
+				}
+				c.DropwizardRenames = append(c.DropwizardRenames, rename)
+			}
+		}
+	}
+
+	c.DropwizardFields = make(map[string][]string)
+	for _, metricType := range []string{"counter", "gauge", "histogram", "meter", "timer"} {
+		key := "dropwizard_" + metricType + "_fields"
+		if node, ok := tbl.Fields[key]; ok {
+			if kv, ok := node.(*ast.KeyValue); ok {
+				if ary, ok := kv.Value.(*ast.Array); ok {
+					for _, elem := range ary.Value {
+						if str, ok := elem.(*ast.String); ok {
+							c.DropwizardFields[metricType] = append(c.DropwizardFields[metricType], str.Value)
+						}
+					}
+				}
+			}
+		}
+		delete(tbl.Fields, key)
+	}
+
+	c.DropwizardDerived = make(map[string]string)
+	if node, ok := tbl.Fields["dropwizard_derived"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			for name, val := range subtbl.Fields {
+				if kv, ok := val.(*ast.KeyValue); ok {
+					if str, ok := kv.Value.(*ast.String); ok {
+						c.DropwizardDerived[name] = str.Value
+					}
+				}
+			}
+		}
+	}
+
+	c.DropwizardFieldTypes = make(map[string]string)
+	if node, ok := tbl.Fields["dropwizard_field_types"]; ok {
+		if subtbl, ok := node.(*ast.Table); ok {
+			for name, val := range subtbl.Fields {
+				if kv, ok := val.(*ast.KeyValue); ok {
+					if str, ok := kv.Value.(*ast.String); ok {
+						c.DropwizardFieldTypes[name] = str.Value
+					}
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_nan_policy"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardNaNPolicy = str.Value
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_flatten_object_gauges"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardFlattenObjectGauges, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_flatten_separator"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardFlattenSeparator = str.Value
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_gauge_error_policy"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardGaugeErrorPolicy = str.Value
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_gauge_errors_metric"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardGaugeErrorsMetric, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_metric_types"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.DropwizardMetricTypes = append(c.DropwizardMetricTypes, str.Value)
+					}
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_percentiles"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.DropwizardPercentiles = append(c.DropwizardPercentiles, str.Value)
+					}
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_sample_percentiles"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.DropwizardSamplePercentiles = append(c.DropwizardSamplePercentiles, str.Value)
+					}
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_unit_suffixes"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardUnitSuffixes, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_gauge_value_type"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardGaugeValueType = str.Value
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_normalize_jvm_metrics"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardNormalizeJVMMetrics, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_omit_fields"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if ary, ok := kv.Value.(*ast.Array); ok {
+				for _, elem := range ary.Value {
+					if str, ok := elem.(*ast.String); ok {
+						c.DropwizardOmitFields = append(c.DropwizardOmitFields, str.Value)
+					}
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_timer_nanoseconds"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if b, ok := kv.Value.(*ast.Boolean); ok {
+				var err error
+				c.DropwizardTimerNanoseconds, err = strconv.ParseBool(b.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if node, ok := tbl.Fields["dropwizard_registry_version"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				c.DropwizardRegistryVersion = str.Value
+			}
+		}
+	}
+
 	c.MetricName = name
 
 	delete(tbl.Fields, "data_format")
@@ -1328,8 +2091,40 @@ func buildParser(name string, tbl *ast.Table) (parsers.Parser, error) {
 	delete(tbl.Fields, "dropwizard_time_format")
 	delete(tbl.Fields, "dropwizard_tags_path")
 	delete(tbl.Fields, "dropwizard_tag_paths")
+	delete(tbl.Fields, "dropwizard_field_types")
+	delete(tbl.Fields, "dropwizard_exclude_jvm_metrics")
+	delete(tbl.Fields, "dropwizard_strip_prefixes")
+	delete(tbl.Fields, "dropwizard_rename")
+	delete(tbl.Fields, "dropwizard_derived")
+	delete(tbl.Fields, "dropwizard_registries_path")
+	delete(tbl.Fields, "dropwizard_registry_tag")
+	delete(tbl.Fields, "dropwizard_nan_policy")
+	delete(tbl.Fields, "dropwizard_flatten_object_gauges")
+	delete(tbl.Fields, "dropwizard_flatten_separator")
+	delete(tbl.Fields, "dropwizard_gauge_error_policy")
+	delete(tbl.Fields, "dropwizard_gauge_errors_metric")
+	delete(tbl.Fields, "dropwizard_metric_types")
+	delete(tbl.Fields, "dropwizard_percentiles")
+	delete(tbl.Fields, "dropwizard_sample_percentiles")
+	delete(tbl.Fields, "dropwizard_unit_suffixes")
+	delete(tbl.Fields, "dropwizard_gauge_value_type")
+	delete(tbl.Fields, "dropwizard_normalize_jvm_metrics")
+	delete(tbl.Fields, "dropwizard_omit_fields")
+	delete(tbl.Fields, "dropwizard_timer_nanoseconds")
+	delete(tbl.Fields, "dropwizard_registry_version")
+
+	return c, nil
+}
 
-	return parsers.NewParser(c)
+// keepsMetricNames returns true if the parser returns the metric names of the
+// data unchanged, so that they may be filtered by the server.
+func keepsMetricNames(c *parsers.Config) bool {
+	return c.DataFormat == "dropwizard" &&
+		len(c.Templates) == 0 &&
+		len(c.DropwizardStripPrefixes) == 0 &&
+		len(c.DropwizardRenames) == 0 &&
+		len(c.DropwizardDerived) == 0 &&
+		!c.DropwizardNormalizeJVMMetrics
 }
 
 // buildSerializer grabs the necessary entries from the ast.Table for creating
@@ -1400,8 +2195,95 @@ func buildOutput(name string, tbl *ast.Table) (*models.OutputConfig, error) {
 	}
 	oc := &models.OutputConfig{
 		Name:   name,
+		Alias:  buildAlias(tbl),
 		Filter: filter,
 	}
+
+	if node, ok := tbl.Fields["flush_interval"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.FlushInterval = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["flush_jitter"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				dur, err := time.ParseDuration(str.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.FlushJitter = dur
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["metric_batch_size"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := strconv.Atoi(integer.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.MetricBatchSize = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["metric_buffer_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := strconv.Atoi(integer.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.MetricBufferLimit = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["metric_rate_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := strconv.Atoi(integer.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.MetricRateLimit = v
+			}
+		}
+	}
+
+	if node, ok := tbl.Fields["byte_rate_limit"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if integer, ok := kv.Value.(*ast.Integer); ok {
+				v, err := strconv.Atoi(integer.Value)
+				if err != nil {
+					return nil, err
+				}
+
+				oc.ByteRateLimit = v
+			}
+		}
+	}
+
+	delete(tbl.Fields, "flush_interval")
+	delete(tbl.Fields, "flush_jitter")
+	delete(tbl.Fields, "metric_batch_size")
+	delete(tbl.Fields, "metric_buffer_limit")
+	delete(tbl.Fields, "metric_rate_limit")
+	delete(tbl.Fields, "byte_rate_limit")
+
 	// Outputs don't support FieldDrop/FieldPass, so set to NameDrop/NamePass
 	if len(oc.Filter.FieldDrop) > 0 {
 		oc.Filter.NameDrop = oc.Filter.FieldDrop
@@ -1,18 +1,27 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/models"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/inputs/exec"
+	_ "github.com/influxdata/telegraf/plugins/inputs/http"
 	"github.com/influxdata/telegraf/plugins/inputs/memcached"
 	"github.com/influxdata/telegraf/plugins/inputs/procstat"
+	_ "github.com/influxdata/telegraf/plugins/outputs/file"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/env"
+	_ "github.com/influxdata/telegraf/plugins/secretstores/file"
+	"github.com/influxdata/toml"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_LoadSingleInputWithEnvVars(t *testing.T) {
@@ -174,3 +183,265 @@ func TestConfig_LoadDirectory(t *testing.T) {
 	assert.Equal(t, pConfig, c.Inputs[3].Config,
 		"Merged Testdata did not produce correct procstat metadata.")
 }
+
+func TestConfig_CheckFilters(t *testing.T) {
+	assert.NoError(t, checkFilters([]string{"exec", "memcached"}, nil, nil, nil))
+
+	err := checkFilters([]string{"exec", "nonexistent"}, []string{"bogus"}, nil, nil)
+	assert.EqualError(t, err, "unknown plugins: inputs.nonexistent, outputs.bogus")
+
+	// the data formats read by an input
+	assert.NoError(t, checkFilters([]string{"dropwizard"}, nil, nil, nil))
+
+	// as split from the command line flags
+	assert.NoError(t, checkFilters([]string{"", "exec", ""}, []string{"", "file", ""},
+		[]string{"", ""}, []string{"", ""}))
+}
+
+func TestConfig_SampleInput(t *testing.T) {
+	name, input, service := sampleInput("dropwizard")
+	assert.Equal(t, "http", name)
+	assert.False(t, service)
+	assert.Contains(t, input.SampleConfig(), "\n  data_format = \"dropwizard\"\n")
+	assert.NotContains(t, input.SampleConfig(), `data_format = "influx"`)
+
+	name, input, _ = sampleInput("exec")
+	assert.Equal(t, "exec", name)
+	assert.Contains(t, input.SampleConfig(), `data_format = "influx"`)
+}
+
+func TestConfig_CommonOptionLines(t *testing.T) {
+	assert.Equal(t, []string{
+		"  ## Options common to all inputs, see docs/CONFIGURATION.md",
+		`  # alias = ""`,
+		`  # log_level = "info"`,
+		`  # collection_jitter = "0s"`,
+		`  # collection_offset = "0s"`,
+	}, commonOptionLines("inputs", `
+  ## Interval of the gathers
+  # interval = "1m"
+`))
+
+	assert.Equal(t, []string{
+		"  ## Options common to all aggregators, see docs/CONFIGURATION.md",
+		`  # alias = ""`,
+		`  # log_level = "info"`,
+		`  # delay = "100ms"`,
+	}, commonOptionLines("aggregators", `
+  period = "30s"
+  drop_original = false
+`))
+}
+
+func TestConfig_KeepsMetricNames(t *testing.T) {
+	tests := map[string]bool{
+		`data_format = "dropwizard"`: true,
+		`data_format = "json"`:       false,
+		`data_format = "influx"`:     false,
+		`data_format = "dropwizard"
+dropwizard_strip_prefixes = ["com.example."]`: false,
+		`data_format = "dropwizard"
+[[dropwizard_rename]]
+pattern = "^a"
+replacement = "b"`: false,
+		`data_format = "dropwizard"
+dropwizard_normalize_jvm_metrics = true`: false,
+	}
+	for config, expected := range tests {
+		tbl, err := toml.Parse([]byte(config))
+		require.NoError(t, err)
+		c, err := buildParserConfig("http", tbl)
+		require.NoError(t, err)
+		assert.Equal(t, expected, keepsMetricNames(c), config)
+	}
+}
+
+func TestConfig_BuildInputPrecision(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`
+interval = "5s"
+precision = "1ms"
+`))
+	assert.NoError(t, err)
+
+	cp, err := buildInput("cpu", tbl)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, cp.Interval)
+	assert.Equal(t, time.Millisecond, cp.Precision)
+	assert.Empty(t, tbl.Fields)
+}
+
+func TestConfig_LoadSecrets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("secret\n"), 0600))
+
+	os.Setenv("TELEGRAF_TEST_SERVER", "192.168.1.1")
+	os.Setenv("TELEGRAF_TEST_SECRETS", dir)
+	defer os.Unsetenv("TELEGRAF_TEST_SERVER")
+	defer os.Unsetenv("TELEGRAF_TEST_SECRETS")
+
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/secrets.toml"))
+	require.Len(t, c.SecretStores, 2)
+	require.Len(t, c.Inputs, 2)
+
+	for _, input := range c.Inputs {
+		switch plugin := input.Input.(type) {
+		case *memcached.Memcached:
+			assert.Equal(t, []string{"192.168.1.1:11211"}, plugin.Servers)
+		case *exec.Exec:
+			assert.Equal(t, []string{"/usr/bin/myothercollector --password secret"}, plugin.Commands)
+		default:
+			t.Errorf("unexpected input %T", plugin)
+		}
+	}
+
+	// the checksums are taken from the references, not from the secrets
+	tbl, err := toml.Parse([]byte(`servers = ["@{env:SERVER}:11211"]`))
+	require.NoError(t, err)
+	want := newPluginInstance("inputs.memcached", tbl)
+	require.Len(t, c.Instances, 2)
+	for _, i := range c.Instances {
+		if i.Name == "inputs.memcached" {
+			assert.Equal(t, want.Settings["servers"], i.Settings["servers"])
+		}
+	}
+}
+
+func TestConfig_LoadUnknownSecretStore(t *testing.T) {
+	c := NewConfig()
+	assert.Error(t, c.LoadConfig("./testdata/unknown_secret_store.toml"))
+}
+
+func TestConfig_ExpandEnv(t *testing.T) {
+	os.Setenv("TELEGRAF_TEST_HOST", "db")
+	os.Setenv("TELEGRAF_TEST_EMPTY", "")
+	os.Setenv("TELEGRAF_TEST_QUOTED", `say "hi"`)
+	defer os.Unsetenv("TELEGRAF_TEST_HOST")
+	defer os.Unsetenv("TELEGRAF_TEST_EMPTY")
+	defer os.Unsetenv("TELEGRAF_TEST_QUOTED")
+
+	tests := []struct {
+		in  string
+		out string
+	}{
+		{`host = "$TELEGRAF_TEST_HOST"`, `host = "db"`},
+		{`host = "${TELEGRAF_TEST_HOST}:5432"`, `host = "db:5432"`},
+		{`host = "$TELEGRAF_TEST_HOST/${TELEGRAF_TEST_HOST}"`, `host = "db/db"`},
+		{`host = "${TELEGRAF_TEST_HOST:-localhost}"`, `host = "db"`},
+		{`host = "${TELEGRAF_TEST_UNSET:-localhost}"`, `host = "localhost"`},
+		{`host = "${TELEGRAF_TEST_EMPTY:-localhost}"`, `host = "localhost"`},
+		{`host = "${TELEGRAF_TEST_EMPTY-localhost}"`, `host = ""`},
+		{`host = "${TELEGRAF_TEST_UNSET-localhost}"`, `host = "localhost"`},
+		{`batch = ${TELEGRAF_TEST_UNSET:-1000}`, `batch = 1000`},
+		{`host = "${TELEGRAF_TEST_UNSET:-}"`, `host = ""`},
+		{`host = "${TELEGRAF_TEST_UNSET}"`, `host = "${TELEGRAF_TEST_UNSET}"`},
+		{`host = "$TELEGRAF_TEST_UNSET"`, `host = "$TELEGRAF_TEST_UNSET"`},
+		{`greeting = "${TELEGRAF_TEST_QUOTED}"`, `greeting = "say \"hi\""`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.out, string(expandEnv([]byte(tt.in))), tt.in)
+	}
+}
+
+func TestConfig_DirectoryFiles(t *testing.T) {
+	files, err := DirectoryFiles("./testdata/directory")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"testdata/directory/00-agent.conf",
+		"testdata/directory/10-outputs/file.conf",
+		"testdata/directory/20-memcached.conf",
+	}, files)
+
+	files, err = DirectoryFiles("./testdata/directory/*-*")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"testdata/directory/00-agent.conf",
+		"testdata/directory/10-outputs/file.conf",
+		"testdata/directory/20-memcached.conf",
+		"testdata/directory/30-exec.conf.disabled",
+	}, files)
+
+	_, err = DirectoryFiles("./testdata/directory/[")
+	assert.Error(t, err)
+}
+
+func TestConfig_LoadDirectoryMerge(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadDirectory("./testdata/directory"))
+
+	assert.Equal(t, map[string]string{"dc": "us-east-1", "rack": "2"}, c.Tags)
+	assert.Equal(t, 20*time.Second, c.Agent.Interval.Duration)
+	assert.Len(t, c.Inputs, 1)
+	assert.Len(t, c.Outputs, 1)
+	assert.Len(t, c.Files, 3)
+}
+
+func TestConfig_LoadCollectionJitterAndOffset(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/collection_offset.toml"))
+
+	assert.Equal(t, 3*time.Second, c.Agent.CollectionOffset.Duration)
+	require.Len(t, c.Inputs, 1)
+	assert.Equal(t, 5*time.Second, c.Inputs[0].Config.CollectionJitter)
+	assert.Equal(t, 7*time.Second, c.Inputs[0].Config.CollectionOffset)
+}
+
+func TestConfig_LoadFlushInterval(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/flush_interval.toml"))
+
+	require.Len(t, c.Outputs, 2)
+	assert.Equal(t, 30*time.Second, c.Outputs[0].Config.FlushInterval)
+	assert.Equal(t, 5*time.Second, c.Outputs[0].Config.FlushJitter)
+	assert.Zero(t, c.Outputs[1].Config.FlushInterval)
+	assert.Zero(t, c.Outputs[1].Config.FlushJitter)
+}
+
+func TestConfig_LoadOutputBuffer(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/output_buffer.toml"))
+
+	require.Len(t, c.Outputs, 2)
+	assert.Equal(t, 50, c.Outputs[0].MetricBatchSize)
+	assert.Equal(t, 500, c.Outputs[0].MetricBufferLimit)
+	assert.Equal(t, 1000, c.Outputs[1].MetricBatchSize)
+	assert.Equal(t, 10000, c.Outputs[1].MetricBufferLimit)
+	assert.Equal(t, 100, c.Outputs[1].Config.MetricRateLimit)
+	assert.Equal(t, 65536, c.Outputs[1].Config.ByteRateLimit)
+}
+
+func TestConfig_LoadAlias(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/alias.toml"))
+
+	require.Len(t, c.Inputs, 1)
+	assert.Equal(t, "sessions", c.Inputs[0].Config.Alias)
+	assert.Equal(t, "inputs.memcached::sessions", c.Inputs[0].LogName())
+	require.Len(t, c.Outputs, 1)
+	assert.Equal(t, "console", c.Outputs[0].Config.Alias)
+}
+
+type loggingPlugin struct {
+	Servers []string
+	Log     telegraf.Logger `toml:"-"`
+}
+
+func TestConfig_SetLogger(t *testing.T) {
+	p := &loggingPlugin{}
+	require.NoError(t, setLogger(p, "inputs.test::app1", "debug"))
+	assert.NotNil(t, p.Log)
+
+	assert.Error(t, setLogger(&loggingPlugin{}, "inputs.test", "verbose"))
+	assert.NoError(t, setLogger(&struct{ Log string }{}, "inputs.test", ""))
+}
+
+func TestConfig_LoadLogLevel(t *testing.T) {
+	c := NewConfig()
+	require.NoError(t, c.LoadConfig("./testdata/log_level.toml"))
+	require.Len(t, c.Inputs, 1)
+
+	c = NewConfig()
+	assert.Error(t, c.LoadConfig("./testdata/invalid_log_level.toml"))
+}
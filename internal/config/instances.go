@@ -0,0 +1,269 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/toml/ast"
+)
+
+// PluginInstance identifies a single configured plugin, ie, one
+// [[inputs.http]] table. Option values are only kept as checksums so that
+// instances can be compared without exposing passwords or tokens.
+type PluginInstance struct {
+	// Name is the plugin type and name, ie, "inputs.http"
+	Name string `json:"name"`
+	// Checksum covers all options of the instance
+	Checksum string `json:"checksum"`
+	// Settings maps each option to the checksum of its value
+	Settings map[string]string `json:"settings"`
+
+	// resolved covers all options once their secrets are resolved, it is
+	// only kept in memory to tell a changed secret apart on a reload.
+	resolved string
+}
+
+// PluginChange describes how a plugin instance differs between two
+// configurations.
+type PluginChange struct {
+	Name string
+	// Action is one of "added", "removed" or "changed"
+	Action string
+	// Options lists the options that differ for changed instances
+	Options []string
+}
+
+func (c PluginChange) String() string {
+	switch c.Action {
+	case "added":
+		return "+ " + c.Name
+	case "removed":
+		return "- " + c.Name
+	default:
+		return fmt.Sprintf("~ %s (%s)", c.Name, strings.Join(c.Options, ", "))
+	}
+}
+
+// newPluginInstance builds the PluginInstance of the given plugin table. It
+// must be called before the table is consumed by the plugin builders, as
+// they remove the options they handle from the table, and before its secret
+// references are resolved, so that the checksums served on the control
+// socket cover the references and never the secrets themselves.
+func newPluginInstance(name string, tbl *ast.Table) PluginInstance {
+	settings := make(map[string]string)
+	flattenTable("", tbl, settings)
+
+	return PluginInstance{
+		Name:     name,
+		Checksum: settingsChecksum(settings),
+		Settings: settings,
+	}
+}
+
+// resolveInstanceSecrets resolves the secret references of the table of the
+// instance, and sets the resolved checksum of the instance.
+func (c *Config) resolveInstanceSecrets(instance *PluginInstance, tbl *ast.Table) error {
+	if err := c.resolveSecrets(tbl); err != nil {
+		return err
+	}
+	settings := make(map[string]string)
+	flattenTable("", tbl, settings)
+	instance.resolved = settingsChecksum(settings)
+	return nil
+}
+
+func settingsChecksum(settings map[string]string) string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s\n", k, settings[k])
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)[:8])
+}
+
+func flattenTable(prefix string, tbl *ast.Table, settings map[string]string) {
+	for key, field := range tbl.Fields {
+		switch f := field.(type) {
+		case *ast.KeyValue:
+			sum := sha256.Sum256([]byte(valueString(f.Value)))
+			settings[prefix+key] = fmt.Sprintf("%x", sum[:8])
+		case *ast.Table:
+			flattenTable(prefix+key+".", f, settings)
+		case []*ast.Table:
+			for i, t := range f {
+				flattenTable(fmt.Sprintf("%s%s.%d.", prefix, key, i), t, settings)
+			}
+		}
+	}
+}
+
+func valueString(v ast.Value) string {
+	switch t := v.(type) {
+	case *ast.String:
+		return strconv.Quote(t.Value)
+	case *ast.Integer:
+		return t.Value
+	case *ast.Float:
+		return t.Value
+	case *ast.Boolean:
+		return t.Value
+	case *ast.Datetime:
+		return t.Value
+	case *ast.Array:
+		values := make([]string, 0, len(t.Value))
+		for _, elem := range t.Value {
+			values = append(values, valueString(elem))
+		}
+		return "[" + strings.Join(values, ",") + "]"
+	default:
+		return v.Source()
+	}
+}
+
+// DiffInstances compares the running plugin instances with the instances of
+// a proposed configuration. Identical instances are matched first, remaining
+// instances of the same plugin are then paired in configuration order and
+// reported as changed. Anything left over was added or removed.
+func DiffInstances(running, proposed []PluginInstance) []PluginChange {
+	unmatched := make([]bool, len(running))
+	for i := range unmatched {
+		unmatched[i] = true
+	}
+
+	var leftover []PluginInstance
+	for i, j := range matchIdentical(running, proposed) {
+		if j < 0 {
+			leftover = append(leftover, proposed[i])
+			continue
+		}
+		unmatched[j] = false
+	}
+
+	var changes []PluginChange
+	for _, p := range leftover {
+		paired := false
+		for i, r := range running {
+			if unmatched[i] && r.Name == p.Name {
+				unmatched[i] = false
+				paired = true
+				options := changedOptions(r.Settings, p.Settings)
+				if len(options) == 0 {
+					// only the resolved secrets differ
+					options = []string{"secrets"}
+				}
+				changes = append(changes, PluginChange{
+					Name:    p.Name,
+					Action:  "changed",
+					Options: options,
+				})
+				break
+			}
+		}
+		if !paired {
+			changes = append(changes, PluginChange{Name: p.Name, Action: "added"})
+		}
+	}
+
+	for i, r := range running {
+		if unmatched[i] {
+			changes = append(changes, PluginChange{Name: r.Name, Action: "removed"})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func changedOptions(old, new map[string]string) []string {
+	var options []string
+	for k, v := range old {
+		if nv, ok := new[k]; !ok || nv != v {
+			options = append(options, k)
+		}
+	}
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			options = append(options, k)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// matchIdentical pairs each proposed instance with the first identical
+// running instance not paired yet. The resolved checksums are compared when
+// both instances have one, which the instances read from the control socket
+// don't. It returns the index of the running instance of each proposed
+// instance, or -1 if there is none.
+func matchIdentical(running, proposed []PluginInstance) []int {
+	matches := make([]int, len(proposed))
+	used := make([]bool, len(running))
+	for i, p := range proposed {
+		matches[i] = -1
+		for j, r := range running {
+			if used[j] || r.Name != p.Name || r.Checksum != p.Checksum {
+				continue
+			}
+			if r.resolved == "" || p.resolved == "" || r.resolved == p.resolved {
+				used[j] = true
+				matches[i] = j
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// MatchPlugins pairs the plugins of the given kind, one of "inputs",
+// "processors", "aggregators" or "outputs", of the current configuration
+// with the identical plugins of the previous configuration, as DiffInstances
+// does. It returns the index of the previous plugin of each current plugin,
+// or -1 if there is none.
+func MatchPlugins(kind string, previous, current *Config) []int {
+	prev := previous.pluginInstances(kind)
+	cur := current.pluginInstances(kind)
+	if len(prev) != previous.pluginCount(kind) || len(cur) != current.pluginCount(kind) {
+		matches := make([]int, current.pluginCount(kind))
+		for i := range matches {
+			matches[i] = -1
+		}
+		return matches
+	}
+	return matchIdentical(prev, cur)
+}
+
+// pluginInstances returns the instances of the plugins of the given kind,
+// in the order of the plugins.
+func (c *Config) pluginInstances(kind string) []PluginInstance {
+	var instances []PluginInstance
+	for _, instance := range c.Instances {
+		if strings.HasPrefix(instance.Name, kind+".") {
+			instances = append(instances, instance)
+		}
+	}
+	return instances
+}
+
+func (c *Config) pluginCount(kind string) int {
+	switch kind {
+	case "inputs":
+		return len(c.Inputs)
+	case "processors":
+		return len(c.Processors)
+	case "aggregators":
+		return len(c.Aggregators)
+	case "outputs":
+		return len(c.Outputs)
+	}
+	return 0
+}
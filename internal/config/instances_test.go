@@ -0,0 +1,133 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/influxdata/telegraf/internal/models"
+	_ "github.com/influxdata/telegraf/plugins/processors/override"
+	"github.com/influxdata/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPluginInstance_IgnoresOrder(t *testing.T) {
+	a, err := toml.Parse([]byte(`
+urls = ["http://a", "http://b"]
+timeout = "5s"
+[tags]
+  env = "prod"
+`))
+	require.NoError(t, err)
+	b, err := toml.Parse([]byte(`
+timeout = "5s"
+urls = ["http://a", "http://b"]
+[tags]
+  env = "prod"
+`))
+	require.NoError(t, err)
+
+	ia := newPluginInstance("inputs.http", a)
+	ib := newPluginInstance("inputs.http", b)
+	assert.Equal(t, ia.Checksum, ib.Checksum)
+	assert.Contains(t, ia.Settings, "tags.env")
+}
+
+func TestNewPluginInstance_HidesValues(t *testing.T) {
+	tbl, err := toml.Parse([]byte(`password = "secret"`))
+	require.NoError(t, err)
+
+	i := newPluginInstance("inputs.http", tbl)
+	assert.NotContains(t, i.Settings["password"], "secret")
+}
+
+func TestDiffInstances(t *testing.T) {
+	running := []PluginInstance{
+		{Name: "inputs.cpu", Checksum: "1", Settings: map[string]string{}},
+		{Name: "inputs.http", Checksum: "2",
+			Settings: map[string]string{"urls": "a", "timeout": "b"}},
+		{Name: "inputs.http", Checksum: "3", Settings: map[string]string{"urls": "c"}},
+		{Name: "outputs.influxdb", Checksum: "4", Settings: map[string]string{}},
+	}
+	proposed := []PluginInstance{
+		{Name: "inputs.cpu", Checksum: "1", Settings: map[string]string{}},
+		{Name: "inputs.http", Checksum: "3", Settings: map[string]string{"urls": "c"}},
+		{Name: "inputs.http", Checksum: "5",
+			Settings: map[string]string{"urls": "d", "timeout": "b", "method": "e"}},
+		{Name: "inputs.mem", Checksum: "6", Settings: map[string]string{}},
+	}
+
+	changes := DiffInstances(running, proposed)
+	expected := []PluginChange{
+		{Name: "inputs.http", Action: "changed", Options: []string{"method", "urls"}},
+		{Name: "inputs.mem", Action: "added"},
+		{Name: "outputs.influxdb", Action: "removed"},
+	}
+	assert.Equal(t, expected, changes)
+	assert.Equal(t, "~ inputs.http (method, urls)", changes[0].String())
+	assert.Equal(t, "+ inputs.mem", changes[1].String())
+	assert.Equal(t, "- outputs.influxdb", changes[2].String())
+}
+
+func TestDiffInstances_Unchanged(t *testing.T) {
+	c := NewConfig()
+	err := c.LoadConfig("./testdata/single_plugin.toml")
+	require.NoError(t, err)
+	require.Len(t, c.Instances, 1)
+	assert.Equal(t, "inputs.memcached", c.Instances[0].Name)
+
+	assert.Empty(t, DiffInstances(c.Instances, c.Instances))
+}
+
+func TestDiffInstances_Secrets(t *testing.T) {
+	running := []PluginInstance{
+		{Name: "inputs.exec", Checksum: "1", Settings: map[string]string{}, resolved: "a"},
+	}
+	proposed := []PluginInstance{
+		{Name: "inputs.exec", Checksum: "1", Settings: map[string]string{}, resolved: "b"},
+	}
+	changes := DiffInstances(running, proposed)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "~ inputs.exec (secrets)", changes[0].String())
+
+	// the instances read from the control socket have no resolved checksum
+	running[0].resolved = ""
+	assert.Empty(t, DiffInstances(running, proposed))
+}
+
+func TestMatchPlugins(t *testing.T) {
+	previous := &Config{
+		Outputs: make([]*models.RunningOutput, 3),
+		Instances: []PluginInstance{
+			{Name: "inputs.cpu", Checksum: "1"},
+			{Name: "outputs.influxdb", Checksum: "2"},
+			{Name: "outputs.file", Checksum: "3"},
+			{Name: "outputs.influxdb", Checksum: "4"},
+		},
+	}
+	current := &Config{
+		Outputs: make([]*models.RunningOutput, 3),
+		Instances: []PluginInstance{
+			{Name: "outputs.influxdb", Checksum: "4"},
+			{Name: "outputs.file", Checksum: "5"},
+			{Name: "inputs.cpu", Checksum: "6"},
+			{Name: "outputs.influxdb", Checksum: "2"},
+		},
+	}
+
+	assert.Equal(t, []int{2, -1, 0}, MatchPlugins("outputs", previous, current))
+
+	// the instances do not match the plugins
+	previous.Inputs = make([]*models.RunningInput, 2)
+	current.Inputs = make([]*models.RunningInput, 1)
+	assert.Equal(t, []int{-1}, MatchPlugins("inputs", previous, current))
+}
+
+func TestMatchPlugins_SortedProcessors(t *testing.T) {
+	previous := NewConfig()
+	require.NoError(t, previous.LoadConfig("./testdata/processor_order.toml"))
+	current := NewConfig()
+	require.NoError(t, current.LoadConfig("./testdata/processor_order_changed.toml"))
+
+	// the instances are sorted along with the processors
+	assert.Equal(t, []int{0, -1}, MatchPlugins("processors", previous, current))
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+
+	"github.com/influxdata/toml"
+	"github.com/influxdata/toml/ast"
+)
+
+var (
+	// secretRe is a regex to find the secret references, ie,
+	// @{vault:password}, in the option values
+	secretRe = regexp.MustCompile(`@\{(\w+):([^}]+)\}`)
+
+	// storeIDRe is a regex validating the ids of the secret stores
+	storeIDRe = regexp.MustCompile(`^\w+$`)
+)
+
+func (c *Config) addSecretStore(name string, table *ast.Table) error {
+	creator, ok := secretstores.SecretStores[name]
+	if !ok {
+		return fmt.Errorf("Undefined but requested secret store: %s", name)
+	}
+	store := creator()
+
+	// a store can use the secrets of the stores defined before it, ie, the
+	// token of a vault store
+	if err := c.resolveSecrets(table); err != nil {
+		return err
+	}
+
+	var id string
+	if node, ok := table.Fields["id"]; ok {
+		if kv, ok := node.(*ast.KeyValue); ok {
+			if str, ok := kv.Value.(*ast.String); ok {
+				id = str.Value
+			}
+		}
+		delete(table.Fields, "id")
+	}
+	if !storeIDRe.MatchString(id) {
+		return fmt.Errorf("invalid id %q of secret store %s", id, name)
+	}
+	if _, ok := c.SecretStores[id]; ok {
+		return fmt.Errorf("duplicate id %q of secret store %s", id, name)
+	}
+
+	if err := toml.UnmarshalTable(table, store); err != nil {
+		return err
+	}
+	// unlike the plugins, the stores are initialized as soon as they are
+	// loaded since the options of the plugins need their secrets
+	if p, ok := store.(telegraf.Initializer); ok {
+		if err := p.Init(); err != nil {
+			return fmt.Errorf("secret store %s: %s", id, err)
+		}
+	}
+
+	c.SecretStores[id] = store
+	return nil
+}
+
+// tablePos returns the position of the first of the tables of a field, to
+// load the tables in the order of the file.
+func tablePos(field interface{}) int {
+	switch t := field.(type) {
+	case *ast.Table:
+		return t.Position.Begin
+	case []*ast.Table:
+		if len(t) > 0 {
+			return t[0].Position.Begin
+		}
+	}
+	return 0
+}
+
+// resolveSecrets replaces the secret references of the string values of the
+// table and its subtables with the secrets of the stores.
+func (c *Config) resolveSecrets(tbl *ast.Table) error {
+	for _, field := range tbl.Fields {
+		switch f := field.(type) {
+		case *ast.KeyValue:
+			if err := c.resolveValue(f.Value); err != nil {
+				return err
+			}
+		case *ast.Table:
+			if err := c.resolveSecrets(f); err != nil {
+				return err
+			}
+		case []*ast.Table:
+			for _, t := range f {
+				if err := c.resolveSecrets(t); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) resolveValue(value ast.Value) error {
+	switch v := value.(type) {
+	case *ast.String:
+		resolved, err := c.resolveString(v.Value)
+		if err != nil {
+			return err
+		}
+		v.Value = resolved
+	case *ast.Array:
+		for _, elem := range v.Value {
+			if err := c.resolveValue(elem); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) resolveString(s string) (string, error) {
+	var err error
+	resolved := secretRe.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		match := secretRe.FindStringSubmatch(ref)
+		store, ok := c.SecretStores[match[1]]
+		if !ok {
+			err = fmt.Errorf("unknown secret store %q referenced by %s", match[1], ref)
+			return ref
+		}
+		secret, getErr := store.Get(match[2])
+		if getErr != nil {
+			err = fmt.Errorf("could not get the secret %s: %s", ref, getErr)
+			return ref
+		}
+		return secret
+	})
+	return resolved, err
+}
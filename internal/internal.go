@@ -67,6 +67,49 @@ func (d *Duration) UnmarshalTOML(b []byte) error {
 	return nil
 }
 
+// Size is a number of bytes, parsed from an integer or from a string with a
+// unit, ie, "10MB" or "512KiB".
+type Size struct {
+	Size int64
+}
+
+var sizeUnits = map[string]int64{
+	"":    1,
+	"B":   1,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"KIB": 1 << 10,
+	"MIB": 1 << 20,
+	"GIB": 1 << 30,
+}
+
+// UnmarshalTOML parses the size from the TOML config file
+func (s *Size) UnmarshalTOML(b []byte) error {
+	str := string(bytes.Trim(b, `'`))
+	if uq, err := strconv.Unquote(str); err == nil {
+		str = uq
+	}
+	str = strings.TrimSpace(str)
+
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return !unicode.IsDigit(r)
+	})
+	if i < 0 {
+		i = len(str)
+	}
+	n, err := strconv.ParseInt(str[:i], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", str)
+	}
+	unit, ok := sizeUnits[strings.ToUpper(strings.TrimSpace(str[i:]))]
+	if !ok {
+		return fmt.Errorf("invalid size unit in %q", str)
+	}
+	s.Size = n * unit
+	return nil
+}
+
 // ReadLines reads contents from a file and splits them by new lines.
 // A convenience wrapper to ReadLinesOffsetN(filename, 0, -1).
 func ReadLines(filename string) ([]string, error) {
@@ -118,6 +161,15 @@ func RandomString(n int) string {
 func GetTLSConfig(
 	SSLCert, SSLKey, SSLCA string,
 	InsecureSkipVerify bool,
+) (*tls.Config, error) {
+	return GetTLSConfigWithKeyPassword(SSLCert, SSLKey, "", SSLCA, InsecureSkipVerify)
+}
+
+// GetTLSConfigWithKeyPassword is GetTLSConfig for encrypted private keys,
+// which are decrypted with SSLKeyPassword.
+func GetTLSConfigWithKeyPassword(
+	SSLCert, SSLKey, SSLKeyPassword, SSLCA string,
+	InsecureSkipVerify bool,
 ) (*tls.Config, error) {
 	if SSLCert == "" && SSLKey == "" && SSLCA == "" && !InsecureSkipVerify {
 		return nil, nil
@@ -140,7 +192,7 @@ func GetTLSConfig(
 	}
 
 	if SSLCert != "" && SSLKey != "" {
-		cert, err := tls.LoadX509KeyPair(SSLCert, SSLKey)
+		cert, err := LoadX509KeyPair(SSLCert, SSLKey, SSLKeyPassword)
 		if err != nil {
 			return nil, errors.New(fmt.Sprintf(
 				"Could not load TLS client key/certificate from %s:%s: %s",
@@ -155,6 +207,44 @@ func GetTLSConfig(
 	return t, nil
 }
 
+// GetServerTLSConfig gets the tls.Config of a server from the given cert and
+// key files.  Clients must present a certificate signed by one of the
+// AllowedCACerts files, if any.
+// If the cert and key are blank, returns a nil pointer.
+func GetServerTLSConfig(
+	TLSCert, TLSKey string,
+	AllowedCACerts []string,
+) (*tls.Config, error) {
+	if TLSCert == "" && TLSKey == "" {
+		return nil, nil
+	}
+
+	cert, err := LoadX509KeyPair(TLSCert, TLSKey, "")
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Could not load TLS server key/certificate from %s:%s: %s",
+			TLSKey, TLSCert, err)
+	}
+	t := &tls.Config{
+		Certificates:  []tls.Certificate{cert},
+		Renegotiation: tls.RenegotiateNever,
+	}
+
+	if len(AllowedCACerts) > 0 {
+		pool := x509.NewCertPool()
+		for _, ca := range AllowedCACerts {
+			caCert, err := ioutil.ReadFile(ca)
+			if err != nil {
+				return nil, fmt.Errorf("Could not load TLS client CA: %s", err)
+			}
+			pool.AppendCertsFromPEM(caCert)
+		}
+		t.ClientCAs = pool
+		t.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return t, nil
+}
+
 // SnakeCase converts the given string to snake case following the Golang format:
 // acronyms are converted to lower-case and preceded by an underscore.
 func SnakeCase(in string) string {
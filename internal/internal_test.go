@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type SnakeTest struct {
@@ -140,6 +141,29 @@ func TestRandomSleep(t *testing.T) {
 	assert.True(t, elapsed < time.Millisecond*150)
 }
 
+func TestSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{`1024`, 1024},
+		{`"1024"`, 1024},
+		{`"10B"`, 10},
+		{`"10MB"`, 10 * 1000 * 1000},
+		{`'1 KiB'`, 1024},
+		{`"2gib"`, 2 << 30},
+	}
+	for _, tt := range tests {
+		var s Size
+		require.NoError(t, s.UnmarshalTOML([]byte(tt.input)), tt.input)
+		assert.Equal(t, tt.expected, s.Size, tt.input)
+	}
+
+	var s Size
+	assert.Error(t, s.UnmarshalTOML([]byte(`"10XB"`)))
+	assert.Error(t, s.UnmarshalTOML([]byte(`"MB"`)))
+}
+
 func TestDuration(t *testing.T) {
 	var d Duration
 
@@ -0,0 +1,52 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// Bucket is a token bucket limiting the rate of events, ie, the metrics or
+// the bytes written by an output, to rate events per second. Bursts of up to
+// one second worth of events go through at once, larger ones are spread over
+// time by delaying the following events.
+type Bucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewBucket returns a full Bucket allowing rate events per second.
+func NewBucket(rate float64) *Bucket {
+	b := &Bucket{
+		rate:   rate,
+		tokens: rate,
+		now:    time.Now,
+	}
+	b.last = b.now()
+	return b
+}
+
+// Reserve takes n events from the bucket, and returns how long to wait
+// before they may happen.
+func (b *Bucket) Reserve(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
@@ -0,0 +1,30 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucket(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	b := NewBucket(100)
+	b.now = func() time.Time { return now }
+	b.last = now
+
+	// a burst of up to one second of events goes through
+	assert.Zero(t, b.Reserve(60))
+	assert.Zero(t, b.Reserve(40))
+	// the next events wait for the bucket to refill
+	assert.Equal(t, 500*time.Millisecond, b.Reserve(50))
+
+	// the bucket refills at rate, once the debt is paid
+	now = now.Add(1500 * time.Millisecond)
+	assert.Zero(t, b.Reserve(100))
+
+	// it never holds more than one second of events
+	now = now.Add(time.Minute)
+	assert.Zero(t, b.Reserve(100))
+	assert.Equal(t, 2*time.Second, b.Reserve(200))
+}
@@ -0,0 +1,20 @@
+package models
+
+// logName returns the name of a plugin instance in the logs, the plugin name
+// followed by the alias of the instance if any, ie, "inputs.http::app1".
+func logName(pluginType, name, alias string) string {
+	if alias == "" {
+		return pluginType + "." + name
+	}
+	return pluginType + "." + name + "::" + alias
+}
+
+// statTags returns the tags of the self-metrics of a plugin instance, the
+// alias tag telling apart the instances of the same plugin.
+func statTags(key, name, alias string) map[string]string {
+	tags := map[string]string{key: name}
+	if alias != "" {
+		tags["alias"] = alias
+	}
+	return tags
+}
@@ -31,7 +31,8 @@ func NewRunningAggregator(
 // AggregatorConfig containing configuration parameters for the running
 // aggregator plugin.
 type AggregatorConfig struct {
-	Name string
+	Name  string
+	Alias string
 
 	DropOriginal      bool
 	NameOverride      string
@@ -48,6 +49,19 @@ func (r *RunningAggregator) Name() string {
 	return "aggregators." + r.Config.Name
 }
 
+// LogName returns the name of the aggregator in the logs, with its alias.
+func (r *RunningAggregator) LogName() string {
+	return logName("aggregators", r.Config.Name, r.Config.Alias)
+}
+
+// Init initializes the aggregator if it implements telegraf.Initializer.
+func (r *RunningAggregator) Init() error {
+	if p, ok := r.a.(telegraf.Initializer); ok {
+		return p.Init()
+	}
+	return nil
+}
+
 func (r *RunningAggregator) MakeMetric(
 	measurement string,
 	fields map[string]interface{},
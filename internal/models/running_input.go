@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
@@ -16,8 +17,12 @@ type RunningInput struct {
 
 	trace       bool
 	defaultTags map[string]string
+	// gatherMu serializes the gathers of the input
+	gatherMu sync.Mutex
 
 	MetricsGathered selfstat.Stat
+	MetricsDropped  selfstat.Stat
+	GatherErrors    selfstat.Stat
 }
 
 func NewRunningInput(
@@ -30,7 +35,17 @@ func NewRunningInput(
 		MetricsGathered: selfstat.Register(
 			"gather",
 			"metrics_gathered",
-			map[string]string{"input": config.Name},
+			statTags("input", config.Name, config.Alias),
+		),
+		MetricsDropped: selfstat.Register(
+			"gather",
+			"metrics_dropped",
+			statTags("input", config.Name, config.Alias),
+		),
+		GatherErrors: selfstat.Register(
+			"gather",
+			"errors",
+			statTags("input", config.Name, config.Alias),
 		),
 	}
 }
@@ -38,18 +53,30 @@ func NewRunningInput(
 // InputConfig containing a name, interval, and filter
 type InputConfig struct {
 	Name              string
+	Alias             string
 	NameOverride      string
 	MeasurementPrefix string
 	MeasurementSuffix string
 	Tags              map[string]string
 	Filter            Filter
 	Interval          time.Duration
+	// Precision overrides the precision of the agent when not zero
+	Precision time.Duration
+	// CollectionJitter and CollectionOffset override the collection jitter
+	// and offset of the agent when not zero
+	CollectionJitter time.Duration
+	CollectionOffset time.Duration
 }
 
 func (r *RunningInput) Name() string {
 	return "inputs." + r.Config.Name
 }
 
+// LogName returns the name of the input in the logs, with its alias.
+func (r *RunningInput) LogName() string {
+	return logName("inputs", r.Config.Name, r.Config.Alias)
+}
+
 // MakeMetric either returns a metric, or returns nil if the metric doesn't
 // need to be created (because of filtering, an error, etc.)
 func (r *RunningInput) MakeMetric(
@@ -77,12 +104,29 @@ func (r *RunningInput) MakeMetric(
 	if r.trace && m != nil {
 		fmt.Print("> " + m.String())
 	}
+	if m == nil {
+		r.MetricsDropped.Incr(1)
+	}
 
 	r.MetricsGathered.Incr(1)
 	GlobalMetricsGathered.Incr(1)
 	return m
 }
 
+// Gather gathers the metrics of the input. The gathers are serialized, so
+// that an input kept across a reload of the configuration is not gathered
+// again while the gather started before the reload is still running.
+func (r *RunningInput) Gather(acc telegraf.Accumulator) error {
+	r.gatherMu.Lock()
+	defer r.gatherMu.Unlock()
+	return r.Input.Gather(acc)
+}
+
+// IncrErrors counts an error of the input.
+func (r *RunningInput) IncrErrors() {
+	r.GatherErrors.Incr(1)
+}
+
 func (r *RunningInput) Trace() bool {
 	return r.trace
 }
@@ -168,6 +168,21 @@ func TestMakeMetricFilteredOut(t *testing.T) {
 	assert.Nil(t, m)
 }
 
+func TestMakeMetricDroppedStat(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name:   "TestRunningInputDropped",
+		Filter: Filter{NameDrop: []string{"dropped"}},
+	})
+	require.NoError(t, ri.Config.Filter.Compile())
+
+	fields := map[string]interface{}{"value": int(101)}
+	assert.NotNil(t, ri.MakeMetric("kept", fields, nil, telegraf.Untyped, time.Now()))
+	assert.Nil(t, ri.MakeMetric("dropped", fields, nil, telegraf.Untyped, time.Now()))
+
+	assert.Equal(t, int64(2), ri.MetricsGathered.Get())
+	assert.Equal(t, int64(1), ri.MetricsDropped.Get())
+}
+
 func TestMakeMetricWithDaemonTags(t *testing.T) {
 	now := time.Now()
 	ri := NewRunningInput(&testInput{}, &InputConfig{
@@ -461,3 +476,20 @@ type testInput struct{}
 func (t *testInput) Description() string                   { return "" }
 func (t *testInput) SampleConfig() string                  { return "" }
 func (t *testInput) Gather(acc telegraf.Accumulator) error { return nil }
+
+func TestRunningInputAlias(t *testing.T) {
+	ri := NewRunningInput(&testInput{}, &InputConfig{
+		Name: "http",
+	})
+	assert.Equal(t, "inputs.http", ri.LogName())
+	assert.Equal(t, map[string]string{"input": "http"}, ri.GatherErrors.Tags())
+
+	ri = NewRunningInput(&testInput{}, &InputConfig{
+		Name:  "http",
+		Alias: "app1",
+	})
+	assert.Equal(t, "inputs.http::app1", ri.LogName())
+	assert.Equal(t, "inputs.http", ri.Name())
+	assert.Equal(t, map[string]string{"input": "http", "alias": "app1"},
+		ri.GatherErrors.Tags())
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal/buffer"
+	"github.com/influxdata/telegraf/internal/limiter"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -29,12 +30,24 @@ type RunningOutput struct {
 
 	MetricsFiltered selfstat.Stat
 	MetricsWritten  selfstat.Stat
+	MetricsDropped  selfstat.Stat
+	WriteErrors     selfstat.Stat
 	BufferSize      selfstat.Stat
 	BufferLimit     selfstat.Stat
+	DiskBufferSize  selfstat.Stat
 	WriteTime       selfstat.Stat
 
 	metrics     *buffer.Buffer
 	failMetrics *buffer.Buffer
+	diskMetrics *buffer.DiskQueue
+
+	// BatchReady, when set, is signaled once a full batch is buffered, which
+	// is then left to the flusher of the output instead of being written by
+	// AddMetric.
+	BatchReady chan struct{}
+
+	metricLimiter *limiter.Bucket
+	byteLimiter   *limiter.Bucket
 
 	// Guards against concurrent calls to the Output as described in #3009
 	sync.Mutex
@@ -64,35 +77,64 @@ func NewRunningOutput(
 		MetricsWritten: selfstat.Register(
 			"write",
 			"metrics_written",
-			map[string]string{"output": name},
+			statTags("output", name, conf.Alias),
 		),
 		MetricsFiltered: selfstat.Register(
 			"write",
 			"metrics_filtered",
-			map[string]string{"output": name},
+			statTags("output", name, conf.Alias),
+		),
+		MetricsDropped: selfstat.Register(
+			"write",
+			"metrics_dropped",
+			statTags("output", name, conf.Alias),
+		),
+		WriteErrors: selfstat.Register(
+			"write",
+			"errors",
+			statTags("output", name, conf.Alias),
 		),
 		BufferSize: selfstat.Register(
 			"write",
 			"buffer_size",
-			map[string]string{"output": name},
+			statTags("output", name, conf.Alias),
 		),
 		BufferLimit: selfstat.Register(
 			"write",
 			"buffer_limit",
-			map[string]string{"output": name},
+			statTags("output", name, conf.Alias),
+		),
+		DiskBufferSize: selfstat.Register(
+			"write",
+			"disk_buffer_size",
+			statTags("output", name, conf.Alias),
 		),
 		WriteTime: selfstat.RegisterTiming(
 			"write",
 			"write_time_ns",
-			map[string]string{"output": name},
+			statTags("output", name, conf.Alias),
 		),
 	}
+	if conf.MetricRateLimit > 0 {
+		ro.metricLimiter = limiter.NewBucket(float64(conf.MetricRateLimit))
+	}
+	if conf.ByteRateLimit > 0 {
+		ro.byteLimiter = limiter.NewBucket(float64(conf.ByteRateLimit))
+	}
 	ro.BufferLimit.Set(int64(ro.MetricBufferLimit))
 	return ro
 }
 
-// AddMetric adds a metric to the output. This function can also write cached
-// points if FlushBufferWhenFull is true.
+// SetDiskBuffer sets the queue persisting the failed writes which do not fit
+// in the buffer anymore.
+func (ro *RunningOutput) SetDiskBuffer(q *buffer.DiskQueue) {
+	ro.diskMetrics = q
+	ro.DiskBufferSize.Set(int64(q.Len()))
+}
+
+// AddMetric adds a metric to the output. This function can also write the
+// batch it completes, unless the output is rate limited or has BatchReady
+// set, in which case the batch stays buffered for the next flush.
 func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
 	if m == nil {
 		return
@@ -113,12 +155,27 @@ func (ro *RunningOutput) AddMetric(m telegraf.Metric) {
 		m, _ = metric.New(name, tags, fields, t)
 	}
 
-	ro.metrics.Add(m)
+	ro.MetricsDropped.Incr(int64(ro.metrics.Add(m)))
 	if ro.metrics.Len() == ro.MetricBatchSize {
 		batch := ro.metrics.Batch(ro.MetricBatchSize)
+		// metrics waiting on disk are older, so queue the batch behind them.
+		// A rate limited output never waits here, ie, in the goroutine
+		// feeding all the outputs.
+		if ro.BatchReady != nil || ro.metricLimiter != nil || ro.byteLimiter != nil ||
+			(ro.diskMetrics != nil && ro.diskMetrics.Len() > 0) {
+			ro.addFailed(batch)
+			if ro.BatchReady != nil {
+				select {
+				case ro.BatchReady <- struct{}{}:
+				default:
+					// a flush of this output is already requested
+				}
+			}
+			return
+		}
 		err := ro.write(batch)
 		if err != nil {
-			ro.failMetrics.Add(batch...)
+			ro.addFailed(failedMetrics(batch, err))
 		}
 	}
 }
@@ -128,7 +185,7 @@ func (ro *RunningOutput) Write() error {
 	nFails, nMetrics := ro.failMetrics.Len(), ro.metrics.Len()
 	ro.BufferSize.Set(int64(nFails + nMetrics))
 	log.Printf("D! Output [%s] buffer fullness: %d / %d metrics. ",
-		ro.Name, nFails+nMetrics, ro.MetricBufferLimit)
+		ro.LogName(), nFails+nMetrics, ro.MetricBufferLimit)
 	var err error
 	if !ro.failMetrics.IsEmpty() {
 		// how many batches of failed writes we need to write.
@@ -149,11 +206,20 @@ func (ro *RunningOutput) Write() error {
 				err = ro.write(batch)
 			}
 			if err != nil {
-				ro.failMetrics.Add(batch...)
+				// there is room for the batch since it was just taken out.
+				ro.MetricsDropped.Incr(int64(ro.failMetrics.Add(failedMetrics(batch, err)...)))
 			}
 		}
 	}
 
+	// the metrics spilled to disk are older than the current batch.
+	if ro.diskMetrics != nil {
+		for err == nil && ro.diskMetrics.Len() > 0 {
+			err = ro.writeDisk()
+		}
+		ro.DiskBufferSize.Set(int64(ro.diskMetrics.Len()))
+	}
+
 	batch := ro.metrics.Batch(ro.MetricBatchSize)
 	// see comment above about not trying to write to an already failed output.
 	// if ro.failMetrics is empty then err will always be nil at this point.
@@ -162,17 +228,33 @@ func (ro *RunningOutput) Write() error {
 	}
 
 	if err != nil {
-		ro.failMetrics.Add(batch...)
+		ro.addFailed(failedMetrics(batch, err))
 		return err
 	}
 	return nil
 }
 
+// failedMetrics returns the metrics of a batch to write again after the
+// given write error, only the failed ones when the output wrote the others.
+func failedMetrics(batch []telegraf.Metric, err error) []telegraf.Metric {
+	if perr, ok := err.(*telegraf.PartialWriteError); ok {
+		return perr.Failed
+	}
+	return batch
+}
+
 func (ro *RunningOutput) write(metrics []telegraf.Metric) error {
 	nMetrics := len(metrics)
 	if nMetrics == 0 {
 		return nil
 	}
+	// the batch waits for the rate limits before taking the lock, so that
+	// the metrics keep being added meanwhile
+	if wait := ro.rateLimit(metrics); wait > 0 {
+		log.Printf("D! Output [%s] rate limited, delaying batch of %d metrics by %s\n",
+			ro.LogName(), nMetrics, wait)
+		time.Sleep(wait)
+	}
 	ro.Lock()
 	defer ro.Unlock()
 	start := time.Now()
@@ -180,15 +262,105 @@ func (ro *RunningOutput) write(metrics []telegraf.Metric) error {
 	elapsed := time.Since(start)
 	if err == nil {
 		log.Printf("D! Output [%s] wrote batch of %d metrics in %s\n",
-			ro.Name, nMetrics, elapsed)
+			ro.LogName(), nMetrics, elapsed)
 		ro.MetricsWritten.Incr(int64(nMetrics))
 		ro.WriteTime.Incr(elapsed.Nanoseconds())
+	} else {
+		ro.WriteErrors.Incr(1)
+		if perr, ok := err.(*telegraf.PartialWriteError); ok {
+			ro.MetricsWritten.Incr(int64(nMetrics - len(perr.Failed)))
+		}
 	}
 	return err
 }
 
+// rateLimit returns how long to wait before writing a batch to stay within
+// the rate limits of the output.
+func (ro *RunningOutput) rateLimit(metrics []telegraf.Metric) time.Duration {
+	var wait time.Duration
+	if ro.metricLimiter != nil {
+		wait = ro.metricLimiter.Reserve(len(metrics))
+	}
+	if ro.byteLimiter != nil {
+		n := 0
+		for _, m := range metrics {
+			n += m.Len()
+		}
+		if d := ro.byteLimiter.Reserve(n); d > wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// writeDisk writes the oldest batch of the disk buffer, removing it once
+// written. A batch which cannot be read back is dropped.
+func (ro *RunningOutput) writeDisk() error {
+	batch, err := ro.diskMetrics.Peek()
+	if err != nil {
+		log.Printf("E! Output [%s] dropping unreadable disk buffer batch: %s",
+			ro.LogName(), err)
+		ro.diskMetrics.Pop()
+		return nil
+	}
+	if err := ro.write(batch); err != nil {
+		// the metrics written are not kept on disk
+		if perr, ok := err.(*telegraf.PartialWriteError); ok {
+			ro.diskMetrics.Pop()
+			ro.addFailed(perr.Failed)
+		}
+		return err
+	}
+	ro.diskMetrics.Pop()
+	return nil
+}
+
+// addFailed adds a batch which could not be written, or which is left for
+// the next flush, to the buffer of failed writes, counting the metrics it
+// drops once full. With a disk buffer, the batches which do not fit in memory
+// are spilled to disk instead.
+func (ro *RunningOutput) addFailed(batch []telegraf.Metric) {
+	if ro.diskMetrics != nil && (ro.diskMetrics.Len() > 0 ||
+		ro.failMetrics.Len()+len(batch) > ro.MetricBufferLimit) {
+		dropped, err := ro.diskMetrics.Push(batch)
+		if err == nil {
+			ro.MetricsDropped.Incr(int64(dropped))
+			ro.DiskBufferSize.Set(int64(ro.diskMetrics.Len()))
+			return
+		}
+		log.Printf("E! Output [%s] could not write to the disk buffer: %s",
+			ro.LogName(), err)
+	}
+	ro.MetricsDropped.Incr(int64(ro.failMetrics.Add(batch...)))
+}
+
 // OutputConfig containing name and filter
 type OutputConfig struct {
 	Name   string
+	Alias  string
 	Filter Filter
+
+	// Checksum is the checksum of the options of the output in the
+	// configuration
+	Checksum string
+
+	// FlushInterval and FlushJitter override the flush interval and jitter
+	// of the agent when not zero
+	FlushInterval time.Duration
+	FlushJitter   time.Duration
+
+	// MetricBatchSize and MetricBufferLimit override the batch size and
+	// buffer limit of the agent when not zero
+	MetricBatchSize   int
+	MetricBufferLimit int
+
+	// MetricRateLimit and ByteRateLimit limit the metrics and the bytes of
+	// line protocol written per second when not zero
+	MetricRateLimit int
+	ByteRateLimit   int
+}
+
+// LogName returns the name of the output in the logs, with its alias.
+func (ro *RunningOutput) LogName() string {
+	return logName("outputs", ro.Name, ro.Config.Alias)
 }
@@ -2,10 +2,14 @@ package models
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal/buffer"
 	"github.com/influxdata/telegraf/testutil"
 
 	"github.com/stretchr/testify/assert"
@@ -298,6 +302,52 @@ func TestRunningOutputMultiFlushWhenFull(t *testing.T) {
 	assert.Len(t, m.Metrics(), 8)
 }
 
+// Test that a full batch is left to the flusher when BatchReady is set.
+func TestRunningOutputBatchReady(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 4, 12)
+	ro.BatchReady = make(chan struct{}, 1)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	assert.Len(t, m.Metrics(), 0)
+	assert.Len(t, ro.BatchReady, 1)
+
+	err := ro.Write()
+	assert.NoError(t, err)
+	assert.Equal(t, append(append([]telegraf.Metric{}, first5...), next5...), m.Metrics())
+}
+
+// Test that a rate limited output never writes, and waits, from AddMetric.
+func TestRunningOutputRateLimitedBatch(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:          Filter{},
+		MetricRateLimit: 1,
+	}
+
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 5, 100)
+
+	start := time.Now()
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Len(t, m.Metrics(), 0)
+	assert.Equal(t, 10, ro.failMetrics.Len())
+}
+
 func TestRunningOutputWriteFail(t *testing.T) {
 	conf := &OutputConfig{
 		Filter: Filter{},
@@ -330,6 +380,48 @@ func TestRunningOutputWriteFail(t *testing.T) {
 	assert.Len(t, m.Metrics(), 10)
 }
 
+func TestRunningOutputWriteFailStats(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("write_fail_stats", m, conf, 2, 4)
+
+	// every full batch fails, the buffer of failed writes holds 4 metrics
+	for _, metric := range append(first5, next5[:3]...) {
+		ro.AddMetric(metric)
+	}
+	assert.Equal(t, int64(4), ro.WriteErrors.Get())
+	assert.Equal(t, int64(4), ro.MetricsDropped.Get())
+	assert.Equal(t, int64(0), ro.MetricsWritten.Get())
+}
+
+func TestRunningOutputPartialWriteFail(t *testing.T) {
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failLast = true
+	ro := NewRunningOutput("partial_write_fail", m, conf, 4, 12)
+
+	for _, metric := range first5[:4] {
+		ro.AddMetric(metric)
+	}
+	// the full batch is written but for its last metric
+	assert.Len(t, m.Metrics(), 3)
+	assert.Equal(t, int64(3), ro.MetricsWritten.Get())
+
+	// only the failed metric is written again
+	m.failLast = false
+	require.NoError(t, ro.Write())
+	assert.Len(t, m.Metrics(), 4)
+	assert.Equal(t, first5[3], m.Metrics()[3])
+	assert.Equal(t, int64(4), ro.MetricsWritten.Get())
+}
+
 // Verify that the order of points is preserved during a write failure.
 func TestRunningOutputWriteFailOrder(t *testing.T) {
 	conf := &OutputConfig{
@@ -479,6 +571,108 @@ func TestRunningOutputWriteFailOrder3(t *testing.T) {
 	assert.Equal(t, expected, m.Metrics())
 }
 
+// Verify that the failed writes overflowing the buffer are spilled to the
+// disk buffer and written in order once the output recovers.
+func TestRunningOutputDiskBuffer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+
+	m := &mockOutput{}
+	m.failWrite = true
+	ro := NewRunningOutput("test", m, conf, 5, 5)
+	q, err := buffer.OpenDiskQueue(dir, 0)
+	require.NoError(t, err)
+	ro.SetDiskBuffer(q)
+
+	for _, metric := range first5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	assert.Zero(t, q.Len())
+
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	require.Error(t, ro.Write())
+	assert.Equal(t, 5, q.Len())
+	assert.Equal(t, int64(5), ro.DiskBufferSize.Get())
+
+	m.failWrite = false
+	require.NoError(t, ro.Write())
+	assert.Zero(t, q.Len())
+	require.Len(t, m.Metrics(), 10)
+	assert.Equal(t, first5, m.Metrics()[:5])
+	var written []string
+	for _, metric := range m.Metrics()[5:] {
+		written = append(written, metric.String())
+	}
+	var expected []string
+	for _, metric := range next5 {
+		expected = append(expected, metric.String())
+	}
+	assert.Equal(t, expected, written)
+}
+
+// Verify that the metrics left in the disk buffer are written by a new
+// output, ie, after a restart.
+func TestRunningOutputDiskBufferReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telegraf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := buffer.OpenDiskQueue(dir, 0)
+	require.NoError(t, err)
+	_, err = q.Push(first5)
+	require.NoError(t, err)
+
+	conf := &OutputConfig{
+		Filter: Filter{},
+	}
+	m := &mockOutput{}
+	ro := NewRunningOutput("test", m, conf, 5, 5)
+	q, err = buffer.OpenDiskQueue(dir, 0)
+	require.NoError(t, err)
+	ro.SetDiskBuffer(q)
+	assert.Equal(t, int64(5), ro.DiskBufferSize.Get())
+
+	for _, metric := range next5 {
+		ro.AddMetric(metric)
+	}
+	require.NoError(t, ro.Write())
+	require.Len(t, m.Metrics(), 10)
+	assert.Equal(t, first5[0].String(), m.Metrics()[0].String())
+	assert.Equal(t, next5, m.Metrics()[5:])
+	assert.Zero(t, q.Len())
+}
+
+func TestRunningOutputRateLimit(t *testing.T) {
+	conf := &OutputConfig{
+		Filter:          Filter{},
+		MetricRateLimit: 10,
+	}
+	ro := NewRunningOutput("test", &mockOutput{}, conf, 5, 100)
+
+	assert.Zero(t, ro.rateLimit(first5))
+	assert.Zero(t, ro.rateLimit(next5))
+	// the bucket holds one second of metrics
+	wait := ro.rateLimit(first5)
+	assert.True(t, wait > 400*time.Millisecond && wait <= 500*time.Millisecond)
+
+	conf = &OutputConfig{
+		Filter:        Filter{},
+		ByteRateLimit: first5[0].Len(),
+	}
+	ro = NewRunningOutput("test", &mockOutput{}, conf, 5, 100)
+	assert.Zero(t, ro.rateLimit(first5[:1]))
+	wait = ro.rateLimit(first5[1:])
+	assert.True(t, wait > 3*time.Second && wait <= 4*time.Second)
+}
+
 type mockOutput struct {
 	sync.Mutex
 
@@ -486,6 +680,8 @@ type mockOutput struct {
 
 	// if true, mock a write failure
 	failWrite bool
+	// if true, mock a write failure of the last metric of each batch
+	failLast bool
 }
 
 func (m *mockOutput) Connect() error {
@@ -510,6 +706,11 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	if m.failWrite {
 		return fmt.Errorf("Failed Write!")
 	}
+	var failed []telegraf.Metric
+	if m.failLast && len(metrics) > 0 {
+		failed = metrics[len(metrics)-1:]
+		metrics = metrics[:len(metrics)-1]
+	}
 
 	if m.metrics == nil {
 		m.metrics = []telegraf.Metric{}
@@ -518,6 +719,9 @@ func (m *mockOutput) Write(metrics []telegraf.Metric) error {
 	for _, metric := range metrics {
 		m.metrics = append(m.metrics, metric)
 	}
+	if len(failed) > 0 {
+		return &telegraf.PartialWriteError{Err: fmt.Errorf("Failed Write!"), Failed: failed}
+	}
 	return nil
 }
 
@@ -554,3 +758,14 @@ func (m *perfOutput) Write(metrics []telegraf.Metric) error {
 	}
 	return nil
 }
+
+func TestRunningOutputAlias(t *testing.T) {
+	conf := &OutputConfig{
+		Name:  "influxdb",
+		Alias: "primary",
+	}
+	ro := NewRunningOutput("influxdb", &mockOutput{}, conf, 1000, 10000)
+	assert.Equal(t, "outputs.influxdb::primary", ro.LogName())
+	assert.Equal(t, map[string]string{"output": "influxdb", "alias": "primary"},
+		ro.WriteErrors.Tags())
+}
@@ -0,0 +1,217 @@
+package rotate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// archiveTimeFormat is the format of the rotation time in the name of the
+// archives, safe in file names.
+const archiveTimeFormat = "2006-01-02T15-04-05"
+
+// FileWriter is a file rotated once it is older than the rotation interval,
+// or once a write would make it larger than the max size. The rotated file is
+// renamed after the rotation time, ie, metrics.2018-06-05T15-04-05.out for
+// metrics.out, and a new file is opened. Only the newest archives are kept.
+type FileWriter struct {
+	filename    string
+	interval    time.Duration
+	maxSize     int64
+	maxArchives int
+
+	current *os.File
+	created time.Time
+	size    int64
+	closed  bool
+
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+
+	sync.Mutex
+}
+
+// NewFileWriter opens the file, creating it if needed.
+//   interval is the maximum age of the file, 0 to never rotate on time.
+//   maxSize is the maximum size of the file in bytes, 0 to never rotate on
+//   size.
+//   maxArchives is the number of rotated files kept, -1 to keep them all.
+func NewFileWriter(
+	filename string,
+	interval time.Duration,
+	maxSize int64,
+	maxArchives int,
+) (*FileWriter, error) {
+	w := &FileWriter{
+		filename:    filename,
+		interval:    interval,
+		maxSize:     maxSize,
+		maxArchives: maxArchives,
+		now:         time.Now,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write writes to the file, rotating it first if needed.
+func (w *FileWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.current == nil {
+		// the file could not be opened again by the last rotation
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.needsRotation(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.current.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (w *FileWriter) Close() error {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.current == nil {
+		return nil
+	}
+	err := w.current.Close()
+	w.current = nil
+	return err
+}
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.current = f
+	// the age of an existing file is the one of its last write
+	w.created = w.now()
+	if stat.Size() > 0 {
+		w.created = stat.ModTime()
+	}
+	w.size = stat.Size()
+	return nil
+}
+
+func (w *FileWriter) needsRotation(n int) bool {
+	if w.interval > 0 && !w.now().Before(w.created.Add(w.interval)) {
+		return true
+	}
+	// a single write larger than the max size is written to an empty file
+	return w.maxSize > 0 && w.size > 0 && w.size+int64(n) > w.maxSize
+}
+
+// rotate renames the current file to an archive, opens a new file and
+// removes the oldest archives. The current file is opened again when it
+// cannot be renamed, and the next write opens the file when this fails.
+func (w *FileWriter) rotate() error {
+	err := w.current.Close()
+	w.current = nil
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(w.filename)
+	base := strings.TrimSuffix(w.filename, ext)
+	archive := fmt.Sprintf("%s.%s%s", base, w.now().Format(archiveTimeFormat), ext)
+	for i := 1; exists(archive); i++ {
+		archive = fmt.Sprintf("%s.%s-%d%s", base, w.now().Format(archiveTimeFormat), i, ext)
+	}
+	if err := os.Rename(w.filename, archive); err != nil {
+		w.open()
+		return err
+	}
+
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.purgeArchives()
+}
+
+// purgeArchives removes the oldest archives beyond maxArchives.
+func (w *FileWriter) purgeArchives() error {
+	if w.maxArchives < 0 {
+		return nil
+	}
+
+	ext := filepath.Ext(w.filename)
+	base := strings.TrimSuffix(w.filename, ext)
+	matches, err := filepath.Glob(escapeGlob(base) + ".*" + escapeGlob(ext))
+	if err != nil {
+		return err
+	}
+
+	type archive struct {
+		name    string
+		modTime time.Time
+	}
+	var archives []archive
+	for _, name := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, base+"."), ext)
+		if i := strings.LastIndex(stamp, "-"); len(stamp) > len(archiveTimeFormat) && i > 0 {
+			stamp = stamp[:i]
+		}
+		if _, err := time.Parse(archiveTimeFormat, stamp); err != nil {
+			continue
+		}
+		stat, err := os.Stat(name)
+		if err != nil {
+			continue
+		}
+		archives = append(archives, archive{name: name, modTime: stat.ModTime()})
+	}
+	if len(archives) <= w.maxArchives {
+		return nil
+	}
+
+	sort.SliceStable(archives, func(i, j int) bool {
+		if archives[i].modTime.Equal(archives[j].modTime) {
+			return archives[i].name < archives[j].name
+		}
+		return archives[i].modTime.Before(archives[j].modTime)
+	})
+	for _, a := range archives[:len(archives)-w.maxArchives] {
+		if err := os.Remove(a.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
+
+// escapeGlob escapes the glob meta characters of a path.
+func escapeGlob(path string) string {
+	r := strings.NewReplacer(`*`, `\*`, `?`, `\?`, `[`, `\[`)
+	return r.Replace(path)
+}
@@ -0,0 +1,192 @@
+package rotate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func files(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFileWriterNoRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationNo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := NewFileWriter(filepath.Join(dir, "test.log"), 0, 0, -1)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("Hello World"))
+	require.NoError(t, err)
+	_, err = w.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"test.log"}, files(t, dir))
+}
+
+func TestFileWriterTimeRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationTime")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 6, 5, 15, 4, 5, 0, time.Local)
+	w, err := NewFileWriter(filepath.Join(dir, "test.log"), time.Minute, 0, -1)
+	require.NoError(t, err)
+	w.now = func() time.Time { return now }
+	w.created = now
+	defer w.Close()
+
+	_, err = w.Write([]byte("Hello World"))
+	require.NoError(t, err)
+	now = now.Add(time.Minute)
+	_, err = w.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"test.2018-06-05T15-05-05.log", "test.log"},
+		files(t, dir))
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "test.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World 2", string(contents))
+}
+
+func TestFileWriterSizeRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationSize")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 6, 5, 15, 4, 5, 0, time.Local)
+	w, err := NewFileWriter(filepath.Join(dir, "test.log"), 0, 15, -1)
+	require.NoError(t, err)
+	w.now = func() time.Time { return now }
+	defer w.Close()
+
+	for _, s := range []string{"Hello World", "Hello World 2", "Hello World 3"} {
+		_, err = w.Write([]byte(s))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{
+		"test.2018-06-05T15-04-05-1.log",
+		"test.2018-06-05T15-04-05.log",
+		"test.log",
+	}, files(t, dir))
+}
+
+func TestFileWriterSizeRotationExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationSizeExisting")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("Hello World"), 0644))
+
+	w, err := NewFileWriter(filename, 0, 15, -1)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+	assert.Len(t, files(t, dir), 2)
+}
+
+func TestFileWriterTimeRotationExistingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationTimeExisting")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	require.NoError(t, ioutil.WriteFile(filename, []byte("Hello World"), 0644))
+	written := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(filename, written, written))
+
+	// the file is as old as its last write
+	w, err := NewFileWriter(filename, time.Hour, 0, -1)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("Hello World 2"))
+	require.NoError(t, err)
+	assert.Len(t, files(t, dir), 2)
+}
+
+func TestFileWriterRotationFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationFailure")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "test.log")
+	w, err := NewFileWriter(filename, 0, 15, -1)
+	require.NoError(t, err)
+	defer w.Close()
+
+	_, err = w.Write([]byte("Hello World"))
+	require.NoError(t, err)
+
+	// the file cannot be renamed once removed
+	require.NoError(t, os.Remove(filename))
+	_, err = w.Write([]byte("Hello World 2"))
+	require.Error(t, err)
+
+	// the writer keeps writing to the file opened again
+	_, err = w.Write([]byte("Hello World 3"))
+	require.NoError(t, err)
+	contents, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello World 3", string(contents))
+}
+
+func TestFileWriterMaxArchives(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationMaxArchives")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 6, 5, 15, 4, 5, 0, time.Local)
+	w, err := NewFileWriter(filepath.Join(dir, "test.log"), time.Second, 0, 2)
+	require.NoError(t, err)
+	w.now = func() time.Time { return now }
+	w.created = now
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		_, err = w.Write([]byte("Hello World"))
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		// the archives are ordered by modification time
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, []string{
+		"test.2018-06-05T15-04-08.log",
+		"test.2018-06-05T15-04-09.log",
+		"test.log",
+	}, files(t, dir))
+}
+
+func TestFileWriterClosed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "RotationClosed")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	w, err := NewFileWriter(filepath.Join(dir, "test.log"), 0, 0, -1)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	_, err = w.Write([]byte("Hello World"))
+	assert.Error(t, err)
+}
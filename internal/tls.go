@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+var (
+	oidPBES2  = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+// encryptedPrivateKeyInfo is the ASN.1 structure of the encrypted PKCS#8
+// private keys, RFC 5208.
+type encryptedPrivateKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Data      []byte
+}
+
+// pbes2Params are the parameters of the PBES2 encryption scheme, RFC 8018.
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params are the parameters of the PBKDF2 key derivation, RFC 8018.
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// LoadX509KeyPair reads a certificate and its private key from PEM files,
+// like tls.LoadX509KeyPair. Encrypted private keys, either legacy encrypted
+// PEM blocks or encrypted PKCS#8 keys, are decrypted with the password.
+func LoadX509KeyPair(certFile, keyFile, password string) (tls.Certificate, error) {
+	certPEM, err := ioutil.ReadFile(certFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	keyPEM, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	block, _ := pem.Decode(keyPEM)
+	if block == nil {
+		return tls.Certificate{}, fmt.Errorf("no PEM block found in %s", keyFile)
+	}
+	encrypted := block.Type == "ENCRYPTED PRIVATE KEY" || x509.IsEncryptedPEMBlock(block)
+	if !encrypted {
+		return tls.X509KeyPair(certPEM, keyPEM)
+	}
+	if password == "" {
+		return tls.Certificate{}, fmt.Errorf("private key %s is encrypted but no password is set", keyFile)
+	}
+
+	var der []byte
+	var keyType string
+	if block.Type == "ENCRYPTED PRIVATE KEY" {
+		der, err = decryptPKCS8(block.Bytes, []byte(password))
+		keyType = "PRIVATE KEY"
+	} else {
+		der, err = x509.DecryptPEMBlock(block, []byte(password))
+		keyType = block.Type
+	}
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not decrypt private key %s: %s", keyFile, err)
+	}
+	return tls.X509KeyPair(certPEM, pem.EncodeToMemory(&pem.Block{Type: keyType, Bytes: der}))
+}
+
+// LoadPKCS12 reads a certificate, its private key and its chain from a
+// PKCS#12 bundle, ie, a .p12 or .pfx keystore, decrypted with the password.
+func LoadPKCS12(file, password string) (tls.Certificate, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	blocks, err := pkcs12.ToPEM(data, password)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("could not decode PKCS#12 bundle %s: %s", file, err)
+	}
+
+	var keyPEM []byte
+	var certs [][]byte
+	for _, block := range blocks {
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, pem.EncodeToMemory(block))
+		} else {
+			keyPEM = pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: block.Bytes})
+		}
+	}
+	if keyPEM == nil || len(certs) == 0 {
+		return tls.Certificate{}, fmt.Errorf("PKCS#12 bundle %s has no private key or certificate", file)
+	}
+
+	// the bundle may hold the chain of the certificate in any order, the
+	// certificate of the private key is the first one of the tls.Certificate
+	for i, leaf := range certs {
+		chain := [][]byte{leaf}
+		chain = append(chain, certs[:i]...)
+		chain = append(chain, certs[i+1:]...)
+		if cert, err := tls.X509KeyPair(bytes.Join(chain, nil), keyPEM); err == nil {
+			return cert, nil
+		}
+	}
+	return tls.Certificate{}, fmt.Errorf("PKCS#12 bundle %s has no certificate of its private key", file)
+}
+
+// decryptPKCS8 decrypts an encrypted PKCS#8 private key using the PBES2
+// scheme with the PBKDF2 key derivation, and returns the DER encoded
+// PKCS#8 private key.
+func decryptPKCS8(data, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPBES2) {
+		return nil, fmt.Errorf("unsupported encryption scheme %s, only PBES2 is supported",
+			info.Algorithm.Algorithm)
+	}
+
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &params); err != nil {
+		return nil, err
+	}
+	if !params.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, fmt.Errorf("unsupported key derivation function %s, only PBKDF2 is supported",
+			params.KeyDerivationFunc.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, err
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = sha1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported pseudorandom function %s", kdf.PRF.Algorithm)
+	}
+
+	var keyLength int
+	var newCipher func([]byte) (cipher.Block, error)
+	scheme := params.EncryptionScheme.Algorithm
+	switch {
+	case scheme.Equal(oidAES128CBC):
+		keyLength, newCipher = 16, aes.NewCipher
+	case scheme.Equal(oidAES192CBC):
+		keyLength, newCipher = 24, aes.NewCipher
+	case scheme.Equal(oidAES256CBC):
+		keyLength, newCipher = 32, aes.NewCipher
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLength, newCipher = 24, des.NewTripleDESCipher
+	default:
+		return nil, fmt.Errorf("unsupported cipher %s", scheme)
+	}
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+
+	block, err := newCipher(pbkdf2(password, kdf.Salt, kdf.IterationCount, keyLength, prf))
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != block.BlockSize() || len(info.Data) == 0 || len(info.Data)%block.BlockSize() != 0 {
+		return nil, errors.New("invalid encrypted data")
+	}
+	decrypted := make([]byte, len(info.Data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(decrypted, info.Data)
+
+	// a wrong password is most likely reported as an invalid padding
+	padding := int(decrypted[len(decrypted)-1])
+	if padding == 0 || padding > block.BlockSize() ||
+		!bytes.Equal(decrypted[len(decrypted)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("invalid password")
+	}
+	return decrypted[:len(decrypted)-padding], nil
+}
+
+// pbkdf2 derives a key from the password, RFC 8018 section 5.2.
+func pbkdf2(password, salt []byte, iterations, keyLength int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	hashLength := prf.Size()
+	blocks := (keyLength + hashLength - 1) / hashLength
+
+	key := make([]byte, 0, blocks*hashLength)
+	u := make([]byte, hashLength)
+	for i := 1; i <= blocks; i++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write([]byte{byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+		u = prf.Sum(u[:0])
+		t := make([]byte, hashLength)
+		copy(t, u)
+		for n := 1; n < iterations; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLength]
+}
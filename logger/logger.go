@@ -12,6 +12,10 @@ import (
 
 var prefixRegex = regexp.MustCompile("^[DIWE]!")
 
+// unfiltered logs the lines of the plugins with their own log level, which
+// are filtered by the plugin loggers rather than by the level of the agent.
+var unfiltered = log.New(&telegrafLog{writer: os.Stderr}, "", 0)
+
 // newTelegrafWriter returns a logging-wrapped writer.
 func newTelegrafWriter(w io.Writer) io.Writer {
 	return &telegrafLog{
@@ -33,6 +37,12 @@ func (t *telegrafLog) Write(b []byte) (n int, err error) {
 	return t.writer.Write(line)
 }
 
+// SetLevel changes the log level at runtime, level is one of debug, info,
+// warn or error.
+func SetLevel(level string) error {
+	return wlog.SetLevelFromName(level)
+}
+
 // SetupLogging configures the logging output.
 //   debug   will set the log level to DEBUG
 //   quiet   will set the log level to ERROR
@@ -66,4 +76,5 @@ func SetupLogging(debug, quiet bool, logfile string) {
 	}
 
 	log.SetOutput(newTelegrafWriter(oFile))
+	unfiltered.SetOutput(&telegrafLog{writer: oFile})
 }
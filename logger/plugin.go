@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/wlog"
+)
+
+// pluginLogger is the telegraf.Logger of a plugin instance.
+type pluginLogger struct {
+	prefix string
+	// level of the instance, zero to use the level of the agent
+	level wlog.Level
+}
+
+// NewPluginLogger returns the logger of the named plugin instance, ie,
+// "inputs.http::app1". With an empty level the lines are filtered on the
+// level of the agent, otherwise on the given level, one of debug, info, warn
+// or error, whatever the level of the agent.
+func NewPluginLogger(name, level string) (telegraf.Logger, error) {
+	l := &pluginLogger{prefix: "[" + name + "] "}
+	if level != "" {
+		var ok bool
+		if l.level, ok = wlog.StringToLevel[strings.ToUpper(level)]; !ok {
+			return nil, fmt.Errorf("unknown log level %q", level)
+		}
+	}
+	return l, nil
+}
+
+func (l *pluginLogger) print(level wlog.Level, msg string) {
+	line := string(wlog.ReverseLevels[level]) + "! " + l.prefix + msg
+	if l.level == 0 {
+		log.Print(line)
+	} else if level >= l.level {
+		unfiltered.Print(line)
+	}
+}
+
+func (l *pluginLogger) Errorf(format string, args ...interface{}) {
+	l.print(wlog.ERROR, fmt.Sprintf(format, args...))
+}
+
+func (l *pluginLogger) Error(args ...interface{}) {
+	l.print(wlog.ERROR, fmt.Sprint(args...))
+}
+
+func (l *pluginLogger) Warnf(format string, args ...interface{}) {
+	l.print(wlog.WARN, fmt.Sprintf(format, args...))
+}
+
+func (l *pluginLogger) Warn(args ...interface{}) {
+	l.print(wlog.WARN, fmt.Sprint(args...))
+}
+
+func (l *pluginLogger) Infof(format string, args ...interface{}) {
+	l.print(wlog.INFO, fmt.Sprintf(format, args...))
+}
+
+func (l *pluginLogger) Info(args ...interface{}) {
+	l.print(wlog.INFO, fmt.Sprint(args...))
+}
+
+func (l *pluginLogger) Debugf(format string, args ...interface{}) {
+	l.print(wlog.DEBUG, fmt.Sprintf(format, args...))
+}
+
+func (l *pluginLogger) Debug(args ...interface{}) {
+	l.print(wlog.DEBUG, fmt.Sprint(args...))
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/wlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLines returns the log lines written to the file, without timestamps.
+func logLines(t *testing.T, path string) []string {
+	f, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(f)), "\n") {
+		lines = append(lines, line[21:])
+	}
+	return lines
+}
+
+func TestPluginLoggerAgentLevel(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	SetupLogging(false, false, tmpfile.Name())
+	wlog.SetLevel(wlog.INFO)
+	l, err := NewPluginLogger("inputs.http::app1", "")
+	require.NoError(t, err)
+	l.Errorf("request failed: %s", "timeout")
+	l.Debug("ignored")
+	l.Info("gathered")
+
+	assert.Equal(t, []string{
+		"E! [inputs.http::app1] request failed: timeout",
+		"I! [inputs.http::app1] gathered",
+	}, logLines(t, tmpfile.Name()))
+}
+
+func TestPluginLoggerOwnLevel(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "")
+	require.NoError(t, err)
+	defer os.Remove(tmpfile.Name())
+
+	SetupLogging(false, true, tmpfile.Name())
+	defer wlog.SetLevel(wlog.INFO)
+	debug, err := NewPluginLogger("inputs.http", "debug")
+	require.NoError(t, err)
+	warn, err := NewPluginLogger("outputs.file", "warn")
+	require.NoError(t, err)
+	debug.Debugf("GET %s", "http://localhost")
+	warn.Info("ignored")
+	warn.Warn("slow write")
+
+	assert.Equal(t, []string{
+		"D! [inputs.http] GET http://localhost",
+		"W! [outputs.file] slow write",
+	}, logLines(t, tmpfile.Name()))
+}
+
+func TestPluginLoggerUnknownLevel(t *testing.T) {
+	_, err := NewPluginLogger("inputs.http", "verbose")
+	assert.Error(t, err)
+}
@@ -447,17 +447,13 @@ func (m *metric) AddTag(key, value string) {
 }
 
 func (m *metric) HasTag(key string) bool {
-	i := bytes.Index(m.tags, []byte(escape(key, "tagkey")+"="))
-	if i == -1 {
-		return false
-	}
-	return true
+	return indexTag(m.tags, key) != -1
 }
 
 func (m *metric) RemoveTag(key string) {
 	m.hashID = 0
 
-	i := bytes.Index(m.tags, []byte(escape(key, "tagkey")+"="))
+	i := indexTag(m.tags, key)
 	if i == -1 {
 		return
 	}
@@ -471,6 +467,25 @@ func (m *metric) RemoveTag(key string) {
 	return
 }
 
+// indexTag returns the index of the key of the tag in the tags, or -1. Every
+// tag starts with an unescaped comma, so that the keys ending with the key,
+// or the values holding it, don't match.
+func indexTag(tags []byte, key string) int {
+	sep := []byte("," + escape(key, "tagkey") + "=")
+	offset := 0
+	for {
+		i := bytes.Index(tags[offset:], sep)
+		if i == -1 {
+			return -1
+		}
+		i += offset
+		if i == 0 || tags[i-1] != '\\' {
+			return i + 1
+		}
+		offset = i + 1
+	}
+}
+
 func (m *metric) AddField(key string, value interface{}) {
 	m.fields = append(m.fields, ',')
 	m.fields = appendField(m.fields, key, value)
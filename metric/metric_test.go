@@ -102,6 +102,21 @@ func TestNewMetric_Tags(t *testing.T) {
 	assert.Equal(t, "cpu value=1 "+fmt.Sprint(now.UnixNano())+"\n", m.String())
 }
 
+func TestNewMetric_TagKeySuffix(t *testing.T) {
+	m, err := New("cpu",
+		map[string]string{"cpu_host": "a,host=b"},
+		map[string]interface{}{"value": float64(1)},
+		time.Now())
+	assert.NoError(t, err)
+
+	// the keys ending with the key, and the values holding it, don't match
+	assert.False(t, m.HasTag("host"))
+	m.AddTag("host", "c")
+	assert.Equal(t, map[string]string{"cpu_host": "a,host=b", "host": "c"}, m.Tags())
+	m.RemoveTag("host")
+	assert.Equal(t, map[string]string{"cpu_host": "a,host=b"}, m.Tags())
+}
+
 func TestSerialize(t *testing.T) {
 	now := time.Now()
 	tags := map[string]string{
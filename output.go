@@ -13,6 +13,19 @@ type Output interface {
 	Write(metrics []Metric) error
 }
 
+// PartialWriteError is returned by the Write of an output which could only
+// write some of the metrics, so that the metrics it did write are not
+// written again.
+type PartialWriteError struct {
+	Err error
+	// Failed are the metrics which were not written
+	Failed []Metric
+}
+
+func (e *PartialWriteError) Error() string {
+	return e.Err.Error()
+}
+
 type ServiceOutput interface {
 	// Connect to the Output
 	Connect() error
@@ -0,0 +1,32 @@
+package telegraf
+
+// Initializer is an interface that the Inputs, Outputs, Processors and
+// Aggregators may implement to validate their configuration and set up
+// their state once, before they are started.
+type Initializer interface {
+	// Init performs the one time setup of the plugin and returns an error
+	// if the configuration is invalid.
+	Init() error
+}
+
+// Logger is the logger of a plugin instance. It is given to the plugins
+// having a Log field of this type, and prefixes the lines with the name and
+// alias of the instance, filtered on the log_level of the instance if any.
+type Logger interface {
+	// Errorf logs an error message, patterned after log.Printf.
+	Errorf(format string, args ...interface{})
+	// Error logs an error message, patterned after log.Print.
+	Error(args ...interface{})
+	// Warnf logs a warning message, patterned after log.Printf.
+	Warnf(format string, args ...interface{})
+	// Warn logs a warning message, patterned after log.Print.
+	Warn(args ...interface{})
+	// Infof logs an information message, patterned after log.Printf.
+	Infof(format string, args ...interface{})
+	// Info logs an information message, patterned after log.Print.
+	Info(args ...interface{})
+	// Debugf logs a debug message, patterned after log.Printf.
+	Debugf(format string, args ...interface{})
+	// Debug logs a debug message, patterned after log.Print.
+	Debug(args ...interface{})
+}
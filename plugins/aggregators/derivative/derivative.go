@@ -0,0 +1,167 @@
+package derivative
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Derivative struct {
+	Fields       []string `toml:"fields"`
+	Suffix       string   `toml:"suffix"`
+	DropNegative bool     `toml:"drop_negative"`
+
+	fields filter.Filter
+	cache  map[uint64]*series
+}
+
+func NewDerivative() *Derivative {
+	d := &Derivative{Suffix: "_rate"}
+	d.cache = make(map[uint64]*series)
+	return d
+}
+
+// sample holds the values of the fields of a series at a time.
+type sample struct {
+	time   time.Time
+	values map[string]float64
+}
+
+// series holds the first and last samples of a series over the period, the
+// first sample being the last one of the previous period if any.
+type series struct {
+	name    string
+	tags    map[string]string
+	first   *sample
+	last    *sample
+	updated bool
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## The fields whose rate of change per second is computed, all the numeric
+  ## fields if empty.
+  # fields = ["count"]
+
+  ## The suffix of the fields of the rates.
+  # suffix = "_rate"
+
+  ## Drop the negative rates, ie, of the counters reset by a restart of the
+  ## application.
+  # drop_negative = false
+`
+
+func (d *Derivative) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Derivative) Description() string {
+	return "Compute the rate of change per second of fields over each period."
+}
+
+func (d *Derivative) Init() error {
+	var err error
+	d.fields, err = filter.Compile(d.Fields)
+	return err
+}
+
+func (d *Derivative) Add(in telegraf.Metric) {
+	values := make(map[string]float64)
+	for k, v := range in.Fields() {
+		if d.fields != nil && !d.fields.Match(k) {
+			continue
+		}
+		if fv, ok := convert(v); ok {
+			values[k] = fv
+		}
+	}
+	if len(values) == 0 {
+		return
+	}
+
+	id := in.HashID()
+	s, ok := d.cache[id]
+	if !ok {
+		s = &series{
+			name: in.Name(),
+			tags: in.Tags(),
+		}
+		d.cache[id] = s
+	}
+	smp := &sample{time: in.Time(), values: values}
+	if s.first == nil {
+		s.first = smp
+	} else {
+		s.last = smp
+	}
+	s.updated = true
+}
+
+func (d *Derivative) Push(acc telegraf.Accumulator) {
+	for _, s := range d.cache {
+		if s.first == nil || s.last == nil || !s.last.time.After(s.first.time) {
+			continue
+		}
+		elapsed := s.last.time.Sub(s.first.time).Seconds()
+
+		fields := make(map[string]interface{})
+		for k, v := range s.last.values {
+			first, ok := s.first.values[k]
+			if !ok {
+				continue
+			}
+			rate := (v - first) / elapsed
+			if d.DropNegative && rate < 0 {
+				continue
+			}
+			fields[k+d.Suffix] = rate
+		}
+		if len(fields) > 0 {
+			acc.AddFields(s.name, fields, s.tags)
+		}
+	}
+}
+
+// Reset keeps the last sample of each series as the first sample of the
+// next period, so that a series with a single metric per period still has a
+// rate. The series without metrics over the period are forgotten.
+func (d *Derivative) Reset() {
+	for id, s := range d.cache {
+		if !s.updated {
+			delete(d.cache, id)
+			continue
+		}
+		if s.last != nil {
+			s.first = s.last
+			s.last = nil
+		}
+		s.updated = false
+	}
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("derivative", func() telegraf.Aggregator {
+		return NewDerivative()
+	})
+}
@@ -0,0 +1,102 @@
+package derivative
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(100), "mean": 12.5, "unit": "ms"},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(150), "mean": 12.5, "unit": "ms"},
+	time.Unix(10, 0),
+)
+var m3, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(400), "mean": 12.5, "unit": "ms"},
+	time.Unix(30, 0),
+)
+var m4, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(160), "mean": 12.5, "unit": "ms"},
+	time.Unix(30, 0),
+)
+var m5, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(220), "mean": 12.5, "unit": "ms"},
+	time.Unix(90, 0),
+)
+var m6, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"count": int64(10), "mean": 12.5, "unit": "ms"},
+	time.Unix(10, 0),
+)
+
+func newDerivative(t *testing.T, fields ...string) *Derivative {
+	d := NewDerivative()
+	d.Fields = fields
+	require.NoError(t, d.Init())
+	return d
+}
+
+func TestRateWithinPeriod(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := newDerivative(t, "count")
+
+	d.Add(m1)
+	d.Add(m2)
+	d.Add(m3)
+	d.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"count_rate": float64(10)}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]string{"app": "shop"}, acc.Metrics[0].Tags)
+}
+
+func TestRateAcrossPeriods(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := newDerivative(t)
+
+	// a single metric per period has a rate from the previous period
+	d.Add(m1)
+	d.Push(&acc)
+	d.Reset()
+	assert.Len(t, acc.Metrics, 0)
+
+	d.Add(m4)
+	d.Push(&acc)
+	d.Reset()
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"count_rate": float64(2),
+		"mean_rate":  float64(0),
+	}, acc.Metrics[0].Fields)
+
+	// the series without metrics over a period is forgotten
+	d.Reset()
+	d.Add(m5)
+	acc.ClearMetrics()
+	d.Push(&acc)
+	assert.Len(t, acc.Metrics, 0)
+}
+
+func TestDropNegative(t *testing.T) {
+	acc := testutil.Accumulator{}
+	d := newDerivative(t, "count")
+	d.DropNegative = true
+
+	d.Add(m1)
+	d.Add(m6)
+	d.Push(&acc)
+
+	assert.Len(t, acc.Metrics, 0)
+}
@@ -0,0 +1,58 @@
+package final
+
+import (
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Final struct {
+	cache map[uint64]telegraf.Metric
+}
+
+func NewFinal() *Final {
+	f := &Final{}
+	f.Reset()
+	return f
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "60s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = true
+`
+
+func (f *Final) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *Final) Description() string {
+	return "Keep the last metric of each series of a period."
+}
+
+func (f *Final) Add(in telegraf.Metric) {
+	id := in.HashID()
+	if last, ok := f.cache[id]; ok && in.Time().Before(last.Time()) {
+		return
+	}
+	// the original metric may still be sent to the outputs
+	f.cache[id] = in.Copy()
+}
+
+func (f *Final) Push(acc telegraf.Accumulator) {
+	for _, m := range f.cache {
+		acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
+	}
+}
+
+func (f *Final) Reset() {
+	f.cache = make(map[uint64]telegraf.Metric)
+}
+
+func init() {
+	aggregators.Add("final", func() telegraf.Aggregator {
+		return NewFinal()
+	})
+}
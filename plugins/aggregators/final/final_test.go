@@ -0,0 +1,74 @@
+package final
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("system",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"load1": 1.5},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("system",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"load1": 1.7},
+	time.Unix(10, 0),
+)
+var m3, _ = metric.New("system",
+	map[string]string{"host": "b"},
+	map[string]interface{}{"load1": 0.2},
+	time.Unix(5, 0),
+)
+var m4, _ = metric.New("system",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"load1": 1.1},
+	time.Unix(20, 0),
+)
+var m5, _ = metric.New("system",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"load1": 9.9},
+	time.Unix(15, 0),
+)
+
+func TestLastOfSeries(t *testing.T) {
+	acc := testutil.Accumulator{}
+	final := NewFinal()
+
+	final.Add(m1)
+	final.Add(m2)
+	final.Add(m3)
+	final.Add(m4)
+	// a metric older than the last one of its series is ignored
+	final.Add(m5)
+	final.Push(&acc)
+
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "system",
+		map[string]interface{}{"load1": 1.1},
+		map[string]string{"host": "a"})
+	acc.AssertContainsTaggedFields(t, "system",
+		map[string]interface{}{"load1": 0.2},
+		map[string]string{"host": "b"})
+	for _, m := range acc.Metrics {
+		if m.Tags["host"] == "a" {
+			assert.Equal(t, time.Unix(20, 0), m.Time)
+		}
+	}
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	final := NewFinal()
+
+	final.Add(m1)
+	final.Reset()
+	final.Push(&acc)
+
+	assert.Len(t, acc.Metrics, 0)
+}
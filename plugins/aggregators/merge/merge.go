@@ -0,0 +1,82 @@
+package merge
+
+import (
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Merge struct {
+	cache map[seriesTime]*aggregate
+}
+
+func NewMerge() *Merge {
+	m := &Merge{}
+	m.Reset()
+	return m
+}
+
+// seriesTime identifies the metrics of a series at a timestamp.
+type seriesTime struct {
+	id   uint64
+	time int64
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+	time   time.Time
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = true
+`
+
+func (m *Merge) SampleConfig() string {
+	return sampleConfig
+}
+
+func (m *Merge) Description() string {
+	return "Merge metrics of the same series and timestamp into a metric."
+}
+
+func (m *Merge) Add(in telegraf.Metric) {
+	key := seriesTime{id: in.HashID(), time: in.UnixNano()}
+	a, ok := m.cache[key]
+	if !ok {
+		a = &aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]interface{}),
+			time:   in.Time(),
+		}
+		m.cache[key] = a
+	}
+	// the fields of the later metrics override the earlier ones
+	for k, v := range in.Fields() {
+		a.fields[k] = v
+	}
+}
+
+func (m *Merge) Push(acc telegraf.Accumulator) {
+	for _, a := range m.cache {
+		acc.AddFields(a.name, a.fields, a.tags, a.time)
+	}
+}
+
+func (m *Merge) Reset() {
+	m.cache = make(map[seriesTime]*aggregate)
+}
+
+func init() {
+	aggregators.Add("merge", func() telegraf.Aggregator {
+		return NewMerge()
+	})
+}
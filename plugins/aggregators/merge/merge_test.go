@@ -0,0 +1,82 @@
+package merge
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("cpu",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"idle": 90.5},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("cpu",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"user": 8.0},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("cpu",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"system": int64(1), "user": 9.5},
+	time.Unix(0, 0),
+)
+var m4, _ = metric.New("cpu",
+	map[string]string{"host": "b"},
+	map[string]interface{}{"user": 8.0},
+	time.Unix(0, 0),
+)
+var m5, _ = metric.New("cpu",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"user": 9.5},
+	time.Unix(10, 0),
+)
+
+func TestMerge(t *testing.T) {
+	acc := testutil.Accumulator{}
+	merge := NewMerge()
+
+	merge.Add(m1)
+	merge.Add(m2)
+	merge.Add(m3)
+	merge.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"idle":   90.5,
+		"user":   9.5,
+		"system": int64(1),
+	}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]string{"host": "a"}, acc.Metrics[0].Tags)
+	assert.Equal(t, time.Unix(0, 0), acc.Metrics[0].Time)
+}
+
+func TestMergeSeparatesSeriesAndTimes(t *testing.T) {
+	acc := testutil.Accumulator{}
+	merge := NewMerge()
+
+	merge.Add(m1)
+	merge.Add(m4)
+	merge.Add(m5)
+	merge.Push(&acc)
+
+	require.Len(t, acc.Metrics, 3)
+	acc.AssertContainsTaggedFields(t, "cpu",
+		map[string]interface{}{"user": 8.0},
+		map[string]string{"host": "b"})
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	merge := NewMerge()
+
+	merge.Add(m1)
+	merge.Reset()
+	merge.Push(&acc)
+
+	assert.Len(t, acc.Metrics, 0)
+}
@@ -0,0 +1,48 @@
+package quantile
+
+import (
+	"math"
+	"sort"
+
+	"github.com/caio/go-tdigest"
+)
+
+// digest estimates the quantiles with a t-digest, in a memory bounded by
+// its compression.
+type digest struct {
+	td *tdigest.TDigest
+}
+
+func (d *digest) Add(value float64) {
+	d.td.Add(value)
+}
+
+func (d *digest) Quantile(q float64) float64 {
+	return d.td.Quantile(q)
+}
+
+// exact computes the quantiles from all the samples, interpolating between
+// the closest ranks, as the R-7 method of numpy and spreadsheets.
+type exact struct {
+	samples []float64
+	sorted  bool
+}
+
+func (e *exact) Add(value float64) {
+	e.samples = append(e.samples, value)
+	e.sorted = false
+}
+
+func (e *exact) Quantile(q float64) float64 {
+	if !e.sorted {
+		sort.Float64s(e.samples)
+		e.sorted = true
+	}
+
+	h := float64(len(e.samples)-1) * q
+	i := int(math.Floor(h))
+	if i+1 >= len(e.samples) {
+		return e.samples[len(e.samples)-1]
+	}
+	return e.samples[i] + (h-float64(i))*(e.samples[i+1]-e.samples[i])
+}
@@ -0,0 +1,179 @@
+package quantile
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/caio/go-tdigest"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type Quantile struct {
+	Quantiles   []float64 `toml:"quantiles"`
+	Algorithm   string    `toml:"algorithm"`
+	Compression float64   `toml:"compression"`
+
+	names []string
+	cache map[uint64]aggregate
+}
+
+func NewQuantile() *Quantile {
+	q := &Quantile{
+		Quantiles:   []float64{0.5, 0.75, 0.95, 0.99},
+		Algorithm:   "t-digest",
+		Compression: 100,
+	}
+	q.Reset()
+	return q
+}
+
+type aggregate struct {
+	name   string
+	tags   map[string]string
+	fields map[string]estimator
+}
+
+// estimator estimates the quantiles of the samples of a field.
+type estimator interface {
+	Add(value float64)
+	Quantile(q float64) float64
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = false
+
+  ## The quantiles to compute, between 0 and 1 exclusive. Each quantile of a
+  ## field is added as <field>_p<digits>, ie, p50, p99 or p999.
+  # quantiles = [0.5, 0.75, 0.95, 0.99]
+
+  ## The algorithm computing the quantiles, either "t-digest", estimating
+  ## them in a bounded memory, or "exact", keeping every sample.
+  # algorithm = "t-digest"
+
+  ## The compression of the t-digest, a higher compression is more accurate
+  ## but uses more memory.
+  # compression = 100.0
+`
+
+func (q *Quantile) SampleConfig() string {
+	return sampleConfig
+}
+
+func (q *Quantile) Description() string {
+	return "Compute the quantiles of the samples of the fields."
+}
+
+func (q *Quantile) Init() error {
+	switch q.Algorithm {
+	case "t-digest":
+		if q.Compression < 1 {
+			return fmt.Errorf("compression must be 1 or more, not %v", q.Compression)
+		}
+	case "exact":
+	default:
+		return fmt.Errorf("unknown algorithm %q", q.Algorithm)
+	}
+	if len(q.Quantiles) == 0 {
+		return fmt.Errorf("no quantiles to compute")
+	}
+
+	q.names = make([]string, len(q.Quantiles))
+	for i, quantile := range q.Quantiles {
+		if quantile <= 0 || quantile >= 1 {
+			return fmt.Errorf("quantile %v is not between 0 and 1", quantile)
+		}
+		q.names[i] = quantileName(quantile)
+	}
+	return nil
+}
+
+// quantileName returns the suffix of a quantile, the digits of its fraction
+// padded to a percentile, as in the dropwizard snapshots.
+func quantileName(quantile float64) string {
+	digits := strings.TrimPrefix(strconv.FormatFloat(quantile, 'f', -1, 64), "0.")
+	if len(digits) < 2 {
+		digits += "0"
+	}
+	return "_p" + digits
+}
+
+func (q *Quantile) Add(in telegraf.Metric) {
+	id := in.HashID()
+	a, ok := q.cache[id]
+	if !ok {
+		a = aggregate{
+			name:   in.Name(),
+			tags:   in.Tags(),
+			fields: make(map[string]estimator),
+		}
+		q.cache[id] = a
+	}
+
+	for k, v := range in.Fields() {
+		fv, ok := convert(v)
+		if !ok || math.IsNaN(fv) {
+			continue
+		}
+		e, ok := a.fields[k]
+		if !ok {
+			e = q.newEstimator()
+			a.fields[k] = e
+		}
+		e.Add(fv)
+	}
+}
+
+func (q *Quantile) newEstimator() estimator {
+	if q.Algorithm == "exact" {
+		return &exact{}
+	}
+	// the compression is validated by Init
+	td, _ := tdigest.New(tdigest.Compression(q.Compression))
+	return &digest{td}
+}
+
+func (q *Quantile) Push(acc telegraf.Accumulator) {
+	for _, a := range q.cache {
+		if len(a.fields) == 0 {
+			continue
+		}
+		fields := make(map[string]interface{}, len(a.fields)*len(q.Quantiles))
+		for k, e := range a.fields {
+			for i, quantile := range q.Quantiles {
+				fields[k+q.names[i]] = e.Quantile(quantile)
+			}
+		}
+		acc.AddFields(a.name, fields, a.tags)
+	}
+}
+
+func (q *Quantile) Reset() {
+	q.cache = make(map[uint64]aggregate)
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("quantile", func() telegraf.Aggregator {
+		return NewQuantile()
+	})
+}
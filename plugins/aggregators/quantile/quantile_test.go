@@ -0,0 +1,111 @@
+package quantile
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("requests",
+	map[string]string{"endpoint": "/orders"},
+	map[string]interface{}{"latency": float64(10), "status": "ok"},
+	time.Now(),
+)
+var m2, _ = metric.New("requests",
+	map[string]string{"endpoint": "/orders"},
+	map[string]interface{}{"latency": float64(20), "status": "ok"},
+	time.Now(),
+)
+var m3, _ = metric.New("requests",
+	map[string]string{"endpoint": "/orders"},
+	map[string]interface{}{"latency": float64(30), "status": "ok"},
+	time.Now(),
+)
+var m4, _ = metric.New("requests",
+	map[string]string{"endpoint": "/orders"},
+	map[string]interface{}{"latency": float64(40), "status": "ok"},
+	time.Now(),
+)
+
+func newQuantile(t *testing.T, algorithm string, quantiles ...float64) *Quantile {
+	q := NewQuantile()
+	q.Algorithm = algorithm
+	q.Quantiles = quantiles
+	require.NoError(t, q.Init())
+	return q
+}
+
+func TestExact(t *testing.T) {
+	acc := testutil.Accumulator{}
+	q := newQuantile(t, "exact", 0.5, 0.75, 0.999)
+
+	q.Add(m4)
+	q.Add(m1)
+	q.Add(m3)
+	q.Add(m2)
+	q.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"latency_p50":  float64(25),
+		"latency_p75":  float64(32.5),
+		"latency_p999": float64(39.97),
+	}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]string{"endpoint": "/orders"}, acc.Metrics[0].Tags)
+}
+
+func TestTDigest(t *testing.T) {
+	acc := testutil.Accumulator{}
+	q := newQuantile(t, "t-digest", 0.5, 0.99)
+
+	for i := 1; i <= 1000; i++ {
+		m, err := metric.New("requests",
+			map[string]string{"endpoint": "/orders"},
+			map[string]interface{}{"latency": float64(i)},
+			time.Now())
+		require.NoError(t, err)
+		q.Add(m)
+	}
+	q.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.InDelta(t, 500, acc.Metrics[0].Fields["latency_p50"], 10)
+	assert.InDelta(t, 990, acc.Metrics[0].Fields["latency_p99"], 5)
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	q := newQuantile(t, "exact", 0.5)
+
+	q.Add(m1)
+	q.Reset()
+	q.Add(m2)
+	q.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"latency_p50": float64(20)}, acc.Metrics[0].Fields)
+}
+
+func TestDefaultQuantiles(t *testing.T) {
+	q := NewQuantile()
+	require.NoError(t, q.Init())
+	assert.Equal(t, []string{"_p50", "_p75", "_p95", "_p99"}, q.names)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	q := NewQuantile()
+	q.Algorithm = "median"
+	assert.EqualError(t, q.Init(), `unknown algorithm "median"`)
+
+	q = NewQuantile()
+	q.Quantiles = []float64{1}
+	assert.EqualError(t, q.Init(), "quantile 1 is not between 0 and 1")
+
+	q = NewQuantile()
+	q.Compression = 0
+	assert.EqualError(t, q.Init(), "compression must be 1 or more, not 0")
+}
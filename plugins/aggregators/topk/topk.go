@@ -0,0 +1,192 @@
+package topk
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/aggregators"
+)
+
+type TopK struct {
+	K           int      `toml:"k"`
+	Field       string   `toml:"field"`
+	Aggregation string   `toml:"aggregation"`
+	GroupBy     []string `toml:"group_by"`
+
+	cache map[uint64]*series
+}
+
+func NewTopK() *TopK {
+	t := &TopK{
+		K:           10,
+		Aggregation: "mean",
+	}
+	t.Reset()
+	return t
+}
+
+// series holds the last fields of a series, and the aggregates of the
+// ranked field over the period.
+type series struct {
+	id     uint64
+	name   string
+	tags   map[string]string
+	fields map[string]interface{}
+
+	count float64
+	sum   float64
+	min   float64
+	max   float64
+	last  float64
+}
+
+var sampleConfig = `
+  ## General Aggregator Arguments:
+  ## The period on which to flush & clear the aggregator.
+  period = "30s"
+  ## If true, the original metric will be dropped by the
+  ## aggregator and will not get sent to the output plugins.
+  drop_original = true
+
+  ## The number of series to keep per group.
+  k = 10
+
+  ## The field ranking the series, the series without the field are
+  ## ignored.
+  field = "p99"
+
+  ## The aggregation of the field over the period ranking the series, one of
+  ## mean, sum, min, max or last.
+  # aggregation = "mean"
+
+  ## The tags grouping the series, the top k series are kept per
+  ## measurement and values of these tags.
+  # group_by = []
+`
+
+func (t *TopK) SampleConfig() string {
+	return sampleConfig
+}
+
+func (t *TopK) Description() string {
+	return "Keep the top k series of each period by a field."
+}
+
+func (t *TopK) Init() error {
+	if t.K < 1 {
+		return fmt.Errorf("k must be positive, not %d", t.K)
+	}
+	if t.Field == "" {
+		return fmt.Errorf("no field ranking the series")
+	}
+	switch t.Aggregation {
+	case "mean", "sum", "min", "max", "last":
+	default:
+		return fmt.Errorf("unknown aggregation %q", t.Aggregation)
+	}
+	return nil
+}
+
+func (t *TopK) Add(in telegraf.Metric) {
+	fv, ok := convert(in.Fields()[t.Field])
+	if !ok {
+		return
+	}
+
+	id := in.HashID()
+	s, ok := t.cache[id]
+	if !ok {
+		s = &series{
+			id:   id,
+			name: in.Name(),
+			tags: in.Tags(),
+			min:  fv,
+			max:  fv,
+		}
+		t.cache[id] = s
+	}
+	s.fields = in.Fields()
+	s.count++
+	s.sum += fv
+	s.last = fv
+	if fv < s.min {
+		s.min = fv
+	}
+	if fv > s.max {
+		s.max = fv
+	}
+}
+
+func (t *TopK) Push(acc telegraf.Accumulator) {
+	groups := make(map[string][]*series)
+	for _, s := range t.cache {
+		key := t.groupKey(s)
+		groups[key] = append(groups[key], s)
+	}
+
+	for _, group := range groups {
+		// the ties are ordered by series, for the same series to be kept
+		// from a period to another
+		sort.Slice(group, func(i, j int) bool {
+			vi, vj := t.value(group[i]), t.value(group[j])
+			if vi != vj {
+				return vi > vj
+			}
+			return group[i].id < group[j].id
+		})
+		if len(group) > t.K {
+			group = group[:t.K]
+		}
+		for _, s := range group {
+			acc.AddFields(s.name, s.fields, s.tags)
+		}
+	}
+}
+
+func (t *TopK) Reset() {
+	t.cache = make(map[uint64]*series)
+}
+
+func (t *TopK) groupKey(s *series) string {
+	parts := []string{s.name}
+	for _, tag := range t.GroupBy {
+		parts = append(parts, tag+"="+s.tags[tag])
+	}
+	return strings.Join(parts, ",")
+}
+
+// value returns the aggregation of the ranked field of the series.
+func (t *TopK) value(s *series) float64 {
+	switch t.Aggregation {
+	case "sum":
+		return s.sum
+	case "min":
+		return s.min
+	case "max":
+		return s.max
+	case "last":
+		return s.last
+	}
+	return s.sum / s.count
+}
+
+func convert(in interface{}) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func init() {
+	aggregators.Add("topk", func() telegraf.Aggregator {
+		return NewTopK()
+	})
+}
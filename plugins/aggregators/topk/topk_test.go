@@ -0,0 +1,141 @@
+package topk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var getA10, _ = metric.New("requests",
+	map[string]string{"endpoint": "/a", "method": "GET"},
+	map[string]interface{}{"p99": float64(10), "count": int64(1)},
+	time.Now(),
+)
+var getA50, _ = metric.New("requests",
+	map[string]string{"endpoint": "/a", "method": "GET"},
+	map[string]interface{}{"p99": float64(50), "count": int64(1)},
+	time.Now(),
+)
+var getB40, _ = metric.New("requests",
+	map[string]string{"endpoint": "/b", "method": "GET"},
+	map[string]interface{}{"p99": float64(40), "count": int64(1)},
+	time.Now(),
+)
+var getC20, _ = metric.New("requests",
+	map[string]string{"endpoint": "/c", "method": "GET"},
+	map[string]interface{}{"p99": float64(20), "count": int64(1)},
+	time.Now(),
+)
+var getD5, _ = metric.New("requests",
+	map[string]string{"endpoint": "/d", "method": "GET"},
+	map[string]interface{}{"p99": float64(5), "count": int64(1)},
+	time.Now(),
+)
+var postA30, _ = metric.New("requests",
+	map[string]string{"endpoint": "/a", "method": "POST"},
+	map[string]interface{}{"p99": float64(30), "count": int64(1)},
+	time.Now(),
+)
+var postB20, _ = metric.New("requests",
+	map[string]string{"endpoint": "/b", "method": "POST"},
+	map[string]interface{}{"p99": float64(20), "count": int64(1)},
+	time.Now(),
+)
+var noP99, _ = metric.New("requests",
+	nil,
+	map[string]interface{}{"count": int64(1)},
+	time.Now(),
+)
+
+func newTopK(t *testing.T, k int, aggregation string, groupBy ...string) *TopK {
+	topk := NewTopK()
+	topk.K = k
+	topk.Field = "p99"
+	topk.Aggregation = aggregation
+	topk.GroupBy = groupBy
+	require.NoError(t, topk.Init())
+	return topk
+}
+
+func TestTopKMean(t *testing.T) {
+	acc := testutil.Accumulator{}
+	topk := newTopK(t, 2, "mean")
+
+	topk.Add(getA10)
+	topk.Add(getA50)
+	topk.Add(getB40)
+	topk.Add(getC20)
+	topk.Add(getD5)
+	topk.Push(&acc)
+
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"p99": float64(50), "count": int64(1)},
+		map[string]string{"endpoint": "/a", "method": "GET"})
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"p99": float64(40), "count": int64(1)},
+		map[string]string{"endpoint": "/b", "method": "GET"})
+}
+
+func TestTopKMax(t *testing.T) {
+	acc := testutil.Accumulator{}
+	topk := newTopK(t, 1, "max")
+
+	topk.Add(getA10)
+	topk.Add(getA50)
+	topk.Add(getB40)
+	topk.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, "/a", acc.Metrics[0].Tags["endpoint"])
+}
+
+func TestTopKGroupBy(t *testing.T) {
+	acc := testutil.Accumulator{}
+	topk := newTopK(t, 1, "last", "method")
+
+	topk.Add(getA10)
+	topk.Add(getB40)
+	topk.Add(postA30)
+	topk.Add(postB20)
+	topk.Push(&acc)
+
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"p99": float64(40), "count": int64(1)},
+		map[string]string{"endpoint": "/b", "method": "GET"})
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{"p99": float64(30), "count": int64(1)},
+		map[string]string{"endpoint": "/a", "method": "POST"})
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	topk := newTopK(t, 2, "mean")
+
+	topk.Add(getA10)
+	topk.Add(noP99)
+	topk.Reset()
+	topk.Push(&acc)
+
+	assert.Len(t, acc.Metrics, 0)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	topk := NewTopK()
+	assert.EqualError(t, topk.Init(), "no field ranking the series")
+
+	topk = NewTopK()
+	topk.Field = "p99"
+	topk.Aggregation = "median"
+	assert.EqualError(t, topk.Init(), `unknown aggregation "median"`)
+
+	topk = NewTopK()
+	topk.Field = "p99"
+	topk.K = 0
+	assert.EqualError(t, topk.Init(), "k must be positive, not 0")
+}
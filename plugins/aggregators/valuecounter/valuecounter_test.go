@@ -0,0 +1,94 @@
+package valuecounter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(200), "cached": true, "latency": 12.5},
+	time.Now(),
+)
+var m2, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(200), "cached": false},
+	time.Now(),
+)
+var m3, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(404)},
+	time.Now(),
+)
+var m4, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": "error"},
+	time.Now(),
+)
+var m5, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"latency": 12.5},
+	time.Now(),
+)
+var m6, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(200)},
+	time.Now(),
+)
+var m7, _ = metric.New("http_requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(500)},
+	time.Now(),
+)
+
+func TestCountValues(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"status", "cached"}
+
+	vc.Add(m1)
+	vc.Add(m2)
+	vc.Add(m3)
+	vc.Add(m4)
+	vc.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"status_200":   int64(2),
+		"status_404":   int64(1),
+		"status_error": int64(1),
+		"cached_true":  int64(1),
+		"cached_false": int64(1),
+	}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]string{"app": "shop"}, acc.Metrics[0].Tags)
+}
+
+func TestNoCountedFields(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"status"}
+
+	vc.Add(m5)
+	vc.Push(&acc)
+
+	assert.Len(t, acc.Metrics, 0)
+}
+
+func TestReset(t *testing.T) {
+	acc := testutil.Accumulator{}
+	vc := NewValueCounter()
+	vc.Fields = []string{"status"}
+
+	vc.Add(m6)
+	vc.Reset()
+	vc.Add(m7)
+	vc.Push(&acc)
+
+	require.Len(t, acc.Metrics, 1)
+	assert.Equal(t, map[string]interface{}{"status_500": int64(1)}, acc.Metrics[0].Fields)
+}
@@ -182,7 +182,7 @@ func (e *Exec) ProcessCommand(command string, acc telegraf.Accumulator, wg *sync
 
 	metrics, err := e.parser.Parse(out)
 	if err != nil {
-		acc.AddError(err)
+		acc.AddError(fmt.Errorf("exec: %s for command '%s'", err, command))
 	} else {
 		for _, metric := range metrics {
 			acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
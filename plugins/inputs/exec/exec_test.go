@@ -50,6 +50,24 @@ cpu,cpu=cpu5,host=foo,datacenter=us-east usage_idle=99,usage_busy=1
 cpu,cpu=cpu6,host=foo,datacenter=us-east usage_idle=99,usage_busy=1
 `
 
+const dropwizardJSON = `
+{
+	"version": "3.0.0",
+	"counters": {
+		"jobs.queued": {
+			"count": 12
+		}
+	},
+	"meters": {},
+	"gauges": {
+		"jobs.workers": {
+			"value": 4
+		}
+	},
+	"histograms": {},
+	"timers": {}
+}`
+
 type CarriageReturnTest struct {
 	input  []byte
 	output []byte
@@ -144,6 +162,39 @@ func TestCommandError(t *testing.T) {
 	assert.Equal(t, acc.NFields(), 0, "No new points should have been added")
 }
 
+func TestExecParseErrorNamesCommand(t *testing.T) {
+	parser, _ := parsers.NewJSONParser("exec", []string{}, nil)
+	e := &Exec{
+		runner:   newRunnerMock([]byte(malformedJson), nil),
+		Commands: []string{"/usr/bin/mycollector --json"},
+		parser:   parser,
+	}
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(e.Gather)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "for command '/usr/bin/mycollector --json'")
+}
+
+func TestExecDropwizard(t *testing.T) {
+	parser, err := parsers.NewParser(&parsers.Config{DataFormat: "dropwizard"})
+	require.NoError(t, err)
+	e := &Exec{
+		runner:   newRunnerMock([]byte(dropwizardJSON), nil),
+		Commands: []string{"/usr/bin/jobs-metrics"},
+		parser:   parser,
+	}
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(e.Gather))
+	acc.AssertContainsTaggedFields(t, "jobs.queued",
+		map[string]interface{}{"count": float64(12)},
+		map[string]string{"metric_type": "counter"})
+	acc.AssertContainsTaggedFields(t, "jobs.workers",
+		map[string]interface{}{"value": int64(4)},
+		map[string]string{"metric_type": "gauge"})
+}
+
 func TestLineProtocolParse(t *testing.T) {
 	parser, _ := parsers.NewInfluxParser()
 	e := &Exec{
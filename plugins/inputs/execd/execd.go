@@ -0,0 +1,283 @@
+package execd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/logger"
+	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
+)
+
+const sampleConfig = `
+  ## Program to run as daemon, with its arguments
+  command = ["telegraf-smartctl", "-d", "/dev/sda"]
+
+  ## Define how the process is signaled on each collection interval.
+  ## Valid values are:
+  ##   "none"    : Do not signal anything.
+  ##               The process must output metrics by itself.
+  ##   "STDIN"   : Send a newline on STDIN.
+  ##   "SIGHUP"  : Send a HUP signal. Not available on Windows.
+  ##   "SIGUSR1" : Send a USR1 signal. Not available on Windows.
+  ##   "SIGUSR2" : Send a USR2 signal. Not available on Windows.
+  signal = "none"
+
+  ## Delay before the process is restarted after an unexpected termination
+  restart_delay = "10s"
+
+  ## Data format to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "influx"
+`
+
+// maxLineSize is the maximum size of a line read from the process.
+const maxLineSize = 1024 * 1024
+
+type Execd struct {
+	Command      []string          `toml:"command"`
+	Signal       string            `toml:"signal"`
+	RestartDelay internal.Duration `toml:"restart_delay"`
+
+	Log telegraf.Logger `toml:"-"`
+
+	acc    telegraf.Accumulator
+	parser parsers.Parser
+
+	// mu guards the running process and its stdin.
+	mu    sync.Mutex
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func (e *Execd) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Execd) Description() string {
+	return "Run executable as long-running input plugin"
+}
+
+func (e *Execd) SetParser(parser parsers.Parser) {
+	e.parser = parser
+}
+
+// Start starts the process, and restarts it whenever it exits until the
+// plugin is stopped.
+func (e *Execd) Start(acc telegraf.Accumulator) error {
+	if len(e.Command) == 0 {
+		return fmt.Errorf("execd: no command configured")
+	}
+	switch e.Signal {
+	case "", "none", "STDIN":
+	default:
+		if _, err := signal(e.Signal); err != nil {
+			return err
+		}
+	}
+	if e.Log == nil {
+		e.Log, _ = logger.NewPluginLogger("inputs.execd", "")
+	}
+
+	e.acc = acc
+	e.done = make(chan struct{})
+
+	readers, err := e.cmdStart()
+	if err != nil {
+		return err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.cmdLoop(readers)
+	}()
+	return nil
+}
+
+// Stop kills the process and waits for it to exit.
+func (e *Execd) Stop() {
+	close(e.done)
+
+	e.mu.Lock()
+	if e.cmd != nil && e.cmd.Process != nil {
+		e.stdin.Close()
+		// the process may have exited already
+		if err := e.cmd.Process.Kill(); err != nil {
+			e.Log.Debugf("Error killing process %q: %s", e.Command[0], err)
+		}
+	}
+	e.mu.Unlock()
+
+	e.wg.Wait()
+}
+
+// Gather signals the process to output its metrics.
+func (e *Execd) Gather(acc telegraf.Accumulator) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cmd == nil || e.cmd.Process == nil {
+		return nil
+	}
+
+	switch e.Signal {
+	case "", "none":
+		return nil
+	case "STDIN":
+		if _, err := io.WriteString(e.stdin, "\n"); err != nil {
+			return fmt.Errorf("error writing to stdin of %q: %s", e.Command[0], err)
+		}
+		return nil
+	default:
+		sig, err := signal(e.Signal)
+		if err != nil {
+			return err
+		}
+		return e.cmd.Process.Signal(sig)
+	}
+}
+
+// cmdStart starts the process, and the goroutines reading its output,
+// returning their wait group.
+func (e *Execd) cmdStart() (*sync.WaitGroup, error) {
+	cmd := exec.Command(e.Command[0], e.Command[1:]...)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error opening stdin pipe: %s", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error opening stdout pipe: %s", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error opening stderr pipe: %s", err)
+	}
+
+	e.Log.Infof("Starting process: %s", e.Command)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting process %q: %s", e.Command[0], err)
+	}
+
+	e.mu.Lock()
+	e.cmd = cmd
+	e.stdin = stdin
+	select {
+	case <-e.done:
+		// stopped while restarting
+		cmd.Process.Kill()
+	default:
+	}
+	e.mu.Unlock()
+
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go func() {
+		defer readers.Done()
+		e.cmdReadOut(stdout)
+	}()
+	go func() {
+		defer readers.Done()
+		e.cmdReadErr(stderr)
+	}()
+	return &readers, nil
+}
+
+// cmdLoop waits for the process to exit, and restarts it after the restart
+// delay unless the plugin is stopped.
+func (e *Execd) cmdLoop(readers *sync.WaitGroup) {
+	for {
+		e.mu.Lock()
+		cmd := e.cmd
+		e.mu.Unlock()
+
+		// the output must be read before waiting for the process
+		readers.Wait()
+		err := cmd.Wait()
+		select {
+		case <-e.done:
+			return
+		default:
+		}
+		if err != nil {
+			e.Log.Errorf("Process %q exited: %s", e.Command[0], err)
+		} else {
+			e.Log.Errorf("Process %q exited", e.Command[0])
+		}
+
+		for {
+			e.Log.Infof("Restarting in %s...", e.RestartDelay.Duration)
+			select {
+			case <-e.done:
+				return
+			case <-time.After(e.RestartDelay.Duration):
+			}
+			var err error
+			if readers, err = e.cmdStart(); err != nil {
+				e.Log.Errorf("%s", err)
+				continue
+			}
+			break
+		}
+	}
+}
+
+// cmdReadOut parses each line output by the process.
+func (e *Execd) cmdReadOut(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		metrics, err := e.parser.Parse(scanner.Bytes())
+		if err != nil {
+			e.acc.AddError(fmt.Errorf("parse error: %s", err))
+			continue
+		}
+		for _, metric := range metrics {
+			e.acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		e.acc.AddError(fmt.Errorf("error reading stdout: %s", err))
+		// the process would block once the pipe is full, it is killed to
+		// be restarted instead
+		e.mu.Lock()
+		e.cmd.Process.Kill()
+		e.mu.Unlock()
+	}
+}
+
+// cmdReadErr logs each line output by the process on stderr.
+func (e *Execd) cmdReadErr(out io.Reader) {
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		e.Log.Errorf("stderr: %q", scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		e.acc.AddError(fmt.Errorf("error reading stderr: %s", err))
+		// keep the process from blocking on a full pipe
+		io.Copy(ioutil.Discard, out)
+	}
+}
+
+func init() {
+	inputs.Add("execd", func() telegraf.Input {
+		return &Execd{
+			Signal:       "none",
+			RestartDelay: internal.Duration{Duration: 10 * time.Second},
+		}
+	})
+}
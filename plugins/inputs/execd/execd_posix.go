@@ -0,0 +1,23 @@
+// +build !windows
+
+package execd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// signal returns the signal of the given name.
+func signal(name string) (os.Signal, error) {
+	switch name {
+	case "SIGHUP":
+		return syscall.SIGHUP, nil
+	case "SIGUSR1":
+		return syscall.SIGUSR1, nil
+	case "SIGUSR2":
+		return syscall.SIGUSR2, nil
+	default:
+		return nil, fmt.Errorf("invalid signal %q", name)
+	}
+}
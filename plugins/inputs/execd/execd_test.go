@@ -0,0 +1,140 @@
+package execd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/testutil"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMain runs the test binary as the external collector when asked to.
+func TestMain(m *testing.M) {
+	switch os.Getenv("EXECD_TEST_COLLECTOR") {
+	case "stdin":
+		runStdinCollector()
+		os.Exit(0)
+	case "long":
+		// a line over the maximum, followed by more than a pipe full of
+		// output
+		fmt.Println(strings.Repeat("x", maxLineSize+1))
+		for {
+			fmt.Println("counter_execd count=1i")
+		}
+	case "exit":
+		fmt.Println("counter_execd count=1i")
+		fmt.Fprintln(os.Stderr, "exiting")
+		os.Exit(1)
+	}
+	os.Exit(m.Run())
+}
+
+// runStdinCollector outputs a metric for each line read on stdin.
+func runStdinCollector() {
+	scanner := bufio.NewScanner(os.Stdin)
+	count := 0
+	for scanner.Scan() {
+		count++
+		fmt.Printf("counter_execd count=%di\n", count)
+	}
+}
+
+func newExecd(t *testing.T, collector string) *Execd {
+	os.Setenv("EXECD_TEST_COLLECTOR", collector)
+	parser, err := parsers.NewInfluxParser()
+	require.NoError(t, err)
+	e := &Execd{
+		Command:      []string{os.Args[0]},
+		Signal:       "STDIN",
+		RestartDelay: internal.Duration{Duration: 10 * time.Millisecond},
+		Log:          &testutil.Logger{},
+	}
+	e.SetParser(parser)
+	return e
+}
+
+func TestExecdSignalStdin(t *testing.T) {
+	e := newExecd(t, "stdin")
+	defer os.Unsetenv("EXECD_TEST_COLLECTOR")
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	require.NoError(t, e.Gather(acc))
+	acc.Wait(1)
+	require.NoError(t, e.Gather(acc))
+	acc.Wait(2)
+
+	acc.Lock()
+	defer acc.Unlock()
+	require.Len(t, acc.Metrics, 2)
+	assert.Equal(t, "counter_execd", acc.Metrics[0].Measurement)
+	assert.Equal(t, map[string]interface{}{"count": int64(1)}, acc.Metrics[0].Fields)
+	assert.Equal(t, map[string]interface{}{"count": int64(2)}, acc.Metrics[1].Fields)
+}
+
+func TestExecdRestart(t *testing.T) {
+	e := newExecd(t, "exit")
+	defer os.Unsetenv("EXECD_TEST_COLLECTOR")
+	log := e.Log.(*testutil.Logger)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+
+	// the process is restarted after exiting
+	acc.Wait(2)
+	e.Stop()
+
+	log.Lock()
+	defer log.Unlock()
+	assert.Contains(t, log.Lines, `E! stderr: "exiting"`)
+}
+
+func TestExecdLongLine(t *testing.T) {
+	e := newExecd(t, "long")
+	defer os.Unsetenv("EXECD_TEST_COLLECTOR")
+	log := e.Log.(*testutil.Logger)
+
+	acc := &testutil.Accumulator{}
+	require.NoError(t, e.Start(acc))
+	defer e.Stop()
+
+	// the process is killed and restarted rather than left blocked
+	starts := func() int {
+		log.Lock()
+		defer log.Unlock()
+		n := 0
+		for _, line := range log.Lines {
+			if strings.HasPrefix(line, "I! Starting process") {
+				n++
+			}
+		}
+		return n
+	}
+	for deadline := time.Now().Add(5 * time.Second); starts() < 2; {
+		require.True(t, time.Now().Before(deadline), "process not restarted")
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	acc.Lock()
+	defer acc.Unlock()
+	require.NotEmpty(t, acc.Errors)
+	assert.Contains(t, acc.Errors[0].Error(), "token too long")
+}
+
+func TestExecdInvalid(t *testing.T) {
+	e := &Execd{}
+	assert.Error(t, e.Start(&testutil.Accumulator{}))
+
+	e = &Execd{Command: []string{"collector"}, Signal: "SIGKILL"}
+	assert.Error(t, e.Start(&testutil.Accumulator{}))
+}
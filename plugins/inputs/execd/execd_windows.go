@@ -0,0 +1,13 @@
+// +build windows
+
+package execd
+
+import (
+	"fmt"
+	"os"
+)
+
+// signal returns an error, only the STDIN signal is available on Windows.
+func signal(name string) (os.Signal, error) {
+	return nil, fmt.Errorf("invalid signal %q, only STDIN is available on Windows", name)
+}
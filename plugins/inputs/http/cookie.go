@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// login sends the login request if there is no session yet or if it must be
+// renewed, the session cookie is kept by the cookie jar of the client.
+func (h *HTTP) login() error {
+	h.sessionMu.Lock()
+	defer h.sessionMu.Unlock()
+
+	if !h.sessionStart.IsZero() &&
+		(h.CookieAuthRenewal.Duration == 0 || time.Since(h.sessionStart) < h.CookieAuthRenewal.Duration) {
+		return nil
+	}
+
+	method := h.CookieAuthMethod
+	if method == "" {
+		method = "POST"
+	}
+	var body io.Reader
+	if h.CookieAuthBody != "" {
+		body = strings.NewReader(h.CookieAuthBody)
+	}
+	request, err := http.NewRequest(method, h.CookieAuthURL, body)
+	if err != nil {
+		return err
+	}
+	if h.Timeout.Duration > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), h.Timeout.Duration)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
+	if body != nil {
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for k, v := range h.CookieAuthHeaders {
+		if strings.ToLower(k) == "host" {
+			request.Host = v
+		} else {
+			request.Header.Set(k, v)
+		}
+	}
+
+	resp, err := h.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("login to %s failed: %s", h.CookieAuthURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("login to %s failed: received status code %d (%s): %s",
+			h.CookieAuthURL,
+			resp.StatusCode,
+			http.StatusText(resp.StatusCode),
+			bodyExcerpt(resp.Body))
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+
+	h.sessionStart = time.Now()
+	return nil
+}
+
+// logout forgets the session, ie, when it expired on the server, so that
+// the next gather logs in again.
+func (h *HTTP) logout() {
+	h.sessionMu.Lock()
+	h.sessionStart = time.Time{}
+	h.sessionMu.Unlock()
+}
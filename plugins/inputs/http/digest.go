@@ -0,0 +1,263 @@
+package http
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// digestTransport authenticates requests with HTTP digest authentication,
+// as defined in RFC 7616. The challenge of each host is kept to
+// authenticate the following requests without an additional round trip.
+type digestTransport struct {
+	username  string
+	password  string
+	transport http.RoundTripper
+
+	mu         sync.Mutex
+	challenges map[string]*digestChallenge
+}
+
+type digestChallenge struct {
+	realm     string
+	nonce     string
+	opaque    string
+	algorithm string
+	qop       string
+	count     int
+}
+
+func newDigestTransport(username, password string, transport http.RoundTripper) *digestTransport {
+	return &digestTransport{
+		username:   username,
+		password:   password,
+		transport:  transport,
+		challenges: make(map[string]*digestChallenge),
+	}
+}
+
+// RoundTrip implements http.RoundTripper. The body of the request is read
+// again from its start each time the request is resent.
+func (t *digestTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if authorization, ok := t.authorize(req); ok {
+		resp, err := t.transport.RoundTrip(withHeader(req, "Authorization", authorization))
+		if err != nil || resp.StatusCode != http.StatusUnauthorized {
+			return resp, err
+		}
+		// the nonce expired, ask for a new challenge
+		resp.Body.Close()
+		if req, err = rewind(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge, err := parseDigestChallenge(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		// not a digest challenge, return the response as is
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	t.mu.Lock()
+	t.challenges[req.URL.Host] = challenge
+	t.mu.Unlock()
+
+	if req, err = rewind(req); err != nil {
+		return nil, err
+	}
+	authorization, _ := t.authorize(req)
+	return t.transport.RoundTrip(withHeader(req, "Authorization", authorization))
+}
+
+// authorize returns the Authorization header of the request, the returned
+// bool is false if no challenge was received from the host yet.
+func (t *digestTransport) authorize(req *http.Request) (string, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c, ok := t.challenges[req.URL.Host]
+	if !ok {
+		return "", false
+	}
+	c.count++
+
+	var h func() hash.Hash
+	switch strings.ToUpper(strings.TrimSuffix(strings.ToLower(c.algorithm), "-sess")) {
+	case "SHA-256":
+		h = sha256.New
+	default:
+		h = md5.New
+	}
+	digest := func(s string) string {
+		hash := h()
+		hash.Write([]byte(s))
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+
+	cnonce := newCnonce()
+	nc := fmt.Sprintf("%08x", c.count)
+	uri := req.URL.RequestURI()
+
+	ha1 := digest(t.username + ":" + c.realm + ":" + t.password)
+	if strings.HasSuffix(strings.ToLower(c.algorithm), "-sess") {
+		ha1 = digest(ha1 + ":" + c.nonce + ":" + cnonce)
+	}
+	ha2 := digest(req.Method + ":" + uri)
+
+	var response string
+	if c.qop != "" {
+		response = digest(strings.Join([]string{ha1, c.nonce, nc, cnonce, c.qop, ha2}, ":"))
+	} else {
+		response = digest(ha1 + ":" + c.nonce + ":" + ha2)
+	}
+
+	params := []string{
+		fmt.Sprintf(`username="%s"`, t.username),
+		fmt.Sprintf(`realm="%s"`, c.realm),
+		fmt.Sprintf(`nonce="%s"`, c.nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if c.algorithm != "" {
+		params = append(params, "algorithm="+c.algorithm)
+	}
+	if c.opaque != "" {
+		params = append(params, fmt.Sprintf(`opaque="%s"`, c.opaque))
+	}
+	if c.qop != "" {
+		params = append(params, "qop="+c.qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	return "Digest " + strings.Join(params, ", "), true
+}
+
+// parseDigestChallenge parses the WWW-Authenticate header of a digest
+// challenge, ie:
+//   Digest realm="admin", qop="auth,auth-int", nonce="dcd98b", opaque="5ccc"
+func parseDigestChallenge(header string) (*digestChallenge, error) {
+	const prefix = "digest "
+	if len(header) < len(prefix) || strings.ToLower(header[:len(prefix)]) != prefix {
+		return nil, fmt.Errorf("not a digest challenge: %q", header)
+	}
+
+	c := &digestChallenge{}
+	for key, value := range parseAuthParams(header[len(prefix):]) {
+		switch key {
+		case "realm":
+			c.realm = value
+		case "nonce":
+			c.nonce = value
+		case "opaque":
+			c.opaque = value
+		case "algorithm":
+			c.algorithm = value
+		case "qop":
+			// only the "auth" quality of protection is supported
+			for _, qop := range strings.Split(value, ",") {
+				if strings.TrimSpace(qop) == "auth" {
+					c.qop = "auth"
+				}
+			}
+		}
+	}
+	if c.nonce == "" {
+		return nil, fmt.Errorf("digest challenge without nonce: %q", header)
+	}
+	switch strings.ToUpper(c.algorithm) {
+	case "", "MD5", "MD5-SESS", "SHA-256", "SHA-256-SESS":
+	default:
+		return nil, fmt.Errorf("unsupported digest algorithm %q", c.algorithm)
+	}
+	return c, nil
+}
+
+// parseAuthParams parses a comma separated list of key=value pairs, where
+// values may be quoted strings.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, " ,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			return params
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " ")
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			var buf []byte
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				buf = append(buf, s[i])
+			}
+			value = string(buf)
+			s = s[min(i+1, len(s)):]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		params[key] = value
+	}
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func newCnonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// rewind returns a shallow copy of the request with its body read again from
+// its start, once it was consumed by a round trip.
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("cannot resend the body of the request to %s", req.URL)
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r := new(http.Request)
+	*r = *req
+	r.Body = body
+	return r, nil
+}
+
+// withHeader returns a shallow copy of the request with the header set.
+func withHeader(req *http.Request, key, value string) *http.Request {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	r.Header.Set(key, value)
+	return r
+}
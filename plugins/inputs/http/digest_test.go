@@ -0,0 +1,45 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDigestTransportResendsBody(t *testing.T) {
+	var bodies []string
+	// like the transports of older Go releases, the body is consumed and
+	// never read again from its start
+	stub := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+		}
+		if req.Header.Get("Authorization") == "" {
+			resp.StatusCode = http.StatusUnauthorized
+			resp.Header.Set("WWW-Authenticate", `Digest realm="admin", qop="auth", nonce="dcd98b"`)
+		}
+		return resp, nil
+	})
+
+	req, err := http.NewRequest("POST", "http://localhost/metrics", strings.NewReader("query"))
+	require.NoError(t, err)
+	resp, err := newDigestTransport("user", "secret", stub).RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, []string{"query", "query"}, bodies)
+}
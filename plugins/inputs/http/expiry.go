@@ -0,0 +1,74 @@
+package http
+
+import (
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// expiryTracker remembers when each series of each URL was last returned,
+// to report the series removed from the registries.
+type expiryTracker struct {
+	sync.Mutex
+	after  time.Duration
+	field  string
+	series map[string]map[string]*seenSeries
+}
+
+type seenSeries struct {
+	name string
+	tags map[string]string
+	seen time.Time
+}
+
+func newExpiryTracker(after time.Duration, field string) *expiryTracker {
+	return &expiryTracker{
+		after:  after,
+		field:  field,
+		series: make(map[string]map[string]*seenSeries),
+	}
+}
+
+// expire records the series returned by the URL and returns a tombstone
+// for each series which was not returned for the expiry duration. The
+// tombstones have the name and tags of the series and a single field set
+// to true, they are returned once before the series is forgotten.
+func (e *expiryTracker) expire(
+	url string,
+	metrics []telegraf.Metric,
+	now time.Time,
+) []telegraf.Metric {
+	e.Lock()
+	defer e.Unlock()
+
+	series, ok := e.series[url]
+	if !ok {
+		series = make(map[string]*seenSeries)
+		e.series[url] = series
+	}
+	for _, m := range metrics {
+		key := seriesKey(m.Name(), m.Tags())
+		if s, ok := series[key]; ok {
+			s.seen = now
+			continue
+		}
+		series[key] = &seenSeries{name: m.Name(), tags: m.Tags(), seen: now}
+	}
+
+	var tombstones []telegraf.Metric
+	for key, s := range series {
+		if now.Sub(s.seen) < e.after {
+			continue
+		}
+		delete(series, key)
+		tombstone, err := metric.New(s.name, s.tags,
+			map[string]interface{}{e.field: true}, now)
+		if err != nil {
+			continue
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+	return tombstones
+}
@@ -1,29 +1,86 @@
 package http
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/logger"
 	"github.com/influxdata/telegraf/plugins/inputs"
 	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/http2"
 )
 
+// Endpoint is a URL configured with its own settings, overriding the
+// settings of the plugin.
+type Endpoint struct {
+	URL     string            `toml:"url"`
+	Timeout internal.Duration `toml:"timeout"`
+	// Name of the application, added as a tag or as a prefix of the
+	// measurement names
+	App string `toml:"app"`
+	// Paths appended to the URL, each path being requested separately and
+	// tagged with the registry tag
+	Paths []string `toml:"paths"`
+	// Names of the metrics kept and dropped, in addition to the namepass
+	// and namedrop of the plugin
+	NamePass []string `toml:"namepass"`
+	NameDrop []string `toml:"namedrop"`
+
+	// filter compiled from NamePass and NameDrop
+	nameFilter filter.Filter
+	// host of the URL template the URL was made from
+	host string
+	// path of Paths the URL was made from
+	path string
+}
+
 type HTTP struct {
 	URLs   []string `toml:"urls"`
 	Method string
+	// Body of the requests
+	Body string `toml:"body"`
+
+	// URLs made from the template for each host, tagged with the host
+	Hosts       []string `toml:"hosts"`
+	URLTemplate string   `toml:"url_template"`
+	HostTag     string   `toml:"host_tag"`
+
+	// URLs with their own settings
+	Endpoints []Endpoint `toml:"endpoint"`
 
 	Headers map[string]string
 
 	// HTTP Basic Auth Credentials
 	Username string
 	Password string
+	// Either "basic" or "digest"
+	AuthMethod string `toml:"auth_method"`
+
+	// Login request whose session cookie is sent along with the requests
+	CookieAuthURL     string            `toml:"cookie_auth_url"`
+	CookieAuthMethod  string            `toml:"cookie_auth_method"`
+	CookieAuthBody    string            `toml:"cookie_auth_body"`
+	CookieAuthHeaders map[string]string `toml:"cookie_auth_headers"`
+	CookieAuthRenewal internal.Duration `toml:"cookie_auth_renewal"`
 
 	// Path to CA file
 	SSLCA string `toml:"ssl_ca"`
@@ -31,18 +88,133 @@ type HTTP struct {
 	SSLCert string `toml:"ssl_cert"`
 	// Path to cert key file
 	SSLKey string `toml:"ssl_key"`
+	// Password of the encrypted SSLKey
+	SSLKeyPassword string `toml:"ssl_key_password"`
+	// PKCS#12 bundle of the client certificate and key, instead of SSLCert
+	// and SSLKey
+	SSLPKCS12         string `toml:"ssl_pkcs12"`
+	SSLPKCS12Password string `toml:"ssl_pkcs12_password"`
 	// Use SSL but skip chain & host verification
 	InsecureSkipVerify bool
 
+	// HTTP or SOCKS5 proxy, defaults to the proxy of the environment
+	ProxyURL string `toml:"proxy_url"`
+
+	// Source address of the connections, either an IP address or the name
+	// of a network interface
+	LocalAddress string `toml:"local_address"`
+	Interface    string `toml:"interface"`
+
 	Timeout internal.Duration
 
-	client *http.Client
+	// Timeouts of the connection and of the response headers
+	ConnectTimeout        internal.Duration `toml:"connect_timeout"`
+	ResponseHeaderTimeout internal.Duration `toml:"response_header_timeout"`
+
+	// Protocol negotiated over TLS, HTTP/2 is only used with EnableHTTP2 and
+	// never with ForceHTTP1
+	EnableHTTP2 bool `toml:"enable_http2"`
+	ForceHTTP1  bool `toml:"force_http1"`
+
+	// Status codes of successful responses, defaults to 200
+	SuccessStatusCodes []int `toml:"success_status_codes"`
+
+	// Report 204 and empty responses as errors instead of as responses
+	// without metrics
+	StrictEmptyResponse bool `toml:"strict_empty_response"`
+	// Add a http_up metric for the empty responses
+	EmptyResponseMarker bool `toml:"empty_response_marker"`
+
+	// Name of the metric reporting whether each URL was scraped
+	UpMetric string `toml:"up_metric"`
+
+	// Maximum number of URLs requested at the same time, 0 is unlimited
+	MaxConcurrentRequests int `toml:"max_concurrent_requests"`
+
+	// Spread the requests over this window, either "even" or "random"
+	StaggerWindow internal.Duration `toml:"stagger_window"`
+	StaggerMode   string            `toml:"stagger_mode"`
+
+	// Retry connection errors and server errors with an exponential backoff
+	Retries      int
+	RetryBackoff internal.Duration `toml:"retry_backoff"`
+	RetryMaxTime internal.Duration `toml:"retry_max_time"`
+
+	// Take the metric time from the response Date header or a JSON field
+	// of the response instead of using the time of the gather
+	UseDateHeader   bool   `toml:"use_date_header"`
+	TimestampPath   string `toml:"timestamp_path"`
+	TimestampFormat string `toml:"timestamp_format"`
+
+	// Add a http_scrape metric with the outcome of each request
+	ScrapeStats bool `toml:"scrape_stats"`
+
+	// Combine the metrics of all URLs into fleet level metrics, either
+	// "alongside" or "instead" of the metrics of each URL
+	Aggregate            string `toml:"aggregate"`
+	AggregateGauges      string `toml:"aggregate_gauges"`
+	AggregatePercentiles string `toml:"aggregate_percentiles"`
+
+	// Drop the gauges whose value did not change for this long
+	StaleAfter internal.Duration `toml:"stale_after"`
+
+	// Report the series not returned by their URL for this long with a
+	// tombstone metric holding the TombstoneField field
+	ExpireAfter    internal.Duration `toml:"expire_after"`
+	TombstoneField string            `toml:"tombstone_field"`
+
+	// Translate the namepass prefixes into name query parameters, only
+	// with the dropwizard data format when the metrics are not renamed
+	ServerSideFilter bool `toml:"server_side_filter"`
+
+	// Either "tag" to add the app of the endpoints as AppTag or "prefix" to
+	// prepend it to the measurement names
+	AppLabel string `toml:"app_label"`
+	AppTag   string `toml:"app_tag"`
+
+	// Tag holding the path of the endpoints configured with several paths
+	PathTag string `toml:"path_tag"`
+
+	// Logger of the plugin instance, the requests are logged at debug level
+	Log telegraf.Logger `toml:"-"`
+
+	client       *http.Client
+	targetsMu    sync.Mutex
+	targets      map[string]*target
+	sessionMu    sync.Mutex
+	sessionStart time.Time
+	stale        *staleTracker
+	expiry       *expiryTracker
+	namePrefixes []string
 
 	// The parser will automatically be set by Telegraf core code because
 	// this plugin implements the ParserInput interface (i.e. the SetParser method)
 	parser parsers.Parser
 }
 
+// target is the state of a URL kept across gathers.  A URL may be requested
+// by several requests at a time, when listed twice or when gathers overlap.
+type target struct {
+	mu sync.Mutex
+	// size of the last response body, the buffer of the next response is
+	// allocated with this size
+	bodySize int
+}
+
+// lastBodySize returns the size of the last response body of the URL.
+func (t *target) lastBodySize() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.bodySize
+}
+
+// setBodySize records the size of the last response body of the URL.
+func (t *target) setBodySize(size int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bodySize = size
+}
+
 var sampleConfig = `
   ## One or more URLs from which to read formatted metrics
   urls = [
@@ -52,12 +224,40 @@ var sampleConfig = `
   ## HTTP method
   # method = "GET"
 
+  ## Optional HTTP request body, ie, for POST requests
+  # body = '''
+  # {'fake':'data'}
+  # '''
+
+  ## URLs may also be made from a template for each host of a list, the
+  ## metrics of these URLs being tagged with their host in the host_tag tag.
+  # hosts = ["app-01.example.com", "app-02.example.com"]
+  # url_template = "https://{host}:8081/metrics"
+  # host_tag = "host"
+
   ## Optional HTTP headers
   # headers = {"X-Special-Header" = "Special-Value"}
 
   ## Optional HTTP Basic Auth Credentials
   # username = "username"
   # password = "pa$$word"
+  ## Authentication scheme of the credentials, either "basic" or "digest".
+  ## With "digest", the credentials are sent after the server challenge as
+  ## defined in RFC 7616, ie, for admin servers refusing basic
+  ## authentication over plain HTTP.
+  # auth_method = "basic"
+
+  ## Optional login request sent before the first request, whose session
+  ## cookie is then sent along with the requests, for consoles that only
+  ## expose metrics behind a form login. The body is sent as form data unless
+  ## a Content-Type header is given. The login is renewed every
+  ## cookie_auth_renewal, and after a request is rejected with a 401 or 403
+  ## status code. A renewal of 0 keeps the session until it is rejected.
+  # cookie_auth_url = "https://localhost/login"
+  # cookie_auth_method = "POST"
+  # cookie_auth_body = "username=admin&password=pa$$word"
+  # cookie_auth_headers = {"X-Requested-With" = "XMLHttpRequest"}
+  # cookie_auth_renewal = "0s"
 
   ## Tag all metrics with the url
   # tag_url = true
@@ -66,17 +266,164 @@ var sampleConfig = `
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
   # ssl_key = "/etc/telegraf/key.pem"
+  ## Password of the ssl_key when it is encrypted, either as an encrypted
+  ## PKCS#8 key or as a legacy encrypted PEM key
+  # ssl_key_password = ""
+  ## PKCS#12 bundle (.p12 or .pfx keystore) of the client certificate, its
+  ## key and its chain, used instead of ssl_cert and ssl_key. The bundle must
+  ## be encrypted with the legacy algorithms, ie, 3DES or RC2, as written by
+  ## "openssl pkcs12 -export -legacy".
+  # ssl_pkcs12 = "/etc/telegraf/client.p12"
+  # ssl_pkcs12_password = ""
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
 
+  ## Proxy of the requests, either a "http://" or "socks5://" URL, ie, of an
+  ## SSH dynamic forward. By default the proxy is taken from the HTTP_PROXY,
+  ## HTTPS_PROXY and NO_PROXY environment variables.
+  # proxy_url = "socks5://localhost:1080"
+
+  ## Source IP address of the connections, or name of the network interface
+  ## whose first address is used, ie, when the collector must be allow-listed
+  ## by firewalls.
+  # local_address = "10.0.0.12"
+  # interface = "eth1"
+
   ## Amount of time allowed to complete the HTTP request
   # timeout = "5s"
 
+  ## Amount of time allowed to establish the connection and to receive the
+  ## response headers once the request is sent, to tell unreachable hosts
+  ## apart from slow endpoints. Both are bounded by timeout and disabled
+  ## when set to 0.
+  # connect_timeout = "0s"
+  # response_header_timeout = "0s"
+
+  ## HTTP/2 is negotiated with the HTTPS servers when enable_http2 is set,
+  ## ie, for servers behind proxies only behaving correctly over HTTP/2.
+  ## Set force_http1 to always use HTTP/1.1.
+  # enable_http2 = false
+  # force_http1 = false
+
+  ## Status codes of successful responses, the response of any other status
+  ## code is reported as an error along with the start of its body.
+  # success_status_codes = [200]
+
+  ## Responses with a 204 status code or an empty body are responses without
+  ## any metrics, unless strict_empty_response is set, in which case they are
+  ## reported as errors. With empty_response_marker, a "http_up" metric with
+  ## an up=1 field is added for the empty responses.
+  # strict_empty_response = false
+  # empty_response_marker = false
+
+  ## Name of a metric added for each URL on every gather, with an up field
+  ## set to 1 if the response was received and parsed and to 0 otherwise, to
+  ## alert on unreachable endpoints. It replaces the empty_response_marker.
+  # up_metric = "dropwizard_up"
+
+  ## Maximum number of URLs requested at the same time, 0 requests all URLs
+  ## at once.
+  # max_concurrent_requests = 0
+
+  ## Spread the requests of each gather over stagger_window instead of
+  ## sending them all at once, to smooth the load of scraping many URLs. The
+  ## requests are either started at "even" intervals or at "random" times
+  ## within the window, which should be less than the collection interval.
+  # stagger_window = "0s"
+  # stagger_mode = "even"
+
+  ## Number of retries of requests failing with a connection error or a 5xx
+  ## status code, ie, while the application is being redeployed. The delay
+  ## between retries starts at retry_backoff and doubles with each retry.
+  ## No retry is attempted if it could not complete within retry_max_time,
+  ## which should be less than the collection interval.
+  # retries = 0
+  # retry_backoff = "1s"
+  # retry_max_time = "10s"
+
+  ## Use the time of the HTTP "Date" response header as metric time, useful
+  ## when responses are cached or proxied upstream.
+  # use_date_header = false
+
+  ## JSON path (gjson syntax) of a field of the response holding the metric
+  ## time, takes precedence over use_date_header. The timestamp_format is
+  ## either "unix", "unix_ms" or a Go time layout, the default is RFC3339.
+  # timestamp_path = "timestamp"
+  # timestamp_format = "2006-01-02T15:04:05Z07:00"
+
+  ## Add a "http_scrape" metric for each URL on every gather, reporting the
+  ## response time, status code, body size and number of metrics parsed, and
+  ## whether the scrape succeeded.
+  # scrape_stats = false
+
+  ## Combine the metrics with the same name and tags gathered from all URLs
+  ## into a single metric tagged with aggregated=true, ie, when scraping the
+  ## replicas of a service. Set to "alongside" to add the combined metrics
+  ## to the metrics of each URL or to "instead" to only add the combined
+  ## metrics. Counts and rates are summed, min and max fields are kept, the
+  ## gauges and other statistics, ie, percentiles, are combined using their
+  ## "mean" or "max".
+  # aggregate = ""
+  # aggregate_gauges = "mean"
+  # aggregate_percentiles = "max"
+
+  ## Drop the gauges, ie, the metrics tagged with metric_type=gauge, whose
+  ## value did not change for stale_after, to surface hung reporters. When
+  ## set, a "http_stale" metric counting the gauges and the stale gauges is
+  ## added for each URL.
+  # stale_after = "0s"
+
+  ## Series which are no longer returned by their URL, ie, metrics of removed
+  ## resources, are reported once expire_after has elapsed since they were
+  ## last returned, with a tombstone metric having the name and tags of the
+  ## series and a single tombstone_field field set to true.
+  # expire_after = "0s"
+  # tombstone_field = "expired"
+
+  ## When all the namepass patterns of the plugin are names or prefixes,
+  ## ie, namepass = ["requests*", "jvm.memory*"], they are sent to the server
+  ## as "name" query parameters, ie, "?name=requests&name=jvm.memory", so that
+  ## the dropwizard metrics servlet only returns the matching metrics.  Only
+  ## applies to the dropwizard data format, when the metric names are not
+  ## changed by dropwizard_strip_prefixes, dropwizard_rename,
+  ## dropwizard_derived, dropwizard_normalize_jvm_metrics, app_label = "prefix"
+  ## or the measurement name options.
+  # server_side_filter = false
+
+  ## The app of the endpoints is either added to the metrics as the app_tag
+  ## tag, with app_label = "tag", or prepended to the measurement names,
+  ## ie, "billing_requests", with app_label = "prefix".
+  # app_label = "tag"
+  # app_tag = "service"
+
+  ## Tag holding the path of the metrics of the endpoints configured with
+  ## several paths.
+  # path_tag = "registry"
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
   ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
   # data_format = "influx"
+
+  ## URLs may also be configured in endpoint tables, with settings
+  ## overriding the defaults of the plugin. Endpoint tables must be placed
+  ## after all other options of the plugin.
+  # [[inputs.http.endpoint]]
+  #   url = "http://remote.example.com/metrics"
+  #   ## Amount of time allowed to complete the HTTP request
+  #   timeout = "30s"
+  #   ## Name of the application serving the metrics
+  #   app = "billing"
+  #   ## Paths appended to the url, ie, when the application and admin
+  #   ## registries are served separately. Each path is requested on its own
+  #   ## and its metrics are tagged with the path in the path_tag tag.
+  #   paths = ["/metrics", "/admin/metrics"]
+  #   ## Names of the metrics kept and dropped for this endpoint only, on top
+  #   ## of the namepass and namedrop of the plugin. They are not sent to the
+  #   ## server.
+  #   namepass = ["requests*"]
+  #   namedrop = ["requests.debug*"]
 `
 
 // SampleConfig returns the default configuration of the Input
@@ -89,44 +436,384 @@ func (*HTTP) Description() string {
 	return "Read formatted metrics from one or more HTTP endpoints"
 }
 
+// Init validates the configuration and creates the client, so that invalid
+// settings are reported at startup rather than on every gather.
+func (h *HTTP) Init() error {
+	if h.parser == nil {
+		return errors.New("Parser is not set")
+	}
+	if err := h.validate(); err != nil {
+		return err
+	}
+
+	endpoints, err := h.endpoints()
+	if err != nil {
+		return err
+	}
+	for _, e := range endpoints {
+		if err := validateURL(e.URL); err != nil {
+			return err
+		}
+	}
+	if h.CookieAuthURL != "" {
+		if err := validateURL(h.CookieAuthURL); err != nil {
+			return fmt.Errorf("invalid cookie_auth_url: %s", err)
+		}
+	}
+
+	return h.createClient(len(endpoints))
+}
+
+// validate checks the options of the plugin and their combinations.
+func (h *HTTP) validate() error {
+	if len(h.Hosts) > 0 && !strings.Contains(h.URLTemplate, "{host}") {
+		return fmt.Errorf("url_template %q must contain {host}", h.URLTemplate)
+	}
+
+	switch h.AuthMethod {
+	case "", "basic", "digest":
+	default:
+		return fmt.Errorf("invalid auth_method %q, must be basic or digest", h.AuthMethod)
+	}
+
+	if (h.SSLCert == "") != (h.SSLKey == "") {
+		return errors.New("ssl_cert and ssl_key must be set together")
+	}
+	if h.SSLPKCS12 != "" && h.SSLCert != "" {
+		return errors.New("ssl_pkcs12 and ssl_cert can not be both set")
+	}
+	if h.EnableHTTP2 && h.ForceHTTP1 {
+		return errors.New("enable_http2 and force_http1 can not be both set")
+	}
+
+	if h.ProxyURL != "" {
+		proxyURL, err := url.Parse(h.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url %q: %s", h.ProxyURL, err)
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5" {
+			return fmt.Errorf("invalid proxy_url %q, scheme must be http, https or socks5", h.ProxyURL)
+		}
+	}
+
+	switch h.StaggerMode {
+	case "", "even", "random":
+	default:
+		return fmt.Errorf("invalid stagger_mode %q, must be even or random", h.StaggerMode)
+	}
+
+	switch h.AppLabel {
+	case "", "tag", "prefix":
+	default:
+		return fmt.Errorf("invalid app_label %q, must be tag or prefix", h.AppLabel)
+	}
+
+	if h.Aggregate != "" {
+		if h.Aggregate != "alongside" && h.Aggregate != "instead" {
+			return fmt.Errorf("invalid aggregate %q, must be alongside or instead", h.Aggregate)
+		}
+		if _, err := newRollup(h.AggregateGauges, h.AggregatePercentiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateURL checks that the URL is an absolute HTTP or HTTPS URL.
+func validateURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %s", u, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid url %q, scheme must be http or https", u)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid url %q, host is missing", u)
+	}
+	return nil
+}
+
+// createClient creates the client shared by the requests of all gathers,
+// keeping idle connections to the given number of URLs.
+func (h *HTTP) createClient(urls int) error {
+	tlsCfg, err := internal.GetTLSConfigWithKeyPassword(
+		h.SSLCert, h.SSLKey, h.SSLKeyPassword, h.SSLCA, h.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+	if tlsCfg == nil {
+		tlsCfg = &tls.Config{}
+	}
+	if h.SSLPKCS12 != "" {
+		cert, err := internal.LoadPKCS12(h.SSLPKCS12, h.SSLPKCS12Password)
+		if err != nil {
+			return err
+		}
+		tlsCfg.Certificates = append(tlsCfg.Certificates, cert)
+	}
+	// resume the TLS sessions when the connections are reopened
+	tlsCfg.ClientSessionCache = tls.NewLRUClientSessionCache(urls)
+	proxy := http.ProxyFromEnvironment
+	if h.ProxyURL != "" {
+		proxyURL, err := url.Parse(h.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url %q: %s", h.ProxyURL, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+	dialer, err := h.dialer()
+	if err != nil {
+		return err
+	}
+	t := &http.Transport{
+		TLSClientConfig: tlsCfg,
+		Proxy:           proxy,
+		DialContext:     dialer.DialContext,
+
+		ResponseHeaderTimeout: h.ResponseHeaderTimeout.Duration,
+
+		// keep a connection open to each URL between gathers, instead of
+		// the 2 idle connections per host by default
+		MaxIdleConnsPerHost: urls,
+	}
+	switch {
+	case h.EnableHTTP2:
+		if err := http2.ConfigureTransport(t); err != nil {
+			return err
+		}
+	case h.ForceHTTP1:
+		// a non-nil map disables the upgrade to HTTP/2
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	var transport http.RoundTripper = t
+	if h.AuthMethod == "digest" {
+		transport = newDigestTransport(h.Username, h.Password, transport)
+	}
+	// the timeout is set on each request, as it may differ per endpoint
+	h.client = &http.Client{
+		Transport: transport,
+	}
+	if h.CookieAuthURL != "" {
+		h.client.Jar, _ = cookiejar.New(nil)
+	}
+	return nil
+}
+
 // Gather takes in an accumulator and adds the metrics that the Input
 // gathers. This is called every "interval"
 func (h *HTTP) Gather(acc telegraf.Accumulator) error {
 	if h.parser == nil {
 		return errors.New("Parser is not set")
 	}
+	if err := h.validate(); err != nil {
+		return err
+	}
+
+	endpoints, err := h.endpoints()
+	if err != nil {
+		return err
+	}
 
 	if h.client == nil {
-		tlsCfg, err := internal.GetTLSConfig(
-			h.SSLCert, h.SSLKey, h.SSLCA, h.InsecureSkipVerify)
-		if err != nil {
+		if err := h.createClient(len(endpoints)); err != nil {
 			return err
 		}
-		h.client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: tlsCfg,
-				Proxy:           http.ProxyFromEnvironment,
-			},
-			Timeout: h.Timeout.Duration,
+	}
+	if h.Log == nil {
+		h.Log, _ = logger.NewPluginLogger("inputs.http", "")
+	}
+
+	if h.CookieAuthURL != "" {
+		if err := h.login(); err != nil {
+			return err
 		}
 	}
 
+	if h.StaleAfter.Duration > 0 && h.stale == nil {
+		h.stale = newStaleTracker(h.StaleAfter.Duration)
+	}
+	if h.ExpireAfter.Duration > 0 && h.expiry == nil {
+		field := h.TombstoneField
+		if field == "" {
+			field = "expired"
+		}
+		h.expiry = newExpiryTracker(h.ExpireAfter.Duration, field)
+	}
+
+	var r *rollup
+	if h.Aggregate != "" {
+		r, err = newRollup(h.AggregateGauges, h.AggregatePercentiles)
+		if err != nil {
+			return err
+		}
+	}
+
+	workers := len(endpoints)
+	if h.MaxConcurrentRequests > 0 && h.MaxConcurrentRequests < workers {
+		workers = h.MaxConcurrentRequests
+	}
+
+	queue := make(chan Endpoint)
 	var wg sync.WaitGroup
-	for _, u := range h.URLs {
+	for i := 0; i < workers; i++ {
 		wg.Add(1)
-		go func(url string) {
+		go func() {
 			defer wg.Done()
-			if err := h.gatherURL(acc, url); err != nil {
-				acc.AddError(fmt.Errorf("[url=%s]: %s", url, err))
+			for e := range queue {
+				if err := h.gatherURL(acc, e, r); err != nil {
+					acc.AddError(fmt.Errorf("[url=%s]: %s", e.URL, err))
+				}
 			}
-		}(u)
+		}()
+	}
+
+	start := time.Now()
+	delays := h.staggerDelays(len(endpoints))
+	for i, e := range endpoints {
+		if delays[i] > 0 {
+			time.Sleep(start.Add(delays[i]).Sub(time.Now()))
+		}
+		queue <- e
 	}
+	close(queue)
 
 	wg.Wait()
 
+	if r != nil {
+		r.emit(acc)
+	}
+
 	return nil
 }
 
+// dialer returns the dialer of the connections, bound to the local address
+// or network interface.
+func (h *HTTP) dialer() (*net.Dialer, error) {
+	dialer := &net.Dialer{
+		Timeout:   h.ConnectTimeout.Duration,
+		KeepAlive: 30 * time.Second,
+	}
+
+	var ip net.IP
+	switch {
+	case h.LocalAddress != "" && h.Interface != "":
+		return nil, errors.New("local_address and interface are mutually exclusive")
+	case h.LocalAddress != "":
+		ip = net.ParseIP(h.LocalAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local_address %q", h.LocalAddress)
+		}
+	case h.Interface != "":
+		iface, err := net.InterfaceByName(h.Interface)
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface %q: %s", h.Interface, err)
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("invalid interface %q: %s", h.Interface, err)
+		}
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok {
+				ip = ipnet.IP
+				break
+			}
+		}
+		if ip == nil {
+			return nil, fmt.Errorf("interface %q has no address", h.Interface)
+		}
+	default:
+		return dialer, nil
+	}
+
+	dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	return dialer, nil
+}
+
+// endpoints returns the endpoints of the urls and of the endpoint tables,
+// with the settings of the plugin filled in.
+func (h *HTTP) endpoints() ([]Endpoint, error) {
+	endpoints := make([]Endpoint, 0, len(h.URLs)+len(h.Endpoints))
+	for _, u := range h.URLs {
+		endpoints = append(endpoints, Endpoint{URL: u})
+	}
+	for _, host := range h.Hosts {
+		endpoints = append(endpoints, Endpoint{
+			URL:  strings.Replace(h.URLTemplate, "{host}", host, -1),
+			host: host,
+		})
+	}
+	for _, e := range h.Endpoints {
+		if len(e.Paths) == 0 {
+			endpoints = append(endpoints, e)
+			continue
+		}
+		for _, path := range e.Paths {
+			pathEndpoint := e
+			pathEndpoint.URL = strings.TrimSuffix(e.URL, "/") + "/" + strings.TrimPrefix(path, "/")
+			pathEndpoint.path = path
+			endpoints = append(endpoints, pathEndpoint)
+		}
+	}
+
+	for i := range endpoints {
+		if endpoints[i].Timeout.Duration == 0 {
+			endpoints[i].Timeout = h.Timeout
+		}
+		if len(endpoints[i].NamePass) > 0 || len(endpoints[i].NameDrop) > 0 {
+			f, err := filter.NewIncludeExcludeFilter(endpoints[i].NamePass, endpoints[i].NameDrop)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter of endpoint %s: %s", endpoints[i].URL, err)
+			}
+			endpoints[i].nameFilter = f
+		}
+	}
+	return endpoints, nil
+}
+
+// staggerDelays returns the delays of n requests from the start of the
+// gather, in increasing order. The random delays are sorted as the requests
+// are queued one after the other.
+func (h *HTTP) staggerDelays(n int) []time.Duration {
+	delays := make([]time.Duration, n)
+	window := h.StaggerWindow.Duration
+	if window <= 0 {
+		return delays
+	}
+	for i := range delays {
+		if h.StaggerMode == "random" {
+			delays[i] = time.Duration(rand.Int63n(int64(window)))
+		} else {
+			delays[i] = window * time.Duration(i) / time.Duration(n)
+		}
+	}
+	sort.Slice(delays, func(i, j int) bool { return delays[i] < delays[j] })
+	return delays
+}
+
+func (h *HTTP) appTag() string {
+	if h.AppTag == "" {
+		return "service"
+	}
+	return h.AppTag
+}
+
+// SetNamePass sets the namepass patterns of the plugin, which are sent to
+// the server when they are all names or prefixes.
+func (h *HTTP) SetNamePass(namepass []string) {
+	var prefixes []string
+	for _, pattern := range namepass {
+		prefix := strings.TrimSuffix(pattern, "*")
+		if prefix == "" || strings.ContainsAny(prefix, "*?[]{}\\") {
+			// the patterns can not be translated, filter the metrics locally
+			h.namePrefixes = nil
+			return
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	h.namePrefixes = prefixes
+}
+
 // SetParser takes the data_format from the config and finds the right parser for that format
 func (h *HTTP) SetParser(parser parsers.Parser) {
 	h.parser = parser
@@ -135,19 +822,238 @@ func (h *HTTP) SetParser(parser parsers.Parser) {
 // Gathers data from a particular URL
 // Parameters:
 //     acc    : The telegraf Accumulator to use
-//     url    : endpoint to send request to
+//     e      : endpoint to send request to
+//     r      : the rollup of the gather, nil if not aggregating
 //
 // Returns:
 //     error: Any error that may have occurred
 func (h *HTTP) gatherURL(
 	acc telegraf.Accumulator,
-	url string,
+	e Endpoint,
+	r *rollup,
 ) error {
-	request, err := http.NewRequest(h.Method, url, nil)
+	url := e.URL
+	start := time.Now()
+	resp, b, err := h.requestWithRetries(e)
+	var metrics []telegraf.Metric
+	var empty bool
+	if err == nil {
+		if len(bytes.TrimSpace(b)) == 0 && !h.StrictEmptyResponse {
+			empty = true
+		} else {
+			metrics, err = h.parser.Parse(b)
+		}
+	}
+
+	if h.ScrapeStats {
+		h.addScrapeStats(acc, url, time.Since(start), resp, b, len(metrics), err)
+	}
+	if h.UpMetric != "" {
+		h.addUp(acc, e, err == nil)
+	}
 	if err != nil {
 		return err
 	}
 
+	if e.nameFilter != nil {
+		kept := metrics[:0]
+		for _, metric := range metrics {
+			if e.nameFilter.Match(metric.Name()) {
+				kept = append(kept, metric)
+			}
+		}
+		metrics = kept
+	}
+
+	if empty && h.EmptyResponseMarker && h.UpMetric == "" {
+		acc.AddFields("http_up",
+			map[string]interface{}{"up": 1},
+			map[string]string{"url": url})
+	}
+
+	// tags identifying the URL, left out of the fleet level series
+	instanceTags := []string{"url"}
+
+	if e.host != "" {
+		hostTag := h.HostTag
+		if hostTag == "" {
+			hostTag = "host"
+		}
+		for _, metric := range metrics {
+			metric.AddTag(hostTag, e.host)
+		}
+		instanceTags = append(instanceTags, hostTag)
+	}
+
+	if e.path != "" {
+		pathTag := h.PathTag
+		if pathTag == "" {
+			pathTag = "registry"
+		}
+		for _, metric := range metrics {
+			metric.AddTag(pathTag, e.path)
+		}
+		instanceTags = append(instanceTags, pathTag)
+	}
+
+	if e.App != "" {
+		for _, metric := range metrics {
+			if h.AppLabel == "prefix" {
+				metric.SetPrefix(e.App + "_")
+			} else {
+				metric.AddTag(h.appTag(), e.App)
+			}
+		}
+	}
+
+	if h.expiry != nil {
+		for _, tombstone := range h.expiry.expire(url, metrics, time.Now()) {
+			tags := tombstone.Tags()
+			if _, ok := tags["url"]; !ok {
+				tags["url"] = url
+			}
+			acc.AddFields(tombstone.Name(), tombstone.Fields(), tags, tombstone.Time())
+		}
+	}
+
+	if h.stale != nil {
+		var gauges, stale int
+		metrics, gauges, stale = h.stale.filter(url, metrics, time.Now())
+		acc.AddFields("http_stale",
+			map[string]interface{}{
+				"gauges":       gauges,
+				"stale_gauges": stale,
+			},
+			map[string]string{"url": url})
+	}
+
+	t, ok, err := h.responseTime(resp, b)
+	if err != nil {
+		acc.AddError(fmt.Errorf("[url=%s]: %s", url, err))
+	}
+
+	for _, metric := range metrics {
+		if !metric.HasTag("url") {
+			metric.AddTag("url", url)
+		}
+		mt := metric.Time()
+		if ok {
+			mt = t
+		}
+		if r != nil {
+			r.add(metric, mt, instanceTags)
+			if h.Aggregate == "instead" {
+				continue
+			}
+		}
+		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), mt)
+	}
+
+	return nil
+}
+
+// addUp adds the up metric of the endpoint, with an up field set to 1 if
+// the scrape succeeded and to 0 otherwise.
+func (h *HTTP) addUp(acc telegraf.Accumulator, e Endpoint, scraped bool) {
+	up := 0
+	if scraped {
+		up = 1
+	}
+	tags := map[string]string{"url": e.URL}
+	if e.host != "" {
+		hostTag := h.HostTag
+		if hostTag == "" {
+			hostTag = "host"
+		}
+		tags[hostTag] = e.host
+	}
+	if e.App != "" {
+		tags[h.appTag()] = e.App
+	}
+	acc.AddFields(h.UpMetric, map[string]interface{}{"up": up}, tags)
+}
+
+// addScrapeStats adds the http_scrape metric describing a single gather of
+// the URL.
+func (h *HTTP) addScrapeStats(
+	acc telegraf.Accumulator,
+	url string,
+	elapsed time.Duration,
+	resp *http.Response,
+	body []byte,
+	gathered int,
+	err error,
+) {
+	fields := map[string]interface{}{
+		"response_time":    elapsed.Seconds(),
+		"body_bytes":       len(body),
+		"metrics_gathered": gathered,
+		"success":          1,
+	}
+	if resp != nil {
+		fields["http_status"] = resp.StatusCode
+	}
+	if err != nil {
+		fields["metrics_gathered"] = 0
+		fields["success"] = 0
+	}
+	acc.AddFields("http_scrape", fields, map[string]string{"url": url})
+}
+
+// requestWithRetries requests the URL and returns the response and its body.
+// Connection errors and server errors are retried with an exponential
+// backoff, as long as the retries fit into retry_max_time.
+func (h *HTTP) requestWithRetries(e Endpoint) (*http.Response, []byte, error) {
+	start := time.Now()
+	backoff := h.RetryBackoff.Duration
+	for attempt := 0; ; attempt++ {
+		resp, b, retry, err := h.request(e)
+		if err == nil || !retry || attempt >= h.Retries {
+			return resp, b, err
+		}
+		if h.RetryMaxTime.Duration > 0 &&
+			time.Since(start)+backoff+e.Timeout.Duration > h.RetryMaxTime.Duration {
+			return resp, nil, fmt.Errorf("%s (giving up after %d retries)", err, attempt)
+		}
+		h.Log.Debugf("Retrying %s in %s: %s", redactURL(e.URL), backoff, err)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// request performs a single request of the URL. The returned bool is true if
+// the request failed with an error that is worth retrying. The response is
+// also returned when the status code is not OK.
+func (h *HTTP) request(e Endpoint) (*http.Response, []byte, bool, error) {
+	u := e.URL
+	if h.ServerSideFilter && len(h.namePrefixes) > 0 && h.AppLabel != "prefix" {
+		parsed, err := url.Parse(u)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		query := parsed.Query()
+		for _, prefix := range h.namePrefixes {
+			query.Add("name", prefix)
+		}
+		parsed.RawQuery = query.Encode()
+		u = parsed.String()
+	}
+
+	var reqBody io.Reader
+	if h.Body != "" {
+		reqBody = strings.NewReader(h.Body)
+	}
+	request, err := http.NewRequest(h.Method, u, reqBody)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if e.Timeout.Duration > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), e.Timeout.Duration)
+		defer cancel()
+		request = request.WithContext(ctx)
+	}
+
 	for k, v := range h.Headers {
 		if strings.ToLower(k) == "host" {
 			request.Host = v
@@ -156,49 +1062,176 @@ func (h *HTTP) gatherURL(
 		}
 	}
 
-	if h.Username != "" || h.Password != "" {
+	if (h.Username != "" || h.Password != "") && h.AuthMethod != "digest" {
 		request.SetBasicAuth(h.Username, h.Password)
 	}
 
+	start := time.Now()
 	resp, err := h.client.Do(request)
 	if err != nil {
-		return err
+		h.Log.Debugf("%s %s failed after %s: %s", request.Method, redactURL(u), time.Since(start), err)
+		return nil, nil, true, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Received status code %d (%s), expected %d (%s)",
-			resp.StatusCode,
-			http.StatusText(resp.StatusCode),
-			http.StatusOK,
-			http.StatusText(http.StatusOK))
+	if h.CookieAuthURL != "" &&
+		(resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+		h.logout()
 	}
 
-	b, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	if !h.isSuccess(resp.StatusCode) {
+		h.Log.Debugf("%s %s: %s in %s", request.Method, redactURL(u), resp.Status, time.Since(start))
+		// server errors are commonly returned while the application restarts
+		return resp, nil, resp.StatusCode >= 500,
+			fmt.Errorf("Received status code %d (%s), expected any value out of %v: %s",
+				resp.StatusCode,
+				http.StatusText(resp.StatusCode),
+				h.successStatusCodes(),
+				bodyExcerpt(resp.Body))
 	}
 
-	metrics, err := h.parser.Parse(b)
-	if err != nil {
-		return err
+	t := h.target(e.URL)
+	// the metrics parsed from the body may refer to it, a new buffer is
+	// allocated for each response
+	body := bytes.NewBuffer(make([]byte, 0, t.lastBodySize()+bytes.MinRead))
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		return nil, nil, true, err
 	}
+	t.setBodySize(body.Len())
+	h.Log.Debugf("%s %s: %s, %d bytes of %s in %s", request.Method, redactURL(u), resp.Status,
+		body.Len(), resp.Header.Get("Content-Type"), time.Since(start))
+	return resp, body.Bytes(), false, nil
+}
 
-	for _, metric := range metrics {
-		if !metric.HasTag("url") {
-			metric.AddTag("url", url)
+// redactURL returns the URL with its password replaced, to be logged.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.User == nil {
+		return u
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+	}
+	return parsed.String()
+}
+
+// target returns the state of the URL, shared by the requests of the URL.
+func (h *HTTP) target(u string) *target {
+	h.targetsMu.Lock()
+	defer h.targetsMu.Unlock()
+	if h.targets == nil {
+		h.targets = make(map[string]*target)
+	}
+	t, ok := h.targets[u]
+	if !ok {
+		t = &target{}
+		h.targets[u] = t
+	}
+	return t
+}
+
+func (h *HTTP) successStatusCodes() []int {
+	if len(h.SuccessStatusCodes) == 0 {
+		return []int{http.StatusOK}
+	}
+	return h.SuccessStatusCodes
+}
+
+func (h *HTTP) isSuccess(statusCode int) bool {
+	if statusCode == http.StatusNoContent && !h.StrictEmptyResponse {
+		return true
+	}
+	for _, code := range h.successStatusCodes() {
+		if statusCode == code {
+			return true
 		}
-		acc.AddFields(metric.Name(), metric.Fields(), metric.Tags(), metric.Time())
 	}
+	return false
+}
 
-	return nil
+// bodyExcerpt returns the start of a response body on a single line, to
+// be included in error messages.
+func bodyExcerpt(body io.Reader) string {
+	const maxLength = 256
+	b, _ := ioutil.ReadAll(io.LimitReader(body, maxLength+1))
+	excerpt := strings.Join(strings.Fields(string(b)), " ")
+	if len(b) > maxLength {
+		if len(excerpt) > maxLength {
+			excerpt = excerpt[:maxLength]
+		}
+		excerpt += "..."
+	}
+	if excerpt == "" {
+		return "empty body"
+	}
+	return excerpt
+}
+
+// responseTime returns the metric time taken from the response, if
+// configured. The returned bool is false if the metric time is not
+// overridden.
+func (h *HTTP) responseTime(resp *http.Response, body []byte) (time.Time, bool, error) {
+	if h.TimestampPath != "" {
+		result := gjson.GetBytes(body, h.TimestampPath)
+		if !result.Exists() {
+			return time.Time{}, false,
+				fmt.Errorf("timestamp not found in JSON path %s", h.TimestampPath)
+		}
+		t, err := parseTimestamp(result, h.TimestampFormat)
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return t, true, nil
+	}
+
+	if h.UseDateHeader {
+		date := resp.Header.Get("Date")
+		if date == "" {
+			return time.Time{}, false, errors.New("response has no Date header")
+		}
+		t, err := http.ParseTime(date)
+		if err != nil {
+			return time.Time{}, false,
+				fmt.Errorf("unable to parse Date header %q: %s", date, err)
+		}
+		return t, true, nil
+	}
+
+	return time.Time{}, false, nil
+}
+
+func parseTimestamp(result gjson.Result, format string) (time.Time, error) {
+	switch format {
+	case "unix":
+		sec, frac := math.Modf(result.Float())
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	case "unix_ms":
+		return time.Unix(0, result.Int()*int64(time.Millisecond)).UTC(), nil
+	case "":
+		format = time.RFC3339
+	}
+
+	t, err := time.Parse(format, result.String())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q cannot be parsed with format %s, %s",
+			result.String(), format, err)
+	}
+	return t.UTC(), nil
 }
 
 func init() {
 	inputs.Add("http", func() telegraf.Input {
 		return &HTTP{
-			Timeout: internal.Duration{Duration: time.Second * 5},
-			Method:  "GET",
+			Timeout:            internal.Duration{Duration: time.Second * 5},
+			Method:             "GET",
+			RetryBackoff:       internal.Duration{Duration: time.Second},
+			RetryMaxTime:       internal.Duration{Duration: time.Second * 10},
+			SuccessStatusCodes: []int{http.StatusOK},
+			AppLabel:           "tag",
+			AppTag:             "service",
+			HostTag:            "host",
+			PathTag:            "registry",
+			TombstoneField:     "expired",
 		}
 	})
 }
@@ -1,13 +1,27 @@
 package http_test
 
 import (
+	"crypto/md5"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/influxdata/telegraf/internal"
 	plugin "github.com/influxdata/telegraf/plugins/inputs/http"
 	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/toml"
 	"github.com/stretchr/testify/require"
 )
 
@@ -113,6 +127,50 @@ func TestMethod(t *testing.T) {
 	require.NoError(t, acc.GatherError(plugin.Gather))
 }
 
+func TestBody(t *testing.T) {
+	var received string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:   []string{fakeServer.URL},
+		Method: "POST",
+		Body:   `{"query":"up"}`,
+	}
+
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, `{"query":"up"}`, received)
+}
+
+func TestPrometheusFormat(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("# TYPE go_goroutines gauge\ngo_goroutines{job=\"api\"} 15\n"))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs: []string{fakeServer.URL},
+	}
+
+	p, err := parsers.NewParser(&parsers.Config{DataFormat: "prometheus"})
+	require.NoError(t, err)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	acc.AssertContainsTaggedFields(t, "go_goroutines",
+		map[string]interface{}{"gauge": float64(15)},
+		map[string]string{"job": "api", "url": fakeServer.URL})
+}
+
 func TestParserNotSet(t *testing.T) {
 	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/endpoint" {
@@ -132,8 +190,1114 @@ func TestParserNotSet(t *testing.T) {
 	require.Error(t, acc.GatherError(plugin.Gather))
 }
 
+func TestDateHeaderTimestamp(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Date", "Tue, 06 Mar 2018 10:00:00 GMT")
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:          []string{fakeServer.URL},
+		UseDateHeader: true,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, time.Date(2018, 3, 6, 10, 0, 0, 0, time.UTC), acc.Metrics[0].Time.UTC())
+}
+
+func TestJSONTimestamp(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"a": 1.2, "ts": 1520330400500}`))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:            []string{fakeServer.URL},
+		UseDateHeader:   true,
+		TimestampPath:   "ts",
+		TimestampFormat: "unix_ms",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, time.Date(2018, 3, 6, 10, 0, 0, 500000000, time.UTC), acc.Metrics[0].Time.UTC())
+}
+
+func TestJSONTimestampMissing(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:          []string{fakeServer.URL},
+		TimestampPath: "ts",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(plugin.Gather))
+	// metrics are kept with the time of the gather
+	require.Len(t, acc.Metrics, 1)
+}
+
+func TestRetries(t *testing.T) {
+	var requests int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:         []string{fakeServer.URL},
+		Retries:      2,
+		RetryBackoff: internal.Duration{Duration: time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, 3, requests)
+	require.Len(t, acc.Metrics, 1)
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var requests int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:         []string{fakeServer.URL},
+		Retries:      2,
+		RetryBackoff: internal.Duration{Duration: time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, 1, requests)
+}
+
+func TestRetriesWithinMaxTime(t *testing.T) {
+	var requests int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:         []string{fakeServer.URL},
+		Retries:      5,
+		RetryBackoff: internal.Duration{Duration: 50 * time.Millisecond},
+		RetryMaxTime: internal.Duration{Duration: 120 * time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(plugin.Gather))
+	// 50ms and 100ms backoffs do not both fit into 120ms
+	require.Equal(t, 2, requests)
+}
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	var active, maxActive int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte(simpleJSON))
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}))
+	defer fakeServer.Close()
+
+	var urls []string
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fakeServer.URL)
+	}
+	plugin := &plugin.HTTP{
+		URLs:                  urls,
+		MaxConcurrentRequests: 2,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 10)
+	require.True(t, maxActive <= 2)
+}
+
+// Test that the state kept per URL is safe when a URL listed several times
+// is requested by concurrent requests, to be run with -race.
+func TestDuplicateURLs(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs: []string{fakeServer.URL, fakeServer.URL, fakeServer.URL},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	for i := 0; i < 2; i++ {
+		var acc testutil.Accumulator
+		require.NoError(t, acc.GatherError(plugin.Gather))
+		require.Len(t, acc.Metrics, 3)
+	}
+}
+
+func TestScrapeStats(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/endpoint" {
+			_, _ = w.Write([]byte(simpleJSON))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:        []string{fakeServer.URL + "/endpoint", fakeServer.URL + "/missing"},
+		ScrapeStats: true,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(plugin.Gather))
+
+	for _, m := range acc.Metrics {
+		if m.Measurement != "http_scrape" {
+			continue
+		}
+		switch m.Tags["url"] {
+		case fakeServer.URL + "/endpoint":
+			require.Equal(t, 1, m.Fields["success"])
+			require.Equal(t, 200, m.Fields["http_status"])
+			require.Equal(t, 1, m.Fields["metrics_gathered"])
+			require.Equal(t, len(simpleJSON), m.Fields["body_bytes"])
+		case fakeServer.URL + "/missing":
+			require.Equal(t, 0, m.Fields["success"])
+			require.Equal(t, 404, m.Fields["http_status"])
+		}
+	}
+	require.True(t, acc.HasMeasurement("metricName"))
+	require.Len(t, acc.Metrics, 3)
+}
+
+func TestAggregate(t *testing.T) {
+	newServer := func(body string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+	}
+	server1 := newServer("requests,metric_type=timer count=2i,p99=10,min=1,m1_rate=1\n" +
+		"queue,metric_type=gauge value=4\n")
+	defer server1.Close()
+	server2 := newServer("requests,metric_type=timer count=3i,p99=20,min=2,m1_rate=2\n" +
+		"queue,metric_type=gauge value=2\n")
+	defer server2.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:      []string{server1.URL, server2.URL},
+		Aggregate: "instead",
+	}
+	p, _ := parsers.NewInfluxParser()
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 2)
+	acc.AssertContainsTaggedFields(t, "requests",
+		map[string]interface{}{
+			"count":   int64(5),
+			"p99":     float64(20),
+			"min":     float64(1),
+			"m1_rate": float64(3),
+		},
+		map[string]string{"metric_type": "timer", "aggregated": "true"})
+	acc.AssertContainsTaggedFields(t, "queue",
+		map[string]interface{}{"value": float64(3)},
+		map[string]string{"metric_type": "gauge", "aggregated": "true"})
+
+	plugin.Aggregate = "alongside"
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 6)
+
+	plugin.Aggregate = "bogus"
+	require.Error(t, plugin.Gather(&acc))
+}
+
+func TestAggregateHosts(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app-01/metrics":
+			_, _ = w.Write([]byte("queue,metric_type=gauge value=4\n"))
+		case "/app-02/metrics":
+			_, _ = w.Write([]byte("queue,metric_type=gauge value=2\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		Hosts:       []string{"app-01", "app-02"},
+		URLTemplate: fakeServer.URL + "/{host}/metrics",
+		Aggregate:   "instead",
+	}
+	p, _ := parsers.NewInfluxParser()
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 1)
+	acc.AssertContainsTaggedFields(t, "queue",
+		map[string]interface{}{"value": float64(3)},
+		map[string]string{"metric_type": "gauge", "aggregated": "true"})
+}
+
+func TestStaleAfter(t *testing.T) {
+	var value = "1"
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("queue,metric_type=gauge value=" + value + "\n" +
+			"requests,metric_type=counter count=1\n"))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:       []string{fakeServer.URL},
+		StaleAfter: internal.Duration{Duration: 50 * time.Millisecond},
+	}
+	p, _ := parsers.NewInfluxParser()
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.True(t, acc.HasMeasurement("queue"))
+
+	time.Sleep(60 * time.Millisecond)
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.False(t, acc.HasMeasurement("queue"))
+	require.True(t, acc.HasMeasurement("requests"))
+	acc.AssertContainsTaggedFields(t, "http_stale",
+		map[string]interface{}{"gauges": 1, "stale_gauges": 1},
+		map[string]string{"url": fakeServer.URL})
+
+	value = "2"
+	acc = testutil.Accumulator{}
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.True(t, acc.HasMeasurement("queue"))
+}
+
+func TestEndpointTimeout(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	tbl, err := toml.Parse([]byte(`
+urls = ["` + fakeServer.URL + `/fast"]
+timeout = "50ms"
+
+[[endpoint]]
+  url = "` + fakeServer.URL + `/slow"
+  timeout = "1s"
+`))
+	require.NoError(t, err)
+	plugin := &plugin.HTTP{}
+	require.NoError(t, toml.UnmarshalTable(tbl, plugin))
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 2)
+
+	plugin.Endpoints[0].Timeout.Duration = 10 * time.Millisecond
+	acc = testutil.Accumulator{}
+	require.Error(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 1)
+}
+
+func TestServerSideFilter(t *testing.T) {
+	var query string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:             []string{fakeServer.URL + "?pretty=true"},
+		ServerSideFilter: true,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	plugin.SetNamePass([]string{"requests*", "jvm.memory"})
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, "name=requests&name=jvm.memory&pretty=true", query)
+	require.Equal(t, fakeServer.URL+"?pretty=true", acc.Metrics[0].Tags["url"])
+
+	plugin.SetNamePass([]string{"requests*", "*.count"})
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, "pretty=true", query)
+
+	plugin.SetNamePass([]string{"requests*"})
+	plugin.ServerSideFilter = false
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, "pretty=true", query)
+
+	// the names are filtered once prefixed with the app
+	plugin.ServerSideFilter = true
+	plugin.AppLabel = "prefix"
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Equal(t, "pretty=true", query)
+}
+
+func TestSuccessStatusCodes(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/accepted" {
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(simpleJSON))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("<html>\n  <body>Internal error</body>\n</html>\n" + strings.Repeat("x", 300)))
+	}))
+	defer fakeServer.Close()
+
+	plugin := &plugin.HTTP{
+		URLs:               []string{fakeServer.URL + "/accepted"},
+		SuccessStatusCodes: []int{200, 202},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(plugin.Gather))
+	require.Len(t, acc.Metrics, 1)
+
+	plugin.URLs = []string{fakeServer.URL + "/error"}
+	err := acc.GatherError(plugin.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"Received status code 500 (Internal Server Error), expected any value out of [200 202]: "+
+			"<html> <body>Internal error</body> </html> xxx")
+	require.True(t, strings.HasSuffix(err.Error(), "x..."))
+}
+
+func TestDigestAuth(t *testing.T) {
+	const nonce = "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	md5hex := func(s string) string {
+		return fmt.Sprintf("%x", md5.Sum([]byte(s)))
+	}
+
+	var challenges int
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := make(map[string]string)
+		auth := r.Header.Get("Authorization")
+		for _, param := range strings.Split(strings.TrimPrefix(auth, "Digest "), ", ") {
+			kv := strings.SplitN(param, "=", 2)
+			if len(kv) == 2 {
+				params[kv[0]] = strings.Trim(kv[1], `"`)
+			}
+		}
+
+		ha1 := md5hex("user:admin:secret")
+		ha2 := md5hex(r.Method + ":" + r.URL.RequestURI())
+		expected := md5hex(strings.Join([]string{
+			ha1, nonce, params["nc"], params["cnonce"], "auth", ha2}, ":"))
+		if !strings.HasPrefix(auth, "Digest ") || params["response"] != expected ||
+			params["opaque"] != "5ccc069c" || params["uri"] != r.URL.RequestURI() {
+			challenges++
+			w.Header().Set("WWW-Authenticate",
+				`Digest realm="admin", qop="auth,auth-int", nonce="`+nonce+`", opaque="5ccc069c", algorithm=MD5`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:       []string{fakeServer.URL + "/metrics?pretty=true"},
+		Username:   "user",
+		Password:   "secret",
+		AuthMethod: "digest",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	// the challenge is reused by the second gather
+	require.Equal(t, 1, challenges)
+
+	h = &plugin.HTTP{
+		URLs:       []string{fakeServer.URL + "/metrics"},
+		Username:   "user",
+		Password:   "wrong",
+		AuthMethod: "digest",
+	}
+	h.SetParser(p)
+	err := acc.GatherError(h.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Received status code 401")
+}
+
+func TestDigestAuthBody(t *testing.T) {
+	var bodies []string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="admin", qop="auth", nonce="dcd98b"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:       []string{fakeServer.URL + "/metrics"},
+		Method:     "POST",
+		Body:       `{"query": "metrics"}`,
+		Username:   "user",
+		Password:   "secret",
+		AuthMethod: "digest",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+	// the body is sent along the challenge request and the authorized one
+	require.Equal(t, []string{`{"query": "metrics"}`, `{"query": "metrics"}`}, bodies)
+}
+
+func TestSOCKS5Proxy(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	// minimal SOCKS5 server without authentication, only accepting CONNECT
+	// requests to IPv4 addresses
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	var proxied int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				buf := make([]byte, 262)
+				// greeting: version, number of methods, methods
+				if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+					return
+				}
+				conn.Write([]byte{5, 0})
+				// request: version, command, reserved, address type, IPv4, port
+				if _, err := io.ReadFull(conn, buf[:10]); err != nil || buf[3] != 1 {
+					return
+				}
+				addr := net.JoinHostPort(net.IP(buf[4:8]).String(), strconv.Itoa(int(buf[8])<<8|int(buf[9])))
+				target, err := net.Dial("tcp", addr)
+				if err != nil {
+					return
+				}
+				defer target.Close()
+				atomic.AddInt32(&proxied, 1)
+				conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(target, conn)
+				io.Copy(conn, target)
+			}(conn)
+		}
+	}()
+
+	h := &plugin.HTTP{
+		URLs:     []string{fakeServer.URL},
+		ProxyURL: "socks5://" + listener.Addr().String(),
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+	require.Equal(t, int32(1), atomic.LoadInt32(&proxied))
+
+	h = &plugin.HTTP{
+		URLs:     []string{fakeServer.URL},
+		ProxyURL: "ftp://localhost",
+	}
+	h.SetParser(p)
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestLocalAddress(t *testing.T) {
+	var remoteAddr string
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		remoteAddr = r.RemoteAddr
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:         []string{fakeServer.URL},
+		LocalAddress: "127.0.0.2",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+	host, _, err := net.SplitHostPort(remoteAddr)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.2", host)
+
+	h = &plugin.HTTP{
+		URLs:         []string{fakeServer.URL},
+		LocalAddress: "localhost",
+	}
+	h.SetParser(p)
+	require.Error(t, acc.GatherError(h.Gather))
+
+	h = &plugin.HTTP{
+		URLs:      []string{fakeServer.URL},
+		Interface: "nonexistent0",
+	}
+	h.SetParser(p)
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:                  []string{fakeServer.URL},
+		Timeout:               internal.Duration{Duration: 5 * time.Second},
+		ConnectTimeout:        internal.Duration{Duration: time.Second},
+		ResponseHeaderTimeout: internal.Duration{Duration: 50 * time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	err := acc.GatherError(h.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timeout awaiting response headers")
+}
+
+func TestHTTP2(t *testing.T) {
+	var proto int32
+	fakeServer := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.StoreInt32(&proto, int32(r.ProtoMajor))
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	fakeServer.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	fakeServer.StartTLS()
+	defer fakeServer.Close()
+
+	gather := func(h *plugin.HTTP) int32 {
+		h.URLs = []string{fakeServer.URL}
+		h.InsecureSkipVerify = true
+		p, _ := parsers.NewJSONParser("metricName", nil, nil)
+		h.SetParser(p)
+
+		var acc testutil.Accumulator
+		require.NoError(t, acc.GatherError(h.Gather))
+		return atomic.LoadInt32(&proto)
+	}
+
+	require.Equal(t, int32(2), gather(&plugin.HTTP{EnableHTTP2: true}))
+	require.Equal(t, int32(1), gather(&plugin.HTTP{ForceHTTP1: true}))
+
+	h := &plugin.HTTP{EnableHTTP2: true, ForceHTTP1: true, URLs: []string{fakeServer.URL}}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestTLSSessionResumption(t *testing.T) {
+	var resumed int32
+	fakeServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS.DidResume {
+			atomic.StoreInt32(&resumed, 1)
+		}
+		// every gather opens a new connection
+		w.Header().Set("Connection", "close")
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:               []string{fakeServer.URL},
+		InsecureSkipVerify: true,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Equal(t, int32(0), atomic.LoadInt32(&resumed))
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Equal(t, int32(1), atomic.LoadInt32(&resumed))
+	require.Len(t, acc.Metrics, 2)
+}
+
+func TestCookieAuth(t *testing.T) {
+	var logins int
+	session := "1"
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			r.ParseForm()
+			if r.Method != "POST" || r.PostForm.Get("password") != "secret" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			logins++
+			session = strconv.Itoa(logins)
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: session, Path: "/"})
+		case "/metrics":
+			cookie, err := r.Cookie("session")
+			if err != nil || cookie.Value != session {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(simpleJSON))
+		}
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:           []string{fakeServer.URL + "/metrics"},
+		CookieAuthURL:  fakeServer.URL + "/login",
+		CookieAuthBody: "username=admin&password=secret",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.Equal(t, 1, logins)
+
+	// the session expires on the server, the next gather logs in again
+	session = "expired"
+	require.Error(t, acc.GatherError(h.Gather))
+	acc.Errors = nil
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 3)
+	require.Equal(t, 2, logins)
+
+	h = &plugin.HTTP{
+		URLs:           []string{fakeServer.URL + "/metrics"},
+		CookieAuthURL:  fakeServer.URL + "/login",
+		CookieAuthBody: "username=admin&password=wrong",
+	}
+	h.SetParser(p)
+	err := acc.GatherError(h.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "received status code 403")
+}
+
+func TestEndpointApp(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs: []string{fakeServer.URL + "/none"},
+		Endpoints: []plugin.Endpoint{
+			{URL: fakeServer.URL + "/billing", App: "billing"},
+		},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.True(t, acc.HasPoint("metricName",
+		map[string]string{"url": fakeServer.URL + "/billing", "service": "billing"}, "a", 1.2))
+	require.True(t, acc.HasPoint("metricName",
+		map[string]string{"url": fakeServer.URL + "/none"}, "a", 1.2))
+
+	acc = testutil.Accumulator{}
+	h.AppLabel = "prefix"
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.True(t, acc.HasMeasurement("billing_metricName"))
+	require.True(t, acc.HasMeasurement("metricName"))
+
+	h.AppLabel = "suffix"
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestStagger(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs: []string{
+			fakeServer.URL + "/1",
+			fakeServer.URL + "/2",
+			fakeServer.URL + "/3",
+			fakeServer.URL + "/4",
+		},
+		StaggerWindow: internal.Duration{Duration: 200 * time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 4)
+	require.Len(t, arrivals, 4)
+	// the requests are started every 50ms
+	require.True(t, arrivals[3].Sub(arrivals[0]) >= 140*time.Millisecond)
+
+	// the random requests are spread over the whole window, rather than
+	// bunched up at its end
+	h.URLs = nil
+	for i := 0; i < 50; i++ {
+		h.URLs = append(h.URLs, fmt.Sprintf("%s/%d", fakeServer.URL, i))
+	}
+	h.StaggerWindow.Duration = 500 * time.Millisecond
+	h.StaggerMode = "random"
+	arrivals = nil
+	start := time.Now()
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 54)
+	require.Len(t, arrivals, 50)
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i].Before(arrivals[j]) })
+	require.True(t, arrivals[0].Sub(start) < 150*time.Millisecond)
+	require.True(t, arrivals[25].Sub(start) < 400*time.Millisecond)
+	require.True(t, arrivals[49].Sub(start) > 350*time.Millisecond)
+
+	h.StaggerMode = "sometimes"
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestURLTemplate(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	// the hosts are paths of the fake server, so that all URLs are served
+	h := &plugin.HTTP{
+		Hosts:       []string{"app-01", "app-02"},
+		URLTemplate: fakeServer.URL + "/{host}/metrics",
+		HostTag:     "source",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	for _, host := range []string{"app-01", "app-02"} {
+		require.True(t, acc.HasPoint("metricName",
+			map[string]string{"url": fakeServer.URL + "/" + host + "/metrics", "source": host}, "a", 1.2))
+	}
+
+	h.URLTemplate = fakeServer.URL + "/metrics"
+	require.Error(t, acc.GatherError(h.Gather))
+}
+
+func TestEmptyResponse(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/nocontent" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, _ = w.Write([]byte(" \n"))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs: []string{fakeServer.URL + "/nocontent", fakeServer.URL + "/empty"},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 0)
+
+	h.EmptyResponseMarker = true
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.True(t, acc.HasPoint("http_up",
+		map[string]string{"url": fakeServer.URL + "/nocontent"}, "up", 1))
+
+	acc = testutil.Accumulator{}
+	h.StrictEmptyResponse = true
+	err := acc.GatherError(h.Gather)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Received status code 204")
+	require.Len(t, acc.Metrics, 0)
+}
+
+func TestEndpointPaths(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metrics" && r.URL.Path != "/admin/metrics" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		Endpoints: []plugin.Endpoint{
+			{URL: fakeServer.URL + "/", Paths: []string{"/metrics", "admin/metrics"}},
+		},
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.True(t, acc.HasPoint("metricName",
+		map[string]string{"url": fakeServer.URL + "/metrics", "registry": "/metrics"}, "a", 1.2))
+	require.True(t, acc.HasPoint("metricName",
+		map[string]string{"url": fakeServer.URL + "/admin/metrics", "registry": "admin/metrics"}, "a", 1.2))
+}
+
+func TestExpireAfter(t *testing.T) {
+	response := `[{"name": "a", "value": 1}, {"name": "b", "value": 2}]`
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(response))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:        []string{fakeServer.URL},
+		ExpireAfter: internal.Duration{Duration: 100 * time.Millisecond},
+	}
+	p, _ := parsers.NewJSONParser("metricName", []string{"name"}, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+
+	// b is removed, it is reported once it expired
+	response = `[{"name": "a", "value": 1}]`
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+
+	time.Sleep(150 * time.Millisecond)
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 2)
+	require.True(t, acc.HasPoint("metricName",
+		map[string]string{"url": fakeServer.URL, "name": "b"}, "expired", true))
+
+	// the tombstone is reported once
+	acc.ClearMetrics()
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 1)
+}
+
 const simpleJSON = `
 {
     "a": 1.2
 }
 `
+
+func TestEndpointNameFilter(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("requests value=1\nrequests.debug value=2\njvm value=3\n"))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs: []string{fakeServer.URL + "/all"},
+		Endpoints: []plugin.Endpoint{
+			{
+				URL:      fakeServer.URL + "/filtered",
+				NamePass: []string{"requests*"},
+				NameDrop: []string{"requests.debug*"},
+			},
+		},
+	}
+	p, _ := parsers.NewInfluxParser()
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, acc.GatherError(h.Gather))
+	require.Len(t, acc.Metrics, 4)
+	require.True(t, acc.HasPoint("requests", map[string]string{"url": fakeServer.URL + "/all"}, "value", float64(1)))
+	require.True(t, acc.HasPoint("requests.debug", map[string]string{"url": fakeServer.URL + "/all"}, "value", float64(2)))
+	require.True(t, acc.HasPoint("jvm", map[string]string{"url": fakeServer.URL + "/all"}, "value", float64(3)))
+	require.True(t, acc.HasPoint("requests", map[string]string{"url": fakeServer.URL + "/filtered"}, "value", float64(1)))
+
+	h.Endpoints[0].NamePass = []string{"["}
+	require.Error(t, h.Gather(&acc))
+}
+
+func TestUpMetric(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(simpleJSON))
+	}))
+	defer fakeServer.Close()
+
+	h := &plugin.HTTP{
+		URLs:     []string{fakeServer.URL + "/up", fakeServer.URL + "/down"},
+		UpMetric: "dropwizard_up",
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	h.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.Error(t, acc.GatherError(h.Gather))
+	require.True(t, acc.HasPoint("dropwizard_up",
+		map[string]string{"url": fakeServer.URL + "/up"}, "up", 1))
+	require.True(t, acc.HasPoint("dropwizard_up",
+		map[string]string{"url": fakeServer.URL + "/down"}, "up", 0))
+}
+
+func TestInit(t *testing.T) {
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	newHTTP := func() *plugin.HTTP {
+		h := &plugin.HTTP{URLs: []string{"http://localhost/metrics"}}
+		h.SetParser(p)
+		return h
+	}
+
+	h := newHTTP()
+	require.NoError(t, h.Init())
+
+	tests := map[string]func(h *plugin.HTTP){
+		"relative url":      func(h *plugin.HTTP) { h.URLs = []string{"localhost/metrics"} },
+		"scheme":            func(h *plugin.HTTP) { h.URLs = []string{"ftp://localhost/metrics"} },
+		"endpoint url":      func(h *plugin.HTTP) { h.Endpoints = []plugin.Endpoint{{URL: "http://"}} },
+		"url template":      func(h *plugin.HTTP) { h.Hosts = []string{"a"}; h.URLTemplate = "http://a/metrics" },
+		"cookie auth url":   func(h *plugin.HTTP) { h.CookieAuthURL = "/login" },
+		"auth method":       func(h *plugin.HTTP) { h.AuthMethod = "ntlm" },
+		"ssl key":           func(h *plugin.HTTP) { h.SSLCert = "/etc/telegraf/cert.pem" },
+		"missing ssl files": func(h *plugin.HTTP) { h.SSLCert = "/nonexistent/cert.pem"; h.SSLKey = "/nonexistent/key.pem" },
+		"http versions":     func(h *plugin.HTTP) { h.EnableHTTP2 = true; h.ForceHTTP1 = true },
+		"proxy url":         func(h *plugin.HTTP) { h.ProxyURL = "ftp://localhost" },
+		"stagger mode":      func(h *plugin.HTTP) { h.StaggerMode = "burst" },
+		"aggregate":         func(h *plugin.HTTP) { h.Aggregate = "both" },
+		"namepass":          func(h *plugin.HTTP) { h.Endpoints = []plugin.Endpoint{{URL: "http://a", NamePass: []string{"["}}} },
+	}
+	for name, configure := range tests {
+		h := newHTTP()
+		configure(h)
+		require.Error(t, h.Init(), name)
+	}
+
+	h = &plugin.HTTP{URLs: []string{"http://localhost/metrics"}}
+	require.Error(t, h.Init())
+}
+
+func TestHTTPDebugLog(t *testing.T) {
+	fakeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/endpoint" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(simpleJSON))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer fakeServer.Close()
+
+	log := &testutil.Logger{}
+	url := strings.Replace(fakeServer.URL, "http://", "http://user:secret@", 1)
+	plugin := &plugin.HTTP{
+		URLs: []string{url + "/endpoint", url + "/missing"},
+		Log:  log,
+	}
+	p, _ := parsers.NewJSONParser("metricName", nil, nil)
+	plugin.SetParser(p)
+
+	var acc testutil.Accumulator
+	require.NoError(t, plugin.Gather(&acc))
+
+	redacted := strings.Replace(fakeServer.URL, "http://", "http://user:xxxxx@", 1)
+	lines := strings.Join(log.Lines, "\n")
+	require.Len(t, log.Lines, 2)
+	require.Contains(t, lines, "D! GET "+redacted+"/endpoint: 200 OK, 18 bytes of application/json in ")
+	require.Contains(t, lines, "D! GET "+redacted+"/missing: 404 Not Found in ")
+	require.NotContains(t, lines, "secret")
+}
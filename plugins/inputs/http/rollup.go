@@ -0,0 +1,181 @@
+package http
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// rollup combines the metrics with the same name and tags, apart from the
+// tags identifying the URL they were gathered from, gathered from all URLs
+// during a single gather.
+type rollup struct {
+	sync.Mutex
+	gauges      string
+	percentiles string
+
+	series map[string]*rollupSeries
+	keys   []string
+}
+
+type rollupSeries struct {
+	name   string
+	tags   map[string]string
+	time   time.Time
+	fields map[string]*rollupField
+}
+
+type rollupField struct {
+	sum   float64
+	min   float64
+	max   float64
+	n     int
+	isInt bool
+}
+
+func newRollup(gauges, percentiles string) (*rollup, error) {
+	if gauges == "" {
+		gauges = "mean"
+	}
+	if percentiles == "" {
+		percentiles = "max"
+	}
+	for _, policy := range []string{gauges, percentiles} {
+		if policy != "mean" && policy != "max" {
+			return nil, fmt.Errorf("invalid aggregation %q, must be mean or max", policy)
+		}
+	}
+	return &rollup{
+		gauges:      gauges,
+		percentiles: percentiles,
+		series:      make(map[string]*rollupSeries),
+	}, nil
+}
+
+// add accounts for a metric gathered from one URL, non numeric fields are
+// ignored. The instanceTags, added by the input to identify the URL, are
+// left out of the series.
+func (r *rollup) add(m telegraf.Metric, t time.Time, instanceTags []string) {
+	tags := m.Tags()
+	for _, k := range instanceTags {
+		delete(tags, k)
+	}
+	key := seriesKey(m.Name(), tags)
+
+	r.Lock()
+	defer r.Unlock()
+
+	s, ok := r.series[key]
+	if !ok {
+		s = &rollupSeries{
+			name:   m.Name(),
+			tags:   tags,
+			fields: make(map[string]*rollupField),
+		}
+		r.series[key] = s
+		r.keys = append(r.keys, key)
+	}
+	if t.After(s.time) {
+		s.time = t
+	}
+
+	for name, value := range m.Fields() {
+		var v float64
+		var isInt bool
+		switch value := value.(type) {
+		case float64:
+			v = value
+		case int64:
+			v, isInt = float64(value), true
+		case uint64:
+			v, isInt = float64(value), true
+		default:
+			continue
+		}
+
+		f, ok := s.fields[name]
+		if !ok {
+			s.fields[name] = &rollupField{sum: v, min: v, max: v, n: 1, isInt: isInt}
+			continue
+		}
+		f.sum += v
+		if v < f.min {
+			f.min = v
+		}
+		if v > f.max {
+			f.max = v
+		}
+		f.n++
+		f.isInt = f.isInt && isInt
+	}
+}
+
+// emit adds the combined metrics tagged with aggregated=true.
+func (r *rollup) emit(acc telegraf.Accumulator) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, key := range r.keys {
+		s := r.series[key]
+		if len(s.fields) == 0 {
+			continue
+		}
+		fields := make(map[string]interface{}, len(s.fields))
+		for name, f := range s.fields {
+			v := r.combine(s.tags["metric_type"], name, f)
+			if f.isInt {
+				fields[name] = int64(v)
+			} else {
+				fields[name] = v
+			}
+		}
+		tags := make(map[string]string, len(s.tags)+1)
+		for k, v := range s.tags {
+			tags[k] = v
+		}
+		tags["aggregated"] = "true"
+		acc.AddFields(s.name, fields, tags, s.time)
+	}
+}
+
+// combine returns the fleet level value of a field: counts and rates are
+// summed, minimums and maximums are kept, gauges and the other statistics,
+// ie, percentiles, are combined according to the configured policy.
+func (r *rollup) combine(metricType, name string, f *rollupField) float64 {
+	switch {
+	case name == "count" || strings.HasSuffix(name, "_rate"):
+		return f.sum
+	case name == "min":
+		return f.min
+	case name == "max":
+		return f.max
+	}
+
+	policy := r.percentiles
+	if metricType == "" || metricType == "gauge" {
+		policy = r.gauges
+	}
+	if policy == "max" {
+		return f.max
+	}
+	return f.sum / float64(f.n)
+}
+
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf []string
+	buf = append(buf, name)
+	for _, k := range keys {
+		buf = append(buf, k+"="+tags[k])
+	}
+	return strings.Join(buf, ",")
+}
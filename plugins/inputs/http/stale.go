@@ -0,0 +1,69 @@
+package http
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+)
+
+// staleTracker remembers when the value of each gauge of each URL last
+// changed, to drop the gauges that did not change for a while.
+type staleTracker struct {
+	sync.Mutex
+	after  time.Duration
+	series map[string]map[string]*gaugeState
+}
+
+type gaugeState struct {
+	fields  map[string]interface{}
+	changed time.Time
+}
+
+func newStaleTracker(after time.Duration) *staleTracker {
+	return &staleTracker{
+		after:  after,
+		series: make(map[string]map[string]*gaugeState),
+	}
+}
+
+// filter returns the metrics of the URL without the stale gauges, along with
+// the number of gauges and the number of stale gauges. Gauges are the
+// metrics tagged with metric_type=gauge.
+func (s *staleTracker) filter(
+	url string,
+	metrics []telegraf.Metric,
+	now time.Time,
+) ([]telegraf.Metric, int, int) {
+	s.Lock()
+	defer s.Unlock()
+
+	last := s.series[url]
+	current := make(map[string]*gaugeState)
+	kept := metrics[:0]
+	var gauges, stale int
+	for _, m := range metrics {
+		if m.Tags()["metric_type"] != "gauge" {
+			kept = append(kept, m)
+			continue
+		}
+		gauges++
+
+		key := seriesKey(m.Name(), m.Tags())
+		fields := m.Fields()
+		state, ok := last[key]
+		if !ok || !reflect.DeepEqual(state.fields, fields) {
+			state = &gaugeState{fields: fields, changed: now}
+		}
+		current[key] = state
+
+		if now.Sub(state.changed) >= s.after {
+			stale++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	s.series[url] = current
+	return kept, gauges, stale
+}
@@ -10,12 +10,14 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
 	"github.com/influxdata/telegraf/plugins/inputs"
+	"github.com/influxdata/telegraf/plugins/parsers"
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/selfstat"
 )
@@ -44,14 +46,27 @@ type HTTPListener struct {
 	TlsCert           string
 	TlsKey            string
 
+	TenantTag          string            `toml:"tenant_tag"`
+	TenantPointsPerSec float64           `toml:"tenant_points_per_second"`
+	TenantMaxSeries    int               `toml:"tenant_max_series"`
+	TenantSeriesWindow internal.Duration `toml:"tenant_series_window"`
+
+	// Tag holding the source of the metrics, taken from the SourceHeader
+	// header or from the path following /write/
+	SourceTag    string `toml:"source_tag"`
+	SourceHeader string `toml:"source_header"`
+
 	mu sync.Mutex
 	wg sync.WaitGroup
 
 	listener net.Listener
 
 	parser influx.InfluxParser
-	acc    telegraf.Accumulator
+	// parser of the data_format, when it is not influx
+	formatParser parsers.Parser
+	acc          telegraf.Accumulator
 	pool   *pool
+	quota  *tenantQuota
 
 	BytesRecv       selfstat.Stat
 	RequestsServed  selfstat.Stat
@@ -64,6 +79,7 @@ type HTTPListener struct {
 	PingsRecv       selfstat.Stat
 	NotFoundsServed selfstat.Stat
 	BuffersCreated  selfstat.Stat
+	PointsRejected  selfstat.Stat
 }
 
 const sampleConfig = `
@@ -90,6 +106,31 @@ const sampleConfig = `
   ## Add service certificate and key
   tls_cert = "/etc/telegraf/cert.pem"
   tls_key = "/etc/telegraf/key.pem"
+
+  ## Optional per tenant quotas, tenants are identified by the value of
+  ## tenant_tag, points without the tag share a single quota.  When a tenant
+  ## is over its quota the points are dropped and a 429 is returned.
+  # tenant_tag = "team"
+  ## Maximum sustained rate of points per second, 0 is unlimited.
+  # tenant_points_per_second = 0.0
+  ## Maximum number of distinct series within tenant_series_window, 0 is
+  ## unlimited.
+  # tenant_max_series = 0
+  # tenant_series_window = "1h"
+
+  ## Tag holding the source of the metrics, taken from the source_header
+  ## header of the requests or from the path of the requests to
+  ## /write/<source>, ie, /write/billing.
+  # source_tag = "source"
+  # source_header = "X-Source"
+
+  ## Data format of the request bodies, the bodies of any other format than
+  ## "influx" are parsed as a whole, ie, the registries posted by the
+  ## dropwizard HTTP reporters with data_format = "dropwizard".
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  # data_format = "influx"
 `
 
 func (h *HTTPListener) SampleConfig() string {
@@ -100,6 +141,16 @@ func (h *HTTPListener) Description() string {
 	return "Influx HTTP write listener"
 }
 
+// SetParser sets the parser of the data_format, the influx line protocol
+// being parsed by the listener itself, line by line.
+func (h *HTTPListener) SetParser(parser parsers.Parser) {
+	if _, ok := parser.(*influx.InfluxParser); ok {
+		h.formatParser = nil
+		return
+	}
+	h.formatParser = parser
+}
+
 func (h *HTTPListener) Gather(_ telegraf.Accumulator) error {
 	h.BuffersCreated.Set(h.pool.ncreated())
 	return nil
@@ -124,6 +175,7 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 	h.PingsRecv = selfstat.Register("http_listener", "pings_received", tags)
 	h.NotFoundsServed = selfstat.Register("http_listener", "not_founds_served", tags)
 	h.BuffersCreated = selfstat.Register("http_listener", "buffers_created", tags)
+	h.PointsRejected = selfstat.Register("http_listener", "points_rejected", tags)
 
 	if h.MaxBodySize == 0 {
 		h.MaxBodySize = DEFAULT_MAX_BODY_SIZE
@@ -139,6 +191,14 @@ func (h *HTTPListener) Start(acc telegraf.Accumulator) error {
 		h.WriteTimeout.Duration = time.Second * 10
 	}
 
+	if h.TenantSeriesWindow.Duration == 0 {
+		h.TenantSeriesWindow.Duration = time.Hour
+	}
+	if h.TenantTag != "" && (h.TenantPointsPerSec > 0 || h.TenantMaxSeries > 0) {
+		h.quota = newTenantQuota(h.TenantPointsPerSec, h.TenantMaxSeries,
+			h.TenantSeriesWindow.Duration)
+	}
+
 	h.acc = acc
 	h.pool = NewPool(200, h.MaxLineSize)
 
@@ -190,11 +250,19 @@ func (h *HTTPListener) Stop() {
 func (h *HTTPListener) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 	h.RequestsRecv.Incr(1)
 	defer h.RequestsServed.Incr(1)
-	switch req.URL.Path {
+	path := req.URL.Path
+	if strings.HasPrefix(path, "/write/") {
+		path = "/write"
+	}
+	switch path {
 	case "/write":
 		h.WritesRecv.Incr(1)
 		defer h.WritesServed.Incr(1)
-		h.serveWrite(res, req)
+		if h.formatParser != nil {
+			h.serveFormat(res, req)
+		} else {
+			h.serveWrite(res, req)
+		}
 	case "/query":
 		h.QueriesRecv.Incr(1)
 		defer h.QueriesServed.Incr(1)
@@ -225,6 +293,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	now := time.Now()
 
 	precision := req.URL.Query().Get("precision")
+	source := h.source(req)
 
 	// Handle gzip request bodies
 	body := req.Body
@@ -241,6 +310,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	body = http.MaxBytesReader(res, body, h.MaxBodySize)
 
 	var return400 bool
+	var return429 bool
 	var hangingBytes bool
 	buf := h.pool.get()
 	defer h.pool.put(buf)
@@ -256,11 +326,7 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 		h.BytesRecv.Incr(int64(n))
 
 		if err == io.EOF {
-			if return400 {
-				badRequest(res)
-			} else {
-				res.WriteHeader(http.StatusNoContent)
-			}
+			respond(res, return400, return429)
 			return
 		}
 
@@ -282,15 +348,13 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 
 		if err == io.ErrUnexpectedEOF {
 			// finished reading the request body
-			if err := h.parse(buf[:n+bufStart], now, precision); err != nil {
+			rejected, err := h.parse(buf[:n+bufStart], now, precision, source)
+			if err != nil {
 				log.Println("E! " + err.Error())
 				return400 = true
 			}
-			if return400 {
-				badRequest(res)
-			} else {
-				res.WriteHeader(http.StatusNoContent)
-			}
+			return429 = return429 || rejected
+			respond(res, return400, return429)
 			return
 		}
 
@@ -307,10 +371,12 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 			bufStart = 0
 			continue
 		}
-		if err := h.parse(buf[:i+1], now, precision); err != nil {
+		rejected, err := h.parse(buf[:i+1], now, precision, source)
+		if err != nil {
 			log.Println("E! " + err.Error())
 			return400 = true
 		}
+		return429 = return429 || rejected
 		// rotate the bit remaining after the last newline to the front of the buffer
 		i++ // start copying after the newline
 		bufStart = len(buf) - i
@@ -320,14 +386,93 @@ func (h *HTTPListener) serveWrite(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (h *HTTPListener) parse(b []byte, t time.Time, precision string) error {
+// serveFormat parses the whole request body with the parser of the
+// data_format.
+func (h *HTTPListener) serveFormat(res http.ResponseWriter, req *http.Request) {
+	if req.ContentLength > h.MaxBodySize {
+		tooLarge(res)
+		return
+	}
+
+	body := req.Body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		var err error
+		body, err = gzip.NewReader(req.Body)
+		if err != nil {
+			log.Println("E! " + err.Error())
+			badRequest(res)
+			return
+		}
+		defer body.Close()
+	}
+	body = http.MaxBytesReader(res, body, h.MaxBodySize)
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		log.Println("E! " + err.Error())
+		badRequest(res)
+		return
+	}
+	h.BytesRecv.Incr(int64(len(b)))
+
+	metrics, err := h.formatParser.Parse(b)
+	if err != nil {
+		log.Println("E! " + err.Error())
+		badRequest(res)
+		return
+	}
+	rejected := h.add(metrics, h.source(req))
+	respond(res, false, rejected)
+}
+
+// source returns the source of the metrics of the request, from the
+// source_header header or from the path following /write/.
+func (h *HTTPListener) source(req *http.Request) string {
+	if h.SourceTag == "" {
+		return ""
+	}
+	if h.SourceHeader != "" {
+		if source := req.Header.Get(h.SourceHeader); source != "" {
+			return source
+		}
+	}
+	return strings.Trim(strings.TrimPrefix(req.URL.Path, "/write"), "/")
+}
+
+// parse adds the metrics in b to the accumulator and reports whether any of
+// them were dropped for exceeding a tenant quota.
+func (h *HTTPListener) parse(b []byte, t time.Time, precision string, source string) (bool, error) {
 	metrics, err := h.parser.ParseWithDefaultTimePrecision(b, t, precision)
+	return h.add(metrics, source), err
+}
 
+// add adds the metrics to the accumulator, tagged with their source, and
+// reports whether any of them were dropped for exceeding a tenant quota.
+func (h *HTTPListener) add(metrics []telegraf.Metric, source string) bool {
+	var rejected bool
 	for _, m := range metrics {
+		if source != "" {
+			m.AddTag(h.SourceTag, source)
+		}
+		if h.quota != nil && !h.quota.allow(m.Tags()[h.TenantTag], m.HashID()) {
+			h.PointsRejected.Incr(1)
+			rejected = true
+			continue
+		}
 		h.acc.AddFields(m.Name(), m.Fields(), m.Tags(), m.Time())
 	}
+	return rejected
+}
 
-	return err
+func respond(res http.ResponseWriter, return400, return429 bool) {
+	switch {
+	case return400:
+		badRequest(res)
+	case return429:
+		tooManyRequests(res)
+	default:
+		res.WriteHeader(http.StatusNoContent)
+	}
 }
 
 func tooLarge(res http.ResponseWriter) {
@@ -344,6 +489,13 @@ func badRequest(res http.ResponseWriter) {
 	res.Write([]byte(`{"error":"http: bad request"}`))
 }
 
+func tooManyRequests(res http.ResponseWriter) {
+	res.Header().Set("Content-Type", "application/json")
+	res.Header().Set("X-Influxdb-Version", "1.0")
+	res.WriteHeader(http.StatusTooManyRequests)
+	res.Write([]byte(`{"error":"http: tenant quota exceeded"}`))
+}
+
 func (h *HTTPListener) getTLSConfig() *tls.Config {
 	tlsConf := &tls.Config{
 		InsecureSkipVerify: false,
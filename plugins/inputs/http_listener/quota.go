@@ -0,0 +1,141 @@
+package http_listener
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultMaxTenants is the number of tenants tracked at once, the points of
+// the tenants above it are dropped until idle tenants are evicted.
+const defaultMaxTenants = 10000
+
+// tenantQuota limits the rate of points and the number of series accepted
+// per tenant. Tenants are identified by the value of a tag.
+type tenantQuota struct {
+	// PointsPerSecond is the sustained rate of points accepted per tenant,
+	// bursts of up to one second worth of points, and at least of one
+	// point, are allowed.
+	PointsPerSecond float64
+	// MaxSeries is the number of distinct series accepted per tenant within
+	// SeriesWindow, which starts with the first point of each tenant.
+	MaxSeries    int
+	SeriesWindow time.Duration
+	// MaxTenants is the number of tenants tracked at once. Tenants without
+	// points for longer than their quotas last are evicted.
+	MaxTenants int
+
+	mu        sync.Mutex
+	tenants   map[string]*tenant
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type tenant struct {
+	tokens      float64
+	last        time.Time
+	lastSeen    time.Time
+	series      map[uint64]bool
+	windowStart time.Time
+}
+
+func newTenantQuota(pointsPerSecond float64, maxSeries int, seriesWindow time.Duration) *tenantQuota {
+	return &tenantQuota{
+		PointsPerSecond: pointsPerSecond,
+		MaxSeries:       maxSeries,
+		SeriesWindow:    seriesWindow,
+		MaxTenants:      defaultMaxTenants,
+		tenants:         make(map[string]*tenant),
+		now:             time.Now,
+	}
+}
+
+// allow returns true if a point of the given series is within the quota of
+// the tenant, and accounts for it.
+func (q *tenantQuota) allow(name string, seriesID uint64) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	if idle, ok := q.idleTimeout(); ok && now.Sub(q.lastSweep) >= idle {
+		q.sweep(now, idle)
+	}
+
+	t, ok := q.tenants[name]
+	if !ok {
+		if len(q.tenants) >= q.MaxTenants {
+			return false
+		}
+		t = &tenant{
+			tokens:      q.burst(),
+			last:        now,
+			series:      make(map[uint64]bool),
+			windowStart: now,
+		}
+		q.tenants[name] = t
+	}
+	t.lastSeen = now
+
+	if q.SeriesWindow > 0 && now.Sub(t.windowStart) >= q.SeriesWindow {
+		t.series = make(map[uint64]bool)
+		t.windowStart = now
+	}
+
+	if q.MaxSeries > 0 && !t.series[seriesID] && len(t.series) >= q.MaxSeries {
+		return false
+	}
+
+	if q.PointsPerSecond > 0 {
+		t.tokens += now.Sub(t.last).Seconds() * q.PointsPerSecond
+		if burst := q.burst(); t.tokens > burst {
+			t.tokens = burst
+		}
+		t.last = now
+		if t.tokens < 1 {
+			return false
+		}
+		t.tokens--
+	}
+
+	if q.MaxSeries > 0 {
+		t.series[seriesID] = true
+	}
+	return true
+}
+
+// burst returns the maximum number of points accepted at once, one second
+// worth of points but at least one point, so that rates below one point per
+// second accept a point every 1/PointsPerSecond seconds.
+func (q *tenantQuota) burst() float64 {
+	if q.PointsPerSecond < 1 {
+		return 1
+	}
+	return q.PointsPerSecond
+}
+
+// idleTimeout returns how long a tenant goes without points before it is
+// evicted: its series window is over and its bucket full again by then, so
+// that its quota is the one of a new tenant. The returned bool is false if
+// the series are never reset, in which case no tenant is evicted.
+func (q *tenantQuota) idleTimeout() (time.Duration, bool) {
+	if q.MaxSeries > 0 && q.SeriesWindow <= 0 {
+		return 0, false
+	}
+	idle := q.SeriesWindow
+	if q.PointsPerSecond > 0 {
+		refill := time.Duration(q.burst() / q.PointsPerSecond * float64(time.Second))
+		if refill > idle {
+			idle = refill
+		}
+	}
+	return idle, true
+}
+
+// sweep evicts the tenants without points for the idle timeout.
+func (q *tenantQuota) sweep(now time.Time, idle time.Duration) {
+	for name, t := range q.tenants {
+		if now.Sub(t.lastSeen) >= idle {
+			delete(q.tenants, name)
+		}
+	}
+	q.lastSweep = now
+}
@@ -74,7 +74,7 @@ var sampleConfig = `
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
 
-  ## Optional SASL Config
+  ## Optional SASL Config, only the PLAIN mechanism is supported
   # sasl_username = "kafka"
   # sasl_password = "secret"
 
@@ -133,6 +133,9 @@ func (k *Kafka) Start(acc telegraf.Accumulator) error {
 		config.Net.SASL.User = k.SASLUsername
 		config.Net.SASL.Password = k.SASLPassword
 		config.Net.SASL.Enable = true
+	} else if k.SASLUsername != "" || k.SASLPassword != "" {
+		log.Printf("W! Kafka consumer SASL auth disabled, both sasl_username " +
+			"and sasl_password must be set")
 	}
 
 	switch strings.ToLower(k.Offset) {
@@ -12,10 +12,15 @@ import (
 )
 
 const (
-	testMsg         = "cpu_load_short,host=server01 value=23422.0 1422568543702900257\n"
-	testMsgGraphite = "cpu.load.short.graphite 23422 1454780029"
-	testMsgJSON     = "{\"a\": 5, \"b\": {\"c\": 6}}\n"
-	invalidMsg      = "cpu_load_short,host=server01 1422568543702900257\n"
+	testMsg           = "cpu_load_short,host=server01 value=23422.0 1422568543702900257\n"
+	testMsgGraphite   = "cpu.load.short.graphite 23422 1454780029"
+	testMsgJSON       = "{\"a\": 5, \"b\": {\"c\": 6}}\n"
+	invalidMsg        = "cpu_load_short,host=server01 1422568543702900257\n"
+	testMsgDropwizard = `{
+		"version": "3.0.0",
+		"counters": {"jobs.queued": {"count": 12}},
+		"gauges": {"jobs.workers": {"value": 4}}
+	}`
 )
 
 func newTestKafka() (*Kafka, chan *sarama.ConsumerMessage) {
@@ -140,6 +145,28 @@ func TestRunParserAndGatherJSON(t *testing.T) {
 		})
 }
 
+// Test that the parser parses dropwizard JSON messages into points
+func TestRunParserAndGatherDropwizard(t *testing.T) {
+	k, in := newTestKafka()
+	acc := testutil.Accumulator{}
+	k.acc = &acc
+	defer close(k.done)
+
+	k.parser, _ = parsers.NewParser(&parsers.Config{DataFormat: "dropwizard"})
+	go k.receiver()
+	in <- saramaMsg(testMsgDropwizard)
+	acc.Wait(2)
+
+	acc.GatherError(k.Gather)
+
+	acc.AssertContainsTaggedFields(t, "jobs.queued",
+		map[string]interface{}{"count": float64(12)},
+		map[string]string{"metric_type": "counter"})
+	acc.AssertContainsTaggedFields(t, "jobs.workers",
+		map[string]interface{}{"value": int64(4)},
+		map[string]string{"metric_type": "gauge"})
+}
+
 func saramaMsg(val string) *sarama.ConsumerMessage {
 	return &sarama.ConsumerMessage{
 		Key:       nil,
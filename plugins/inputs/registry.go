@@ -9,3 +9,11 @@ var Inputs = map[string]Creator{}
 func Add(name string, creator Creator) {
 	Inputs[name] = creator
 }
+
+// NamePassInput is an interface for input plugins that are able to filter
+// the metrics at their source, ie, by asking a server to only return the
+// metrics matching the namepass patterns of the plugin.
+type NamePassInput interface {
+	// SetNamePass sets the namepass patterns of the plugin
+	SetNamePass(namepass []string)
+}
@@ -2,6 +2,7 @@ package socket_listener
 
 import (
 	"bufio"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log"
@@ -26,7 +27,8 @@ type streamSocketListener struct {
 	net.Listener
 	*SocketListener
 
-	sockType string
+	sockType  string
+	tlsConfig *tls.Config
 
 	connections    map[string]net.Conn
 	connectionsMtx sync.Mutex
@@ -65,6 +67,10 @@ func (ssl *streamSocketListener) listen() {
 			ssl.AddError(fmt.Errorf("unable to configure keep alive (%s): %s", ssl.ServiceAddress, err))
 		}
 
+		if ssl.tlsConfig != nil {
+			c = tls.Server(c, ssl.tlsConfig)
+		}
+
 		go ssl.read(c)
 	}
 
@@ -165,6 +171,12 @@ type SocketListener struct {
 	ReadTimeout     *internal.Duration
 	KeepAlivePeriod *internal.Duration
 
+	// Set TLSCert and TLSKey to accept TLS connections, and
+	// TLSAllowedCACerts to require client certificates
+	TLSCert           string   `toml:"tls_cert"`
+	TLSKey            string   `toml:"tls_key"`
+	TLSAllowedCACerts []string `toml:"tls_allowed_cacerts"`
+
 	parsers.Parser
 	telegraf.Accumulator
 	io.Closer
@@ -210,6 +222,14 @@ func (sl *SocketListener) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Service certificate and key, to accept TLS connections.
+  ## Only applies to stream sockets (e.g. TCP).
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+  ## Set one or more allowed client CA certificate file names to
+  ## enable mutually authenticated TLS connections.
+  # tls_allowed_cacerts = ["/etc/telegraf/clientca.pem"]
+
   ## Data format to consume.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
@@ -240,6 +260,12 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 		os.Remove(spl[1])
 	}
 
+	tlsConfig, err := internal.GetServerTLSConfig(
+		sl.TLSCert, sl.TLSKey, sl.TLSAllowedCACerts)
+	if err != nil {
+		return err
+	}
+
 	switch spl[0] {
 	case "tcp", "tcp4", "tcp6", "unix", "unixpacket":
 		l, err := net.Listen(spl[0], spl[1])
@@ -251,11 +277,16 @@ func (sl *SocketListener) Start(acc telegraf.Accumulator) error {
 			Listener:       l,
 			SocketListener: sl,
 			sockType:       spl[0],
+			tlsConfig:      tlsConfig,
 		}
 
 		sl.Closer = ssl
 		go ssl.listen()
 	case "udp", "udp4", "udp6", "ip", "ip4", "ip6", "unixgram":
+		if tlsConfig != nil {
+			return fmt.Errorf("TLS is not supported on %s sockets", spl[0])
+		}
+
 		pc, err := net.ListenPacket(spl[0], spl[1])
 		if err != nil {
 			return err
@@ -5,6 +5,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -194,3 +195,27 @@ func validateFile(fname, expS string, t *testing.T) {
 	}
 	assert.Equal(t, expS, string(buf))
 }
+
+func TestFileRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, _ := serializers.NewInfluxSerializer()
+	f := File{
+		Files:               []string{filepath.Join(dir, "metrics.out")},
+		RotationMaxSize:     internal.Size{Size: int64(len(expNewFile))},
+		RotationMaxArchives: 1,
+		serializer:          s,
+	}
+	assert.NoError(t, f.Connect())
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, f.Write(testutil.MockMetrics()))
+	}
+	assert.NoError(t, f.Close())
+
+	files, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+	validateFile(filepath.Join(dir, "metrics.out"), expNewFile, t)
+}
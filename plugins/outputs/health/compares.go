@@ -0,0 +1,90 @@
+package health
+
+import (
+	"github.com/influxdata/telegraf"
+)
+
+// Compares is a check passing when all the values of a field compare to the
+// given thresholds, ie, with lt = 5000.0 the field must stay below 5000.
+// The metrics without the field are ignored.
+type Compares struct {
+	Field string   `toml:"field"`
+	GT    *float64 `toml:"gt"`
+	GE    *float64 `toml:"ge"`
+	LT    *float64 `toml:"lt"`
+	LE    *float64 `toml:"le"`
+	EQ    *float64 `toml:"eq"`
+}
+
+func (c *Compares) runChecks(fv float64) bool {
+	if c.GT != nil && !(fv > *c.GT) {
+		return false
+	}
+	if c.GE != nil && !(fv >= *c.GE) {
+		return false
+	}
+	if c.LT != nil && !(fv < *c.LT) {
+		return false
+	}
+	if c.LE != nil && !(fv <= *c.LE) {
+		return false
+	}
+	if c.EQ != nil && !(fv == *c.EQ) {
+		return false
+	}
+	return true
+}
+
+// Check returns false if a metric has a value of the field which does not
+// compare, or which is not a number.
+func (c *Compares) Check(metrics []telegraf.Metric) bool {
+	for _, m := range metrics {
+		fv, ok := m.Fields()[c.Field]
+		if !ok {
+			continue
+		}
+
+		f, ok := asFloat(fv)
+		if !ok {
+			return false
+		}
+
+		if !c.runChecks(f) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains is a check passing when at least one metric has the field.
+type Contains struct {
+	Field string `toml:"field"`
+}
+
+// Check returns true if a metric has the field.
+func (c *Contains) Check(metrics []telegraf.Metric) bool {
+	for _, m := range metrics {
+		if _, ok := m.Fields()[c.Field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func asFloat(fv interface{}) (float64, bool) {
+	switch v := fv.(type) {
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1.0, true
+		}
+		return 0.0, true
+	default:
+		return 0.0, false
+	}
+}
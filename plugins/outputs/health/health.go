@@ -0,0 +1,248 @@
+package health
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+)
+
+const (
+	defaultServiceAddress = ":8080"
+	defaultReadTimeout    = 5 * time.Second
+	defaultWriteTimeout   = 5 * time.Second
+)
+
+var sampleConfig = `
+  ## Address and port to listen on.
+  # service_address = ":8080"
+
+  ## The maximum duration for reading the entire request.
+  # read_timeout = "5s"
+  ## The maximum duration for writing the entire response.
+  # write_timeout = "5s"
+
+  ## Username and password to accept for HTTP basic authentication.
+  # basic_username = "user1"
+  # basic_password = "secret"
+
+  ## Use TLS
+  # tls_cert = "/etc/telegraf/cert.pem"
+  # tls_key = "/etc/telegraf/key.pem"
+
+  ## The output is unhealthy when it receives no metric for this long, ie,
+  ## when the inputs stopped gathering. 0 disables the check.
+  # max_time_between_metrics = "0s"
+
+  ## One or more check sub-tables should be defined, it is also recommended to
+  ## use metric filtering to limit the metrics that flow into this output.
+  ##
+  ## When using the default buffer sizes, this example will fail when the
+  ## metric buffer is half full.
+  ##
+  ## namepass = ["internal_write"]
+  ## tagpass = { output = ["influxdb"] }
+  ##
+  ## [[outputs.health.compares]]
+  ##   field = "buffer_size"
+  ##   lt = 5000.0
+  ##
+  ## [[outputs.health.contains]]
+  ##   field = "buffer_size"
+`
+
+type Health struct {
+	ServiceAddress        string            `toml:"service_address"`
+	ReadTimeout           internal.Duration `toml:"read_timeout"`
+	WriteTimeout          internal.Duration `toml:"write_timeout"`
+	BasicUsername         string            `toml:"basic_username"`
+	BasicPassword         string            `toml:"basic_password"`
+	TLSCert               string            `toml:"tls_cert"`
+	TLSKey                string            `toml:"tls_key"`
+	MaxTimeBetweenMetrics internal.Duration `toml:"max_time_between_metrics"`
+
+	Compares []*Compares `toml:"compares"`
+	Contains []*Contains `toml:"contains"`
+
+	server   *http.Server
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu sync.Mutex
+	// failed holds the description of the failed checks of the last write.
+	failed []string
+	// lastWrite is when metrics were last written, or the start time.
+	lastWrite time.Time
+	// now returns the current time, replaced in tests.
+	now func() time.Time
+}
+
+func (h *Health) SampleConfig() string {
+	return sampleConfig
+}
+
+func (h *Health) Description() string {
+	return "Configurable HTTP health check resource based on metrics"
+}
+
+// Start starts the HTTP server serving the health status.
+func (h *Health) Start() error {
+	if h.ServiceAddress == "" {
+		h.ServiceAddress = defaultServiceAddress
+	}
+
+	h.mu.Lock()
+	h.lastWrite = h.now()
+	h.mu.Unlock()
+
+	listener, err := net.Listen("tcp", h.ServiceAddress)
+	if err != nil {
+		return err
+	}
+	h.listener = listener
+
+	h.server = &http.Server{
+		Handler:      h,
+		ReadTimeout:  h.ReadTimeout.Duration,
+		WriteTimeout: h.WriteTimeout.Duration,
+	}
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		var err error
+		if h.TLSCert != "" && h.TLSKey != "" {
+			err = h.server.ServeTLS(listener, h.TLSCert, h.TLSKey)
+		} else {
+			err = h.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			log.Printf("E! Error serving health endpoint on %s: %s",
+				h.ServiceAddress, err)
+		}
+	}()
+
+	log.Printf("I! Started the health output service on %s", listener.Addr())
+	return nil
+}
+
+func (h *Health) Stop() {
+	// the server is shut down in Close.
+}
+
+func (h *Health) Connect() error {
+	// This service output does not need to make any further connections
+	return nil
+}
+
+func (h *Health) Close() error {
+	if h.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := h.server.Shutdown(ctx)
+	h.wg.Wait()
+	return err
+}
+
+// ServeHTTP answers with 200 when healthy, and with 503 and the failed
+// checks otherwise.
+func (h *Health) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !h.authorized(req) {
+		rw.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+		http.Error(rw, "Not authorized", http.StatusUnauthorized)
+		return
+	}
+
+	failed := h.status()
+	rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if len(failed) > 0 {
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(rw, "unhealthy: %s\n", strings.Join(failed, ", "))
+		return
+	}
+	rw.WriteHeader(http.StatusOK)
+	fmt.Fprintln(rw, "healthy")
+}
+
+func (h *Health) authorized(req *http.Request) bool {
+	if h.BasicUsername == "" && h.BasicPassword == "" {
+		return true
+	}
+	username, password, ok := req.BasicAuth()
+	return ok &&
+		subtle.ConstantTimeCompare([]byte(username), []byte(h.BasicUsername)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.BasicPassword)) == 1
+}
+
+// status returns the description of the failed checks.
+func (h *Health) status() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	failed := append([]string{}, h.failed...)
+	if max := h.MaxTimeBetweenMetrics.Duration; max > 0 {
+		if since := h.now().Sub(h.lastWrite); since > max {
+			failed = append(failed, fmt.Sprintf("no metrics for %s",
+				since.Truncate(time.Second)))
+		}
+	}
+	return failed
+}
+
+// Write runs the checks against the metrics.
+func (h *Health) Write(metrics []telegraf.Metric) error {
+	var failed []string
+	for _, c := range h.Compares {
+		if !c.Check(metrics) {
+			failed = append(failed, fmt.Sprintf("compares %s", c.Field))
+		}
+	}
+	for _, c := range h.Contains {
+		if !c.Check(metrics) {
+			failed = append(failed, fmt.Sprintf("contains %s", c.Field))
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failed = failed
+	h.lastWrite = h.now()
+	return nil
+}
+
+// Origin returns the URL of the HTTP server.
+func (h *Health) Origin() string {
+	if h.listener == nil {
+		return ""
+	}
+	scheme := "http"
+	if h.TLSCert != "" && h.TLSKey != "" {
+		scheme = "https"
+	}
+	return scheme + "://" + h.listener.Addr().String()
+}
+
+func NewHealth() *Health {
+	return &Health{
+		ServiceAddress: defaultServiceAddress,
+		ReadTimeout:    internal.Duration{Duration: defaultReadTimeout},
+		WriteTimeout:   internal.Duration{Duration: defaultWriteTimeout},
+		now:            time.Now,
+	}
+}
+
+func init() {
+	outputs.Add("health", func() telegraf.Output {
+		return NewHealth()
+	})
+}
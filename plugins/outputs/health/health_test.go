@@ -0,0 +1,142 @@
+package health
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/testutil"
+	"github.com/influxdata/toml"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestCompares(t *testing.T) {
+	tests := []struct {
+		name     string
+		compares *Compares
+		value    interface{}
+		expected bool
+	}{
+		{"lt", &Compares{Field: "value", LT: float(42)}, int64(41), true},
+		{"lt fails", &Compares{Field: "value", LT: float(42)}, int64(42), false},
+		{"le", &Compares{Field: "value", LE: float(42)}, 42.0, true},
+		{"gt", &Compares{Field: "value", GT: float(42)}, uint64(43), true},
+		{"ge fails", &Compares{Field: "value", GE: float(42)}, 41.5, false},
+		{"eq", &Compares{Field: "value", EQ: float(1)}, true, true},
+		{"range", &Compares{Field: "value", GT: float(0), LT: float(10)}, int64(10), false},
+		{"not a number", &Compares{Field: "value", LT: float(42)}, "41", false},
+		{"missing field", &Compares{Field: "other", LT: float(42)}, int64(43), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testutil.TestMetric(tt.value)
+			assert.Equal(t, tt.expected, tt.compares.Check([]telegraf.Metric{m}))
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	metrics := []telegraf.Metric{testutil.TestMetric(1)}
+	assert.True(t, (&Contains{Field: "value"}).Check(metrics))
+	assert.False(t, (&Contains{Field: "other"}).Check(metrics))
+}
+
+func TestConfig(t *testing.T) {
+	h := NewHealth()
+	err := toml.Unmarshal([]byte(`
+service_address = ":8081"
+max_time_between_metrics = "2m"
+[[compares]]
+  field = "buffer_size"
+  lt = 5000.0
+[[contains]]
+  field = "buffer_size"
+`), h)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, h.MaxTimeBetweenMetrics.Duration)
+	require.Len(t, h.Compares, 1)
+	assert.Equal(t, float(5000), h.Compares[0].LT)
+	assert.Nil(t, h.Compares[0].GT)
+	require.Len(t, h.Contains, 1)
+}
+
+func get(t *testing.T, h *Health) (int, string) {
+	resp, err := http.Get(h.Origin())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestHealth(t *testing.T) {
+	h := NewHealth()
+	h.ServiceAddress = "localhost:0"
+	h.Compares = []*Compares{{Field: "value", LT: float(5000)}}
+	require.NoError(t, h.Start())
+	defer h.Close()
+
+	status, body := get(t, h)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "healthy\n", body)
+
+	require.NoError(t, h.Write([]telegraf.Metric{testutil.TestMetric(int64(6000))}))
+	status, body = get(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "unhealthy: compares value\n", body)
+
+	require.NoError(t, h.Write([]telegraf.Metric{testutil.TestMetric(int64(10))}))
+	status, _ = get(t, h)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestHealthMaxTimeBetweenMetrics(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+	h := NewHealth()
+	h.now = func() time.Time { return now }
+	h.MaxTimeBetweenMetrics = internal.Duration{Duration: time.Minute}
+	h.ServiceAddress = "localhost:0"
+	require.NoError(t, h.Start())
+	defer h.Close()
+
+	status, _ := get(t, h)
+	assert.Equal(t, http.StatusOK, status)
+
+	now = now.Add(2 * time.Minute)
+	status, body := get(t, h)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "unhealthy: no metrics for 2m0s\n", body)
+
+	require.NoError(t, h.Write([]telegraf.Metric{testutil.TestMetric(1)}))
+	status, _ = get(t, h)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestHealthBasicAuth(t *testing.T) {
+	h := NewHealth()
+	h.ServiceAddress = "localhost:0"
+	h.BasicUsername = "user"
+	h.BasicPassword = "secret"
+	require.NoError(t, h.Start())
+	defer h.Close()
+
+	status, _ := get(t, h)
+	assert.Equal(t, http.StatusUnauthorized, status)
+
+	req, err := http.NewRequest("GET", h.Origin(), nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("user", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,295 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/outputs"
+	"github.com/influxdata/telegraf/plugins/serializers"
+)
+
+const (
+	defaultURL             = "http://127.0.0.1:8080/telegraf"
+	defaultMethod          = http.MethodPost
+	defaultContentType     = "text/plain; charset=utf-8"
+	defaultClientTimeout   = 5 * time.Second
+	maxErrorResponseLength = 1024
+)
+
+var sampleConfig = `
+  ## URL is the address to send metrics to.  The URL may contain {tag}
+  ## placeholders, replaced by the value of the tag of each metric, the
+  ## metrics being sent in one request per resulting URL.  Metrics without
+  ## one of the tags are dropped.
+  url = "http://127.0.0.1:8080/telegraf"
+  # url = "https://ingest.example.com/{datacenter}/metrics"
+
+  ## Timeout for HTTP message
+  # timeout = "5s"
+
+  ## HTTP method, one of: "POST" or "PUT"
+  # method = "POST"
+
+  ## HTTP Basic Auth credentials
+  # username = "username"
+  # password = "pa$$word"
+
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+
+  ## HTTP Content-Encoding for write request body, can be set to "gzip" to
+  ## compress body or "identity" to apply no encoding.
+  # content_encoding = "identity"
+
+  ## Additional HTTP headers, ie, the Content-Type of the data format which
+  ## defaults to "text/plain; charset=utf-8".
+  # [outputs.http.headers]
+  #   Content-Type = "application/json"
+  #   X-Api-Key = "secret"
+
+  ## Data format to output.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
+  data_format = "influx"
+`
+
+// placeholder matches the {tag} placeholders of the URL.
+var placeholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
+type HTTP struct {
+	URL             string            `toml:"url"`
+	Timeout         internal.Duration `toml:"timeout"`
+	Method          string            `toml:"method"`
+	Username        string            `toml:"username"`
+	Password        string            `toml:"password"`
+	Headers         map[string]string `toml:"headers"`
+	ContentEncoding string            `toml:"content_encoding"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool
+
+	client     *http.Client
+	serializer serializers.Serializer
+}
+
+func (h *HTTP) SetSerializer(serializer serializers.Serializer) {
+	h.serializer = serializer
+}
+
+func (h *HTTP) Connect() error {
+	if h.Method == "" {
+		h.Method = defaultMethod
+	}
+	h.Method = strings.ToUpper(h.Method)
+	if h.Method != http.MethodPost && h.Method != http.MethodPut {
+		return fmt.Errorf("invalid method %q, must be POST or PUT", h.Method)
+	}
+
+	switch h.ContentEncoding {
+	case "", "identity", "gzip":
+	default:
+		return fmt.Errorf("invalid content_encoding %q, must be identity or gzip",
+			h.ContentEncoding)
+	}
+
+	if h.Timeout.Duration == 0 {
+		h.Timeout.Duration = defaultClientTimeout
+	}
+
+	tlsCfg, err := internal.GetTLSConfig(
+		h.SSLCert, h.SSLKey, h.SSLCA, h.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+
+	h.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsCfg,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: h.Timeout.Duration,
+	}
+	return nil
+}
+
+func (h *HTTP) Close() error {
+	return nil
+}
+
+func (h *HTTP) Description() string {
+	return "A plugin that can transmit metrics over HTTP"
+}
+
+func (h *HTTP) SampleConfig() string {
+	return sampleConfig
+}
+
+// Write serializes the metrics and sends them in one request per URL. The
+// requests to all the URLs are attempted, only the metrics of the failed
+// ones are returned in a PartialWriteError to be written again.
+func (h *HTTP) Write(metrics []telegraf.Metric) error {
+	batches := make(map[string]*bytes.Buffer)
+	batchMetrics := make(map[string][]telegraf.Metric)
+	for _, m := range metrics {
+		u, err := h.metricURL(m)
+		if err != nil {
+			log.Printf("E! [outputs.http] Dropping metric %s: %s", m.Name(), err)
+			continue
+		}
+		b, err := h.serializer.Serialize(m)
+		if err != nil {
+			return fmt.Errorf("failed to serialize message: %s", err)
+		}
+		batch, ok := batches[u]
+		if !ok {
+			batch = &bytes.Buffer{}
+			batches[u] = batch
+		}
+		batch.Write(b)
+		batchMetrics[u] = append(batchMetrics[u], m)
+	}
+
+	// send the batches in a stable order
+	urls := make([]string, 0, len(batches))
+	for u := range batches {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	var failed []telegraf.Metric
+	var errs []error
+	for _, u := range urls {
+		if err := h.write(u, batches[u].Bytes()); err != nil {
+			failed = append(failed, batchMetrics[u]...)
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &telegraf.PartialWriteError{
+		Err: fmt.Errorf("failed to write to %d of %d urls: %s",
+			len(errs), len(urls), errs[0]),
+		Failed: failed,
+	}
+}
+
+// metricURL returns the URL of the metric, with the {tag} placeholders
+// replaced by the values of its tags.
+func (h *HTTP) metricURL(m telegraf.Metric) (string, error) {
+	if !placeholder.MatchString(h.URL) {
+		return h.URL, nil
+	}
+	tags := m.Tags()
+	var missing string
+	u := placeholder.ReplaceAllStringFunc(h.URL, func(s string) string {
+		key := s[1 : len(s)-1]
+		value, ok := tags[key]
+		if !ok {
+			if missing == "" {
+				missing = key
+			}
+			return s
+		}
+		return url.PathEscape(value)
+	})
+	if missing != "" {
+		return "", fmt.Errorf("missing tag %q of url %s", missing, h.URL)
+	}
+	return u, nil
+}
+
+func (h *HTTP) write(u string, body []byte) error {
+	var reqBody io.Reader = bytes.NewReader(body)
+	if h.ContentEncoding == "gzip" {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		if _, err := gw.Write(body); err != nil {
+			return err
+		}
+		if err := gw.Close(); err != nil {
+			return err
+		}
+		reqBody = &buf
+	}
+
+	req, err := http.NewRequest(h.Method, u, reqBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", defaultContentType)
+	if h.ContentEncoding == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.Headers {
+		if strings.ToLower(k) == "host" {
+			req.Host = v
+		} else {
+			req.Header.Set(k, v)
+		}
+	}
+	if h.Username != "" || h.Password != "" {
+		req.SetBasicAuth(h.Username, h.Password)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		excerpt, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorResponseLength))
+		return fmt.Errorf("when writing to [%s] received status code: %d: %s",
+			redactURL(u), resp.StatusCode, strings.TrimSpace(string(excerpt)))
+	}
+	// drain the body so that the connection is reused
+	io.Copy(ioutil.Discard, resp.Body)
+	return nil
+}
+
+// redactURL returns the URL without its password.
+func redactURL(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.User == nil {
+		return u
+	}
+	if _, ok := parsed.User.Password(); ok {
+		parsed.User = url.UserPassword(parsed.User.Username(), "xxxxx")
+	}
+	return parsed.String()
+}
+
+func init() {
+	outputs.Add("http", func() telegraf.Output {
+		return &HTTP{
+			URL:     defaultURL,
+			Method:  defaultMethod,
+			Timeout: internal.Duration{Duration: defaultClientTimeout},
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package http
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/serializers"
+	"github.com/stretchr/testify/require"
+)
+
+// request is a request received by the test server.
+type request struct {
+	method   string
+	path     string
+	header   http.Header
+	body     string
+	username string
+	password string
+}
+
+type server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []request
+	status   int
+	// failPath is a path answered with a 500 status code
+	failPath string
+}
+
+func newServer(t *testing.T) *server {
+	s := &server{status: http.StatusNoContent}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		var err error
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gr, gerr := gzip.NewReader(r.Body)
+			require.NoError(t, gerr)
+			body, err = ioutil.ReadAll(gr)
+		} else {
+			body, err = ioutil.ReadAll(r.Body)
+		}
+		require.NoError(t, err)
+		username, password, _ := r.BasicAuth()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, request{
+			method:   r.Method,
+			path:     r.URL.Path,
+			header:   r.Header,
+			body:     string(body),
+			username: username,
+			password: password,
+		})
+		if r.URL.Path == s.failPath {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if s.status != http.StatusNoContent {
+			w.WriteHeader(s.status)
+			w.Write([]byte("invalid metrics\n"))
+			return
+		}
+		w.WriteHeader(s.status)
+	}))
+	return s
+}
+
+func newMetric(t *testing.T, name string, tags map[string]string) telegraf.Metric {
+	m, err := metric.New(name, tags, map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0))
+	require.NoError(t, err)
+	return m
+}
+
+func newHTTP(t *testing.T, url string) *HTTP {
+	h := &HTTP{URL: url}
+	s, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+	h.SetSerializer(s)
+	require.NoError(t, h.Connect())
+	return h
+}
+
+func TestWrite(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	h := &HTTP{
+		URL:      s.URL + "/telegraf",
+		Method:   "put",
+		Username: "user",
+		Password: "secret",
+		Headers:  map[string]string{"X-Api-Key": "key"},
+	}
+	serializer, err := serializers.NewInfluxSerializer()
+	require.NoError(t, err)
+	h.SetSerializer(serializer)
+	require.NoError(t, h.Connect())
+
+	require.NoError(t, h.Write([]telegraf.Metric{
+		newMetric(t, "cpu", nil),
+		newMetric(t, "mem", nil),
+	}))
+
+	require.Len(t, s.requests, 1)
+	r := s.requests[0]
+	require.Equal(t, "PUT", r.method)
+	require.Equal(t, "/telegraf", r.path)
+	require.Equal(t, "cpu value=42 0\nmem value=42 0\n", r.body)
+	require.Equal(t, "text/plain; charset=utf-8", r.header.Get("Content-Type"))
+	require.Equal(t, "key", r.header.Get("X-Api-Key"))
+	require.Equal(t, "user", r.username)
+	require.Equal(t, "secret", r.password)
+}
+
+func TestWriteGzip(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	h := newHTTP(t, s.URL)
+	h.ContentEncoding = "gzip"
+	require.NoError(t, h.Write([]telegraf.Metric{newMetric(t, "cpu", nil)}))
+
+	require.Len(t, s.requests, 1)
+	require.Equal(t, "gzip", s.requests[0].header.Get("Content-Encoding"))
+	require.Equal(t, "cpu value=42 0\n", s.requests[0].body)
+}
+
+func TestWriteURLTemplate(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	h := newHTTP(t, s.URL+"/{dc}/metrics")
+	require.NoError(t, h.Write([]telegraf.Metric{
+		newMetric(t, "cpu", map[string]string{"dc": "us-east"}),
+		newMetric(t, "cpu", map[string]string{"dc": "eu west"}),
+		newMetric(t, "mem", map[string]string{"dc": "us-east"}),
+		// dropped as it has no dc tag
+		newMetric(t, "disk", nil),
+	}))
+
+	require.Len(t, s.requests, 2)
+	bodies := map[string]string{}
+	var paths []string
+	for _, r := range s.requests {
+		bodies[r.path] = r.body
+		paths = append(paths, r.path)
+	}
+	sort.Strings(paths)
+	require.Equal(t, []string{"/eu west/metrics", "/us-east/metrics"}, paths)
+	require.Equal(t, "cpu,dc=us-east value=42 0\nmem,dc=us-east value=42 0\n",
+		bodies["/us-east/metrics"])
+	require.Equal(t, "cpu,dc=eu\\ west value=42 0\n", bodies["/eu west/metrics"])
+}
+
+func TestWriteStatusError(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.status = http.StatusBadRequest
+
+	h := newHTTP(t, s.URL)
+	err := h.Write([]telegraf.Metric{newMetric(t, "cpu", nil)})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "received status code: 400: invalid metrics")
+}
+
+func TestWritePartialFailure(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.failPath = "/eu-west/metrics"
+
+	h := newHTTP(t, s.URL+"/{dc}/metrics")
+	euWest := newMetric(t, "cpu", map[string]string{"dc": "eu-west"})
+	err := h.Write([]telegraf.Metric{
+		newMetric(t, "cpu", map[string]string{"dc": "us-east"}),
+		euWest,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to write to 1 of 2 urls")
+
+	// the request to the other URL is sent after the failure
+	require.Len(t, s.requests, 2)
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, []telegraf.Metric{euWest}, perr.Failed)
+}
+
+func TestConnectInvalidOptions(t *testing.T) {
+	h := &HTTP{URL: defaultURL, Method: "GET"}
+	require.Error(t, h.Connect())
+
+	h = &HTTP{URL: defaultURL, ContentEncoding: "br"}
+	require.Error(t, h.Connect())
+}
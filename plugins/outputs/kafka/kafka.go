@@ -3,6 +3,8 @@ package kafka
 import (
 	"crypto/tls"
 	"fmt"
+	"log"
+	"regexp"
 	"strings"
 
 	"github.com/influxdata/telegraf"
@@ -13,6 +15,9 @@ import (
 	"github.com/Shopify/sarama"
 )
 
+// keyPlaceholder matches the {tag} placeholders of the routing key.
+var keyPlaceholder = regexp.MustCompile(`\{([^{}]+)\}`)
+
 var ValidTopicSuffixMethods = []string{
 	"",
 	"measurement",
@@ -29,12 +34,16 @@ type (
 		TopicSuffix TopicSuffix `toml:"topic_suffix"`
 		// Routing Key Tag
 		RoutingTag string `toml:"routing_tag"`
+		// Routing Key template, used for the metrics without the RoutingTag
+		RoutingKey string `toml:"routing_key"`
 		// Compression Codec Tag
 		CompressionCodec int
 		// RequiredAcks Tag
 		RequiredAcks int
 		// MaxRetry Tag
 		MaxRetry int
+		// Maximum size of a message, defaults to the sarama default
+		MaxMessageBytes int `toml:"max_message_bytes"`
 
 		// Legacy SSL config options
 		// TLS client certificate
@@ -108,6 +117,12 @@ var sampleConfig = `
   ##  ie, if this tag exists, its value will be used as the routing key
   routing_tag = "host"
 
+  ## Routing key of the metrics without the routing_tag, in which {tag}
+  ## placeholders are replaced by the value of the tags of the metric, or by
+  ## an empty string for missing tags.  Messages with the same key are sent
+  ## to the same partition.
+  # routing_key = "{datacenter}-{host}"
+
   ## CompressionCodec represents the various compression codecs recognized by
   ## Kafka in messages.
   ##  0 : No compression
@@ -134,6 +149,10 @@ var sampleConfig = `
   ##  The total number of times to retry sending a message
   max_retry = 3
 
+  ## The maximum permitted size of a message, should be set equal to or
+  ## smaller than the broker's 'message.max.bytes'.
+  # max_message_bytes = 1000000
+
   ## Optional SSL Config
   # ssl_ca = "/etc/telegraf/ca.pem"
   # ssl_cert = "/etc/telegraf/cert.pem"
@@ -141,7 +160,7 @@ var sampleConfig = `
   ## Use SSL but skip chain & host verification
   # insecure_skip_verify = false
 
-  ## Optional SASL Config
+  ## Optional SASL Config, only the PLAIN mechanism is supported
   # sasl_username = "kafka"
   # sasl_password = "secret"
 
@@ -182,6 +201,19 @@ func (k *Kafka) GetTopicName(metric telegraf.Metric) string {
 	return topicName
 }
 
+// GetRoutingKey returns the routing key of the metric, the value of the
+// RoutingTag if the metric has it, else the RoutingKey template with its
+// placeholders replaced by the tags of the metric.
+func (k *Kafka) GetRoutingKey(metric telegraf.Metric) string {
+	tags := metric.Tags()
+	if h, ok := tags[k.RoutingTag]; ok {
+		return h
+	}
+	return keyPlaceholder.ReplaceAllStringFunc(k.RoutingKey, func(s string) string {
+		return tags[s[1:len(s)-1]]
+	})
+}
+
 func (k *Kafka) SetSerializer(serializer serializers.Serializer) {
 	k.serializer = serializer
 }
@@ -197,6 +229,9 @@ func (k *Kafka) Connect() error {
 	config.Producer.Compression = sarama.CompressionCodec(k.CompressionCodec)
 	config.Producer.Retry.Max = k.MaxRetry
 	config.Producer.Return.Successes = true
+	if k.MaxMessageBytes > 0 {
+		config.Producer.MaxMessageBytes = k.MaxMessageBytes
+	}
 
 	// Legacy support ssl config
 	if k.Certificate != "" {
@@ -220,6 +255,9 @@ func (k *Kafka) Connect() error {
 		config.Net.SASL.User = k.SASLUsername
 		config.Net.SASL.Password = k.SASLPassword
 		config.Net.SASL.Enable = true
+	} else if k.SASLUsername != "" || k.SASLPassword != "" {
+		log.Printf("W! Kafka output SASL auth disabled, both sasl_username " +
+			"and sasl_password must be set")
 	}
 
 	producer, err := sarama.NewSyncProducer(k.Brokers, config)
@@ -247,6 +285,8 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 		return nil
 	}
 
+	msgs := make([]*sarama.ProducerMessage, 0, len(metrics))
+	sent := make(map[*sarama.ProducerMessage]telegraf.Metric, len(metrics))
 	for _, metric := range metrics {
 		buf, err := k.serializer.Serialize(metric)
 		if err != nil {
@@ -259,17 +299,33 @@ func (k *Kafka) Write(metrics []telegraf.Metric) error {
 			Topic: topicName,
 			Value: sarama.ByteEncoder(buf),
 		}
-		if h, ok := metric.Tags()[k.RoutingTag]; ok {
-			m.Key = sarama.StringEncoder(h)
+		if key := k.GetRoutingKey(metric); key != "" {
+			m.Key = sarama.StringEncoder(key)
 		}
+		msgs = append(msgs, m)
+		sent[m] = metric
+	}
 
-		_, _, err = k.producer.SendMessage(m)
-
-		if err != nil {
-			return fmt.Errorf("FAILED to send kafka message: %s\n", err)
+	// the messages are sent at once, only the failed ones being retried
+	err := k.producer.SendMessages(msgs)
+	if err == nil {
+		return nil
+	}
+	errs, ok := err.(sarama.ProducerErrors)
+	if !ok || len(errs) == 0 {
+		return fmt.Errorf("FAILED to send kafka messages: %s", err)
+	}
+	failed := make([]telegraf.Metric, 0, len(errs))
+	for _, e := range errs {
+		if metric, ok := sent[e.Msg]; ok {
+			failed = append(failed, metric)
 		}
 	}
-	return nil
+	return &telegraf.PartialWriteError{
+		Err: fmt.Errorf("FAILED to send %d of %d kafka messages: %s",
+			len(errs), len(msgs), errs[0].Err),
+		Failed: failed,
+	}
 }
 
 func init() {
@@ -1,8 +1,13 @@
 package kafka
 
 import (
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/Shopify/sarama"
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
 	"github.com/influxdata/telegraf/plugins/serializers"
 	"github.com/influxdata/telegraf/testutil"
 	"github.com/stretchr/testify/require"
@@ -96,3 +101,88 @@ func TestValidateTopicSuffixMethod(t *testing.T) {
 		require.NoError(t, err, "Topic suffix method used should be valid.")
 	}
 }
+
+func TestRoutingKey(t *testing.T) {
+	m, err := metric.New("cpu",
+		map[string]string{"host": "server01", "datacenter": "us-east"},
+		map[string]interface{}{"value": 42.0},
+		time.Unix(0, 0))
+	require.NoError(t, err)
+
+	var testcases = []struct {
+		routingTag  string
+		routingKey  string
+		expectedKey string
+	}{
+		{"", "", ""},
+		{"host", "", "server01"},
+		{"missing", "", ""},
+		// the routing tag has precedence over the template
+		{"host", "{datacenter}", "server01"},
+		{"missing", "{datacenter}-{host}", "us-east-server01"},
+		// missing tags are replaced by empty strings
+		{"", "{datacenter}/{rack}", "us-east/"},
+		{"", "static", "static"},
+	}
+
+	for _, testcase := range testcases {
+		k := &Kafka{
+			RoutingTag: testcase.routingTag,
+			RoutingKey: testcase.routingKey,
+		}
+		require.Equal(t, testcase.expectedKey, k.GetRoutingKey(m),
+			"routing_tag %q, routing_key %q", testcase.routingTag, testcase.routingKey)
+	}
+}
+
+// failingProducer fails to send the messages of the given values.
+type failingProducer struct {
+	fail map[string]bool
+	sent []string
+}
+
+func (p *failingProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	return 0, 0, p.SendMessages([]*sarama.ProducerMessage{msg})
+}
+
+func (p *failingProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		value, _ := msg.Value.Encode()
+		if p.fail[string(value)] {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: errors.New("timeout")})
+			continue
+		}
+		p.sent = append(p.sent, string(value))
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (p *failingProducer) Close() error {
+	return nil
+}
+
+func TestWritePartialFailure(t *testing.T) {
+	s, _ := serializers.NewInfluxSerializer()
+	producer := &failingProducer{fail: map[string]bool{"mem value=2i 0\n": true}}
+	k := &Kafka{Topic: "telegraf", serializer: s, producer: producer}
+
+	var metrics []telegraf.Metric
+	for i, name := range []string{"cpu", "mem", "disk"} {
+		m, err := metric.New(name, nil, map[string]interface{}{"value": i + 1},
+			time.Unix(0, 0))
+		require.NoError(t, err)
+		metrics = append(metrics, m)
+	}
+
+	err := k.Write(metrics)
+	require.EqualError(t, err, "FAILED to send 1 of 3 kafka messages: timeout")
+	// only the failed metric is written again
+	perr, ok := err.(*telegraf.PartialWriteError)
+	require.True(t, ok)
+	require.Equal(t, metrics[1:2], perr.Failed)
+	require.Equal(t, []string{"cpu value=1i 0\n", "disk value=3i 0\n"}, producer.sent)
+}
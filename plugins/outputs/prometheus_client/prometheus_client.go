@@ -62,6 +62,7 @@ type PrometheusClient struct {
 	Path               string            `toml:"path"`
 	CollectorsExclude  []string          `toml:"collectors_exclude"`
 	StringAsLabel      bool              `toml:"string_as_label"`
+	DropwizardSummary  bool              `toml:"dropwizard_summary"`
 
 	server *http.Server
 
@@ -94,6 +95,12 @@ var sampleConfig = `
   # Send string metrics as Prometheus labels.
   # Unless set to false all string metrics will be sent as labels.
   string_as_label = true
+
+  ## Expose the timers and histograms of the dropwizard parser, the metrics
+  ## with a metric_type tag of timer or histogram, as Prometheus summaries
+  ## of their percentiles and count. The other fields of the metrics, ie,
+  ## the min, max and rates, are exposed as separate series.
+  # dropwizard_summary = false
 `
 
 func (p *PrometheusClient) basicAuth(h http.Handler) http.Handler {
@@ -300,13 +307,13 @@ func addSample(fam *MetricFamily, sample *Sample, sampleID SampleID) {
 	fam.Samples[sampleID] = sample
 }
 
-func (p *PrometheusClient) addMetricFamily(point telegraf.Metric, sample *Sample, mname string, sampleID SampleID) {
+func (p *PrometheusClient) addMetricFamily(valueType telegraf.ValueType, sample *Sample, mname string, sampleID SampleID) {
 	var fam *MetricFamily
 	var ok bool
 	if fam, ok = p.fam[mname]; !ok {
 		fam = &MetricFamily{
 			Samples:           make(map[SampleID]*Sample),
-			TelegrafValueType: point.Type(),
+			TelegrafValueType: valueType,
 			LabelSet:          make(map[string]int),
 		}
 		p.fam[mname] = fam
@@ -341,6 +348,16 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			}
 		}
 
+		fields := point.Fields()
+		if p.DropwizardSummary && isDropwizardSummary(tags, point) {
+			if sample, rest := dropwizardSummary(fields); sample != nil {
+				sample.Labels = labels
+				sample.Expiration = now.Add(p.ExpirationInterval.Duration)
+				p.addMetricFamily(telegraf.Summary, sample, sanitize(point.Name()), sampleID)
+				fields = rest
+			}
+		}
+
 		switch point.Type() {
 		case telegraf.Summary:
 			var mname string
@@ -379,7 +396,7 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			}
 			mname = sanitize(point.Name())
 
-			p.addMetricFamily(point, sample, mname, sampleID)
+			p.addMetricFamily(point.Type(), sample, mname, sampleID)
 
 		case telegraf.Histogram:
 			var mname string
@@ -418,10 +435,10 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 			}
 			mname = sanitize(point.Name())
 
-			p.addMetricFamily(point, sample, mname, sampleID)
+			p.addMetricFamily(point.Type(), sample, mname, sampleID)
 
 		default:
-			for fn, fv := range point.Fields() {
+			for fn, fv := range fields {
 				// Ignore string and bool fields.
 				var value float64
 				switch fv := fv.(type) {
@@ -460,7 +477,7 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 					}
 				}
 
-				p.addMetricFamily(point, sample, mname, sampleID)
+				p.addMetricFamily(point.Type(), sample, mname, sampleID)
 
 			}
 		}
@@ -468,6 +485,72 @@ func (p *PrometheusClient) Write(metrics []telegraf.Metric) error {
 	return nil
 }
 
+// isDropwizardSummary returns true for the timers and histograms of the
+// dropwizard parser, which have a count and percentiles.
+func isDropwizardSummary(tags map[string]string, point telegraf.Metric) bool {
+	metricType := tags["metric_type"]
+	return (metricType == "timer" || metricType == "histogram") && point.HasField("count")
+}
+
+// dropwizardSummary returns a summary Sample of the percentiles, ie, p50 or
+// p999_ms, and count of a dropwizard timer or histogram, and the remaining
+// fields. The sum is estimated from the mean, the registries don't have it:
+// without a mean the Sample is nil, and the fields are left as untyped series.
+func dropwizardSummary(fields map[string]interface{}) (*Sample, map[string]interface{}) {
+	sample := &Sample{SummaryValue: make(map[float64]float64)}
+	rest := make(map[string]interface{})
+	var mean float64
+	var hasMean bool
+	for fn, fv := range fields {
+		var value float64
+		switch fv := fv.(type) {
+		case int64:
+			value = float64(fv)
+		case float64:
+			value = fv
+		default:
+			rest[fn] = fv
+			continue
+		}
+
+		name := fn
+		if i := strings.IndexByte(name, '_'); i > 0 {
+			name = name[:i]
+		}
+		if name == "mean" && !strings.HasPrefix(fn, "mean_rate") {
+			mean = value
+			hasMean = true
+		}
+		if fn == "count" {
+			sample.Count = uint64(value)
+		} else if q, ok := dropwizardQuantile(name); ok {
+			sample.SummaryValue[q] = value
+		} else {
+			rest[fn] = fv
+		}
+	}
+	if !hasMean {
+		return nil, fields
+	}
+	sample.Sum = mean * float64(sample.Count)
+	return sample, rest
+}
+
+// dropwizardQuantile returns the quantile of a percentile field name, the
+// digits after the p are the decimals of the quantile, ie, p999 is 0.999.
+func dropwizardQuantile(name string) (float64, bool) {
+	if len(name) < 2 || name[0] != 'p' {
+		return 0, false
+	}
+	for _, c := range name[1:] {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+	}
+	q, err := strconv.ParseFloat("0."+name[1:], 64)
+	return q, err == nil
+}
+
 func init() {
 	outputs.Add("prometheus_client", func() telegraf.Output {
 		return &PrometheusClient{
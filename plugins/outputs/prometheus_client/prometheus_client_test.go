@@ -286,6 +286,85 @@ func TestWrite_Summary(t *testing.T) {
 	require.Equal(t, 3, len(sample1.SummaryValue))
 }
 
+func TestWrite_DropwizardSummary(t *testing.T) {
+	client := NewClient()
+	client.DropwizardSummary = true
+
+	p1, err := metric.New(
+		"requests",
+		map[string]string{"metric_type": "timer"},
+		map[string]interface{}{
+			"count":             10,
+			"mean_ms":           2.5,
+			"max_ms":            9.0,
+			"p50_ms":            2.0,
+			"p99_ms":            8.0,
+			"p999_ms":           9.0,
+			"mean_rate_per_sec": 0.5,
+			"duration_units":    "milliseconds",
+		},
+		time.Now())
+	require.NoError(t, err)
+
+	err = client.Write([]telegraf.Metric{p1})
+	require.NoError(t, err)
+
+	fam, ok := client.fam["requests"]
+	require.True(t, ok)
+	require.Equal(t, telegraf.Summary, fam.TelegrafValueType)
+	sample, ok := fam.Samples[CreateSampleID(p1.Tags())]
+	require.True(t, ok)
+	require.Equal(t, uint64(10), sample.Count)
+	require.Equal(t, 25.0, sample.Sum)
+	require.Equal(t, map[float64]float64{0.5: 2.0, 0.99: 8.0, 0.999: 9.0}, sample.SummaryValue)
+
+	for _, name := range []string{"requests_mean_ms", "requests_max_ms", "requests_mean_rate_per_sec"} {
+		fam, ok := client.fam[name]
+		require.True(t, ok, name)
+		require.Equal(t, telegraf.Untyped, fam.TelegrafValueType)
+	}
+	require.NotContains(t, client.fam, "requests_count")
+	require.NotContains(t, client.fam, "requests_p50_ms")
+}
+
+func TestWrite_DropwizardSummaryWithoutMean(t *testing.T) {
+	client := NewClient()
+	client.DropwizardSummary = true
+
+	p1, err := metric.New(
+		"requests",
+		map[string]string{"metric_type": "histogram"},
+		map[string]interface{}{"count": 10, "p50": 2.0},
+		time.Now())
+	require.NoError(t, err)
+
+	err = client.Write([]telegraf.Metric{p1})
+	require.NoError(t, err)
+
+	// no sum can be estimated, the fields are kept as untyped series
+	require.NotContains(t, client.fam, "requests")
+	require.Contains(t, client.fam, "requests_count")
+	require.Contains(t, client.fam, "requests_p50")
+}
+
+func TestWrite_DropwizardSummaryDisabled(t *testing.T) {
+	client := NewClient()
+
+	p1, err := metric.New(
+		"requests",
+		map[string]string{"metric_type": "histogram"},
+		map[string]interface{}{"count": 10, "p50": 2.0},
+		time.Now())
+	require.NoError(t, err)
+
+	err = client.Write([]telegraf.Metric{p1})
+	require.NoError(t, err)
+
+	require.NotContains(t, client.fam, "requests")
+	require.Contains(t, client.fam, "requests_count")
+	require.Contains(t, client.fam, "requests_p50")
+}
+
 func TestWrite_Histogram(t *testing.T) {
 	client := NewClient()
 
@@ -5,6 +5,7 @@ import (
 	"log"
 	"net"
 	"strings"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/internal"
@@ -12,13 +13,30 @@ import (
 	"github.com/influxdata/telegraf/plugins/serializers"
 )
 
+const (
+	defaultReconnectBackoff    = time.Second
+	defaultMaxReconnectBackoff = time.Minute
+)
+
 type SocketWriter struct {
 	Address         string
 	KeepAlivePeriod *internal.Duration
 
+	// Delay before reconnecting after a failed connection, doubled on each
+	// consecutive failure up to MaxReconnectBackoff
+	ReconnectBackoff    internal.Duration `toml:"reconnect_backoff"`
+	MaxReconnectBackoff internal.Duration `toml:"max_reconnect_backoff"`
+
 	serializers.Serializer
 
 	net.Conn
+
+	// backoff is the current delay between the connection attempts, and
+	// nextConnect the time before which no connection is attempted.
+	backoff     time.Duration
+	nextConnect time.Time
+	// now returns the current time, replaced in tests.
+	now func() time.Time
 }
 
 func (sw *SocketWriter) Description() string {
@@ -45,10 +63,16 @@ func (sw *SocketWriter) SampleConfig() string {
   ## Defaults to the OS configuration.
   # keep_alive_period = "5m"
 
+  ## Delay before reconnecting after the connection failed, the writes
+  ## failing in the meantime.  The delay is doubled on each consecutive
+  ## failure, up to max_reconnect_backoff.
+  # reconnect_backoff = "1s"
+  # max_reconnect_backoff = "1m"
+
   ## Data format to generate.
   ## Each data format has its own unique set of configuration options, read
   ## more about them here:
-  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_OUTPUT.md
   # data_format = "influx"
 `
 }
@@ -65,17 +89,41 @@ func (sw *SocketWriter) Connect() error {
 
 	c, err := net.Dial(spl[0], spl[1])
 	if err != nil {
+		sw.delayConnect()
 		return err
 	}
 
 	if err := sw.setKeepAlive(c); err != nil {
-		log.Printf("unable to configure keep alive (%s): %s", sw.Address, err)
+		log.Printf("W! [outputs.socket_writer] Unable to configure keep alive (%s): %s", sw.Address, err)
 	}
 
 	sw.Conn = c
+	sw.backoff = 0
+	sw.nextConnect = time.Time{}
 	return nil
 }
 
+// delayConnect delays the next connection attempt, backing off
+// exponentially on consecutive failures.
+func (sw *SocketWriter) delayConnect() {
+	if sw.backoff == 0 {
+		sw.backoff = sw.ReconnectBackoff.Duration
+		if sw.backoff <= 0 {
+			sw.backoff = defaultReconnectBackoff
+		}
+	} else {
+		sw.backoff *= 2
+	}
+	max := sw.MaxReconnectBackoff.Duration
+	if max <= 0 {
+		max = defaultMaxReconnectBackoff
+	}
+	if sw.backoff > max {
+		sw.backoff = max
+	}
+	sw.nextConnect = sw.now().Add(sw.backoff)
+}
+
 func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 	if sw.KeepAlivePeriod == nil {
 		return nil
@@ -99,6 +147,10 @@ func (sw *SocketWriter) setKeepAlive(c net.Conn) error {
 func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	if sw.Conn == nil {
 		// previous write failed with permanent error and socket was closed.
+		if wait := sw.nextConnect.Sub(sw.now()); wait > 0 {
+			return fmt.Errorf("not connected to %s, reconnecting in %s",
+				sw.Address, wait.Truncate(time.Millisecond))
+		}
 		if err := sw.Connect(); err != nil {
 			return err
 		}
@@ -107,8 +159,8 @@ func (sw *SocketWriter) Write(metrics []telegraf.Metric) error {
 	for _, m := range metrics {
 		bs, err := sw.Serialize(m)
 		if err != nil {
-			//TODO log & keep going with remaining metrics
-			return err
+			log.Printf("E! [outputs.socket_writer] Could not serialize metric: %v", err)
+			continue
 		}
 		if _, err := sw.Conn.Write(bs); err != nil {
 			//TODO log & keep going with remaining strings
@@ -137,7 +189,10 @@ func (sw *SocketWriter) Close() error {
 func newSocketWriter() *SocketWriter {
 	s, _ := serializers.NewInfluxSerializer()
 	return &SocketWriter{
-		Serializer: s,
+		ReconnectBackoff:    internal.Duration{Duration: defaultReconnectBackoff},
+		MaxReconnectBackoff: internal.Duration{Duration: defaultMaxReconnectBackoff},
+		Serializer:          s,
+		now:                 time.Now,
 	}
 }
 
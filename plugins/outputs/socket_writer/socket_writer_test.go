@@ -7,6 +7,7 @@ import (
 	"os"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/influxdata/telegraf"
 	"github.com/influxdata/telegraf/testutil"
@@ -187,3 +188,45 @@ func TestSocketWriter_Write_reconnect(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, string(mbsout), string(buf[:n]))
 }
+
+func TestSocketWriter_Write_reconnectBackoff(t *testing.T) {
+	// find a free port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	now := time.Unix(0, 0)
+	sw := newSocketWriter()
+	sw.Address = "tcp://" + address
+	sw.ReconnectBackoff.Duration = time.Second
+	sw.MaxReconnectBackoff.Duration = 3 * time.Second
+	sw.now = func() time.Time { return now }
+
+	metrics := []telegraf.Metric{testutil.TestMetric(1, "testerr")}
+
+	require.Error(t, sw.Connect())
+	assert.Equal(t, time.Second, sw.backoff)
+
+	// no connection is attempted before the backoff elapsed
+	err = sw.Write(metrics)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reconnecting in 1s")
+
+	// the backoff doubles on each failure, up to the maximum
+	now = now.Add(time.Second)
+	require.Error(t, sw.Write(metrics))
+	assert.Equal(t, 2*time.Second, sw.backoff)
+	now = now.Add(2 * time.Second)
+	require.Error(t, sw.Write(metrics))
+	assert.Equal(t, 3*time.Second, sw.backoff)
+
+	// the backoff is reset once connected
+	listener, err = net.Listen("tcp", address)
+	require.NoError(t, err)
+	defer listener.Close()
+	now = now.Add(3 * time.Second)
+	require.NoError(t, sw.Write(metrics))
+	assert.Equal(t, time.Duration(0), sw.backoff)
+	sw.Close()
+}
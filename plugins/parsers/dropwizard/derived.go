@@ -0,0 +1,267 @@
+package dropwizard
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// expression is a compiled arithmetic expression of a derived metric.
+type expression interface {
+	// eval returns the value of the expression for the metric registry, the
+	// returned bool is false if a referenced metric or field is missing.
+	eval(registry map[string]interface{}) (float64, bool)
+}
+
+type number float64
+
+func (n number) eval(map[string]interface{}) (float64, bool) {
+	return float64(n), true
+}
+
+// reference is a field of a metric of the registry, ie,
+// meters["requests"].m1_rate
+type reference struct {
+	section string
+	metric  string
+	field   string
+}
+
+func (r reference) eval(registry map[string]interface{}) (float64, bool) {
+	section, ok := registry[r.section].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	metric, ok := section[r.metric].(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	switch v := metric[r.field].(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+type negation struct {
+	expr expression
+}
+
+func (n negation) eval(registry map[string]interface{}) (float64, bool) {
+	v, ok := n.expr.eval(registry)
+	return -v, ok
+}
+
+type operation struct {
+	op          byte
+	left, right expression
+}
+
+func (o operation) eval(registry map[string]interface{}) (float64, bool) {
+	l, ok := o.left.eval(registry)
+	if !ok {
+		return 0, false
+	}
+	r, ok := o.right.eval(registry)
+	if !ok {
+		return 0, false
+	}
+	switch o.op {
+	case '+':
+		return l + r, true
+	case '-':
+		return l - r, true
+	case '*':
+		return l * r, true
+	default:
+		if r == 0 {
+			return 0, false
+		}
+		return l / r, true
+	}
+}
+
+var sections = map[string]bool{
+	"counters":   true,
+	"meters":     true,
+	"gauges":     true,
+	"histograms": true,
+	"timers":     true,
+}
+
+// expressionParser is a recursive descent parser of the grammar:
+//   expr      = term { ("+" | "-") term }
+//   term      = factor { ("*" | "/") factor }
+//   factor    = number | "-" factor | "(" expr ")" | reference
+//   reference = section "[" string "]" "." field
+type expressionParser struct {
+	s   string
+	pos int
+}
+
+func compileExpression(s string) (expression, error) {
+	p := &expressionParser{s: s}
+	expr, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+	return expr, nil
+}
+
+func (p *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid expression %q at offset %d: %s",
+		p.s, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+// next skips spaces and consumes c if it is the next character.
+func (p *expressionParser) next(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *expressionParser) expr() (expression, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.next('+'):
+			op = '+'
+		case p.next('-'):
+			op = '-'
+		default:
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = operation{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) term() (expression, error) {
+	left, err := p.factor()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		var op byte
+		switch {
+		case p.next('*'):
+			op = '*'
+		case p.next('/'):
+			op = '/'
+		default:
+			return left, nil
+		}
+		right, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		left = operation{op: op, left: left, right: right}
+	}
+}
+
+func (p *expressionParser) factor() (expression, error) {
+	switch {
+	case p.next('-'):
+		expr, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+		return negation{expr}, nil
+	case p.next('('):
+		expr, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(')') {
+			return nil, p.errorf("missing )")
+		}
+		return expr, nil
+	}
+
+	if p.pos >= len(p.s) {
+		return nil, p.errorf("unexpected end")
+	}
+	c := p.s[p.pos]
+	if c == '.' || (c >= '0' && c <= '9') {
+		start := p.pos
+		for p.pos < len(p.s) && (p.s[p.pos] == '.' || (p.s[p.pos] >= '0' && p.s[p.pos] <= '9')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, p.errorf("%s", err)
+		}
+		return number(v), nil
+	}
+	return p.reference()
+}
+
+func (p *expressionParser) reference() (expression, error) {
+	section := p.identifier()
+	if !sections[section] {
+		return nil, p.errorf("unknown metric type %q", section)
+	}
+	if !p.next('[') {
+		return nil, p.errorf("missing [")
+	}
+	p.skipSpaces()
+	if p.pos >= len(p.s) || p.s[p.pos] != '"' {
+		return nil, p.errorf("missing metric name")
+	}
+	end := strings.IndexByte(p.s[p.pos+1:], '"')
+	if end < 0 {
+		return nil, p.errorf("unterminated metric name")
+	}
+	metric := p.s[p.pos+1 : p.pos+1+end]
+	p.pos += end + 2
+	if !p.next(']') {
+		return nil, p.errorf("missing ]")
+	}
+	if !p.next('.') {
+		return nil, p.errorf("missing field")
+	}
+	field := p.identifier()
+	if field == "" {
+		return nil, p.errorf("missing field")
+	}
+	return reference{section: section, metric: metric, field: field}, nil
+}
+
+func (p *expressionParser) identifier() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := rune(p.s[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
@@ -0,0 +1,51 @@
+package dropwizard
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileExpression(t *testing.T) {
+	registry := map[string]interface{}{
+		"meters": map[string]interface{}{
+			"http.5xx":      map[string]interface{}{"m1_rate": float64(2)},
+			"http.requests": map[string]interface{}{"m1_rate": float64(8)},
+		},
+	}
+
+	tests := []struct {
+		expr  string
+		value float64
+		ok    bool
+	}{
+		{`meters["http.5xx"].m1_rate / meters["http.requests"].m1_rate`, 0.25, true},
+		{`(meters["http.5xx"].m1_rate + 2) * -1.5`, -6, true},
+		{`1 - 2 - 3`, -4, true},
+		{`meters["http.5xx"].m1_rate / 0`, 0, false},
+		{`meters["missing"].m1_rate`, 0, false},
+		{`timers["http.5xx"].m1_rate`, 0, false},
+	}
+	for _, tt := range tests {
+		expr, err := compileExpression(tt.expr)
+		assert.NoError(t, err, tt.expr)
+		v, ok := expr.eval(registry)
+		assert.Equal(t, tt.ok, ok, tt.expr)
+		assert.Equal(t, tt.value, v, tt.expr)
+	}
+}
+
+func TestCompileInvalidExpression(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`1 +`,
+		`(1`,
+		`foo["a"].b`,
+		`meters["a"]`,
+		`meters["a.b`,
+		`meters["a"].b c`,
+	} {
+		_, err := compileExpression(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,136 @@
+package dropwizard
+
+import (
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+)
+
+// normalizeJVMMetric maps the name of a gauge of the JVM instrumentation of
+// the dropwizard metrics library to a measurement, tags and field name, as
+// reported by the JVM inputs, ie:
+//   jvm.memory.heap.used             -> jvm_memory,area=heap used
+//   jvm.memory.pools.Metaspace.usage -> jvm_memory,pool=Metaspace usage
+//   jvm.gc.PS-MarkSweep.count        -> jvm_gc,collector=PS-MarkSweep count
+//   jvm.threads.blocked.count        -> jvm_threads,state=blocked count
+// The returned bool is false if the gauge is not a well-known JVM gauge.
+func normalizeJVMMetric(name string) (string, map[string]string, string, bool) {
+	parts := strings.Split(name, ".")
+	if len(parts) < 3 || parts[0] != "jvm" {
+		return "", nil, "", false
+	}
+	last := strings.Replace(parts[len(parts)-1], "-", "_", -1)
+	inner := strings.Join(parts[2:len(parts)-1], ".")
+
+	switch parts[1] {
+	case "memory":
+		switch {
+		case len(parts) == 4 && (parts[2] == "heap" || parts[2] == "non-heap" || parts[2] == "total"):
+			return "jvm_memory", map[string]string{"area": parts[2]}, last, true
+		case len(parts) >= 5 && parts[2] == "pools":
+			pool := strings.Join(parts[3:len(parts)-1], ".")
+			return "jvm_memory", map[string]string{"pool": pool}, last, true
+		}
+	case "gc":
+		if len(parts) >= 4 && (last == "count" || last == "time") {
+			return "jvm_gc", map[string]string{"collector": inner}, last, true
+		}
+	case "threads":
+		switch {
+		case len(parts) == 3 && last == "count":
+			return "jvm_threads", map[string]string{}, "count", true
+		case len(parts) == 4 && last == "count" && (parts[2] == "daemon" || parts[2] == "deadlock"):
+			return "jvm_threads", map[string]string{}, parts[2] + "_count", true
+		case len(parts) == 4 && last == "count":
+			return "jvm_threads", map[string]string{"state": parts[2]}, "count", true
+		}
+	case "buffers":
+		if len(parts) == 4 {
+			return "jvm_buffers", map[string]string{"pool": parts[2]}, last, true
+		}
+	case "classloader":
+		if len(parts) == 3 {
+			return "jvm_classloader", map[string]string{}, last, true
+		}
+	}
+	return "", nil, "", false
+}
+
+// readJVMMetrics adds the normalized metrics of the well-known JVM gauges,
+// the gauges mapped to the same measurement and tags being merged into a
+// single metric.
+func (p *Parser) readJVMMetrics(dwr map[string]interface{}, metrics []telegraf.Metric, t time.Time) []telegraf.Metric {
+	gauges, ok := dwr["gauges"].(map[string]interface{})
+	if !ok {
+		return metrics
+	}
+
+	type jvmMetric struct {
+		name   string
+		tags   map[string]string
+		fields map[string]interface{}
+	}
+	merged := make(map[string]*jvmMetric)
+	for name, gauge := range gauges {
+		if p.exclude != nil && p.exclude.Match(name) {
+			continue
+		}
+		measurement, tags, field, ok := normalizeJVMMetric(name)
+		if !ok {
+			continue
+		}
+		fields, ok := gauge.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		n, ok := fields["value"].(json.Number)
+		if !ok {
+			continue
+		}
+
+		tags["metric_type"] = "gauge"
+		key := seriesKey(measurement, tags)
+		m, ok := merged[key]
+		if !ok {
+			m = &jvmMetric{name: measurement, tags: tags, fields: make(map[string]interface{})}
+			merged[key] = m
+		}
+		m.fields[field] = p.numberValue("gauge", n)
+	}
+
+	keys := make([]string, 0, len(merged))
+	for key := range merged {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m := merged[key]
+		jvm, err := metric.New(m.name, m.tags, m.fields, t)
+		if err != nil {
+			log.Printf("W! failed to create metric %s: %s\n", m.name, err)
+			continue
+		}
+		metrics = append(metrics, jvm)
+	}
+	return metrics
+}
+
+// seriesKey returns a key identifying the measurement and tags.
+func seriesKey(name string, tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	key := name
+	for _, k := range keys {
+		key += "," + k + "=" + tags[k]
+	}
+	return key
+}
@@ -5,15 +5,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
 	"github.com/influxdata/telegraf/internal/templating"
 	"github.com/influxdata/telegraf/metric"
 	"github.com/tidwall/gjson"
 )
 
+// jvmMetrics are the names of the metrics registered by the standard JVM,
+// Jetty and servlet instrumentation of the dropwizard metrics library and
+// framework. They are dropped when ExcludeJVMMetrics is set.
+var jvmMetrics = []string{
+	"jvm.*",
+	"org.eclipse.jetty.*",
+	"io.dropwizard.jetty.*",
+	"com.codahale.metrics.servlet.*",
+	"io.dropwizard.metrics.servlet.*",
+	"com.codahale.metrics.jetty*",
+	"io.dropwizard.metrics.jetty*",
+}
+
+// Rename rewrites the metric names matching Pattern, a regular expression,
+// with Replacement, which may refer to the capture groups of the pattern,
+// ie, "${1}".
+type Rename struct {
+	Pattern     string
+	Replacement string
+
+	re *regexp.Regexp
+}
+
 var fieldEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
 var keyEscaper = strings.NewReplacer(" ", "\\ ", ",", "\\,", "=", "\\=")
 
@@ -26,6 +54,14 @@ type Parser struct {
 	// if left empty, the whole json object is parsed as a metric registry
 	MetricRegistryPath string
 
+	// an optional json path containing an object of named metric registries,
+	// takes precedence over MetricRegistryPath
+	RegistriesPath string
+
+	// the tag holding the name of the registry of the metrics when using
+	// RegistriesPath, defaults to "registry"
+	RegistryTag string
+
 	// an optional json path containing the default time of the metrics
 	// if left empty, or if cannot be parsed the current processing time is used as the time of the metrics
 	TimePath string
@@ -49,7 +85,97 @@ type Parser struct {
 	Separator string
 	Templates []string
 
+	// an optional map of field names to the type the field values are
+	// converted to, one of "int", "float", "string" or "bool"
+	FieldTypes map[string]string
+
+	// drop the standard JVM, Jetty and servlet metrics
+	ExcludeJVMMetrics bool
+
+	// prefixes removed from the metric names, the first matching prefix is
+	// removed
+	StripPrefixes []string
+
+	// rules rewriting the metric names, applied in order after the prefixes
+	// are stripped
+	Renames []Rename
+
+	// an optional map of metric types, ie, "timer", to the fields kept for
+	// the metrics of that type, supporting glob patterns
+	Fields map[string][]string
+
+	// an optional map of derived metric names to arithmetic expressions of
+	// the fields of other metrics of the registry, ie,
+	// meters["http.5xx"].m1_rate / meters["http.requests"].m1_rate
+	Derived map[string]string
+
+	// the handling of the NaN, Infinity and -Infinity values, either "drop"
+	// to drop the field, "zero" to replace the value with 0 or "emit" to
+	// keep the value as a string, defaults to "drop"
+	NaNPolicy string
+
+	// flatten the gauges whose value is a json object into a field per
+	// member, ie, value_numerator and value_denominator, joining the names
+	// with FlattenSeparator, "_" by default
+	FlattenObjectGauges bool
+	FlattenSeparator    string
+
+	// the handling of the gauges reporting an error, ie, when the supplier of
+	// the gauge threw, either "drop" to drop the gauge or "tag" to keep the
+	// other fields of the gauge with the tag error=true, defaults to "drop"
+	GaugeErrorPolicy string
+
+	// add a dropwizard_gauge_errors metric counting the gauges of the
+	// registry reporting an error
+	GaugeErrorsMetric bool
+
+	// the types of metrics parsed, ie, "timers", all types are parsed by
+	// default
+	MetricTypes []string
+
+	// the percentile fields of histograms and timers kept, ie, "p99", all
+	// percentiles are kept by default
+	Percentiles []string
+
+	// the percentiles computed, along with the min and max, from the raw
+	// samples of histograms and timers, the "values" array of the registries
+	// serialized with showSamples=true, ie, "p90"; the samples are ignored
+	// when left empty
+	SamplePercentiles []string
+
+	// append the unit of the durations and rates of timers and meters to the
+	// field names, ie, p99_ms and m1_rate_per_sec
+	UnitSuffixes bool
+
+	// the type of the numeric values of gauges, either "auto" to keep the
+	// integers as int64 values and the other numbers as float64 values,
+	// "float" or "int", defaults to "auto"
+	GaugeValueType string
+
+	// map the gauges of the JVM instrumentation into the jvm_memory, jvm_gc,
+	// jvm_threads, jvm_buffers and jvm_classloader measurements
+	NormalizeJVMMetrics bool
+
+	// convert the statistics of timers from their duration_units to integer
+	// nanoseconds
+	TimerNanoseconds bool
+
+	// the handling of the version member of the registry, either "tag" to
+	// add it as the registry_version tag of the metrics, "metric" to add a
+	// dropwizard_registry metric with a version field, or "" to ignore it
+	RegistryVersion string
+
+	// the fields dropped from the metrics of all types, ie, "stddev" and
+	// "mean_rate", glob patterns are supported
+	OmitFields []string
+
 	templateEngine *templating.Engine
+	exclude        filter.Filter
+	fieldFilters   map[string]filter.Filter
+	omitFields     filter.Filter
+	derived        map[string]expression
+	metricTypes    map[string]bool
+	percentiles    map[string]bool
 }
 
 // Parse parses the input bytes to an array of metrics
@@ -57,20 +183,40 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 
 	metrics := make([]telegraf.Metric, 0)
 
+	buf = quoteNonFinite(buf)
 	metricTime, err := p.parseTime(buf)
 	if err != nil {
 		return nil, err
 	}
-	dwr, err := p.unmarshalMetrics(buf)
-	if err != nil {
-		return nil, err
-	}
+	if p.RegistriesPath != "" {
+		registries, err := p.unmarshalRegistries(buf)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(registries))
+		for name := range registries {
+			names = append(names, name)
+		}
+		sort.Strings(names)
 
-	metrics = p.readDWMetrics("counter", dwr["counters"], metrics, metricTime)
-	metrics = p.readDWMetrics("meter", dwr["meters"], metrics, metricTime)
-	metrics = p.readDWMetrics("gauge", dwr["gauges"], metrics, metricTime)
-	metrics = p.readDWMetrics("histogram", dwr["histograms"], metrics, metricTime)
-	metrics = p.readDWMetrics("timer", dwr["timers"], metrics, metricTime)
+		tag := p.RegistryTag
+		if tag == "" {
+			tag = "registry"
+		}
+		for _, name := range names {
+			start := len(metrics)
+			metrics = p.readRegistry(registries[name], metrics, metricTime)
+			for _, m := range metrics[start:] {
+				m.AddTag(tag, name)
+			}
+		}
+	} else {
+		dwr, err := p.unmarshalMetrics(buf)
+		if err != nil {
+			return nil, err
+		}
+		metrics = p.readRegistry(dwr, metrics, metricTime)
+	}
 
 	jsonTags := p.readTags(buf)
 
@@ -100,6 +246,110 @@ func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
 	return metrics, nil
 }
 
+// Init validates the configuration and initializes the templating support
+func (p *Parser) Init() error {
+	for field, fieldType := range p.FieldTypes {
+		switch fieldType {
+		case "int", "float", "string", "bool":
+		default:
+			return fmt.Errorf("invalid type %q for field %q, must be one of int, float, string or bool",
+				fieldType, field)
+		}
+	}
+	switch p.NaNPolicy {
+	case "", "drop", "zero", "emit":
+	default:
+		return fmt.Errorf("invalid NaN policy %q, must be one of drop, zero or emit", p.NaNPolicy)
+	}
+	p.metricTypes = nil
+	if len(p.MetricTypes) > 0 {
+		p.metricTypes = make(map[string]bool)
+		for _, metricType := range p.MetricTypes {
+			// both the names of the sections and of the types are accepted
+			metricType = strings.TrimSuffix(metricType, "s")
+			switch metricType {
+			case "counter", "gauge", "histogram", "meter", "timer":
+				p.metricTypes[metricType] = true
+			default:
+				return fmt.Errorf("invalid metric type %q", metricType)
+			}
+		}
+	}
+	p.percentiles = nil
+	if len(p.Percentiles) > 0 {
+		p.percentiles = make(map[string]bool)
+		for _, percentile := range p.Percentiles {
+			if !isPercentile(percentile) {
+				return fmt.Errorf("invalid percentile %q, must be like p99", percentile)
+			}
+			p.percentiles[percentile] = true
+		}
+	}
+	for _, percentile := range p.SamplePercentiles {
+		if _, ok := percentileQuantile(percentile); !ok {
+			return fmt.Errorf("invalid sample percentile %q, must be like p99", percentile)
+		}
+	}
+	switch p.GaugeValueType {
+	case "", "auto", "float", "int":
+	default:
+		return fmt.Errorf("invalid gauge value type %q, must be one of auto, float or int", p.GaugeValueType)
+	}
+	switch p.RegistryVersion {
+	case "", "tag", "metric":
+	default:
+		return fmt.Errorf("invalid registry version %q, must be tag or metric", p.RegistryVersion)
+	}
+	switch p.GaugeErrorPolicy {
+	case "", "drop", "tag":
+	default:
+		return fmt.Errorf("invalid gauge error policy %q, must be drop or tag", p.GaugeErrorPolicy)
+	}
+	for i := range p.Renames {
+		re, err := regexp.Compile(p.Renames[i].Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid rename pattern %q: %s", p.Renames[i].Pattern, err)
+		}
+		p.Renames[i].re = re
+	}
+	p.derived = make(map[string]expression)
+	for name, s := range p.Derived {
+		expr, err := compileExpression(s)
+		if err != nil {
+			return err
+		}
+		p.derived[name] = expr
+	}
+	p.fieldFilters = make(map[string]filter.Filter)
+	for metricType, fields := range p.Fields {
+		switch metricType {
+		case "counter", "gauge", "histogram", "meter", "timer":
+		default:
+			return fmt.Errorf("invalid metric type %q", metricType)
+		}
+		f, err := filter.Compile(fields)
+		if err != nil {
+			return err
+		}
+		p.fieldFilters[metricType] = f
+	}
+	if len(p.OmitFields) > 0 {
+		omitFields, err := filter.Compile(p.OmitFields)
+		if err != nil {
+			return err
+		}
+		p.omitFields = omitFields
+	}
+	if p.ExcludeJVMMetrics {
+		exclude, err := filter.Compile(jvmMetrics)
+		if err != nil {
+			return err
+		}
+		p.exclude = exclude
+	}
+	return p.InitTemplating()
+}
+
 // InitTemplating initializes the templating support
 func (p *Parser) InitTemplating() error {
 	if len(p.Templates) > 0 {
@@ -169,6 +419,102 @@ func (p *Parser) parseTime(buf []byte) (time.Time, error) {
 	return time.Now().UTC(), nil
 }
 
+// unmarshalRegistries returns the named metric registries of the object
+// found in RegistriesPath, values which are not objects are ignored.
+func (p *Parser) unmarshalRegistries(buf []byte) (map[string]map[string]interface{}, error) {
+	result := gjson.GetBytes(buf, p.RegistriesPath)
+	if !result.IsObject() {
+		return nil, fmt.Errorf("metric registries not found in JSON path %s", p.RegistriesPath)
+	}
+	var jsonOut map[string]interface{}
+	if err := decodeJSON([]byte(result.Raw), &jsonOut); err != nil {
+		return nil, fmt.Errorf("unable to parse dropwizard metric registries from JSON document, %s", err)
+	}
+
+	registries := make(map[string]map[string]interface{})
+	for name, registry := range jsonOut {
+		if dwr, ok := registry.(map[string]interface{}); ok {
+			registries[name] = dwr
+		}
+	}
+	return registries, nil
+}
+
+// readRegistry appends the metrics of a metric registry.
+func (p *Parser) readRegistry(dwr map[string]interface{}, metrics []telegraf.Metric, t time.Time) []telegraf.Metric {
+	start := len(metrics)
+	metrics = p.readDWMetrics("counter", dwr["counters"], metrics, t)
+	metrics = p.readDWMetrics("meter", dwr["meters"], metrics, t)
+	metrics = p.readDWMetrics("gauge", dwr["gauges"], metrics, t)
+	if p.NormalizeJVMMetrics && (p.metricTypes == nil || p.metricTypes["gauge"]) {
+		metrics = p.readJVMMetrics(dwr, metrics, t)
+	}
+	metrics = p.readDWMetrics("histogram", dwr["histograms"], metrics, t)
+	metrics = p.readDWMetrics("timer", dwr["timers"], metrics, t)
+	if p.GaugeErrorsMetric {
+		metrics = p.readGaugeErrors(dwr, metrics, t)
+	}
+	metrics = p.readDerivedMetrics(dwr, metrics, t)
+
+	version, ok := dwr["version"].(string)
+	if !ok {
+		return metrics
+	}
+	switch p.RegistryVersion {
+	case "tag":
+		for _, m := range metrics[start:] {
+			m.AddTag("registry_version", version)
+		}
+	case "metric":
+		m, err := metric.New("dropwizard_registry",
+			map[string]string{},
+			map[string]interface{}{"version": version},
+			t)
+		if err != nil {
+			log.Printf("W! failed to create dropwizard_registry metric: %s\n", err)
+			return metrics
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// readGaugeErrors adds a dropwizard_gauge_errors metric counting the gauges
+// reporting an error.
+func (p *Parser) readGaugeErrors(dwr map[string]interface{}, metrics []telegraf.Metric, t time.Time) []telegraf.Metric {
+	var count int64
+	if gauges, ok := dwr["gauges"].(map[string]interface{}); ok {
+		for name, gauge := range gauges {
+			if p.exclude != nil && p.exclude.Match(name) {
+				continue
+			}
+			if hasError(gauge) {
+				count++
+			}
+		}
+	}
+	m, err := metric.New("dropwizard_gauge_errors",
+		map[string]string{},
+		map[string]interface{}{"count": count},
+		t)
+	if err != nil {
+		log.Printf("W! failed to create dropwizard_gauge_errors metric: %s\n", err)
+		return metrics
+	}
+	return append(metrics, m)
+}
+
+// hasError returns true if the gauge has an error message instead of, or
+// along with, its value.
+func hasError(gauge interface{}) bool {
+	fields, ok := gauge.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = fields["error"].(string)
+	return ok
+}
+
 func (p *Parser) unmarshalMetrics(buf []byte) (map[string]interface{}, error) {
 
 	var registryBytes []byte
@@ -187,7 +533,7 @@ func (p *Parser) unmarshalMetrics(buf []byte) (map[string]interface{}, error) {
 		registryBytes = buf
 	}
 	var jsonOut map[string]interface{}
-	err := json.Unmarshal(registryBytes, &jsonOut)
+	err := decodeJSON(registryBytes, &jsonOut)
 	if err != nil {
 		err = fmt.Errorf("unable to parse dropwizard metric registry from JSON document, %s", err)
 		return nil, err
@@ -196,11 +542,23 @@ func (p *Parser) unmarshalMetrics(buf []byte) (map[string]interface{}, error) {
 }
 
 func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []telegraf.Metric, time time.Time) []telegraf.Metric {
+	if p.metricTypes != nil && !p.metricTypes[metricType] {
+		return metrics
+	}
 
 	switch dwmsTyped := dwms.(type) {
 	case map[string]interface{}:
 		var metricsBuffer bytes.Buffer
 		for dwmName, dwmFields := range dwmsTyped {
+			if p.exclude != nil && p.exclude.Match(dwmName) {
+				continue
+			}
+			if metricType == "gauge" && p.NormalizeJVMMetrics {
+				if _, _, _, ok := normalizeJVMMetric(dwmName); ok {
+					continue
+				}
+			}
+			dwmName = p.rename(p.stripPrefix(dwmName))
 			measurementName := dwmName
 			tags := make(map[string]string)
 			fieldPrefix := ""
@@ -212,6 +570,14 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 			}
 			tags["metric_type"] = metricType
 
+			gaugeError := metricType == "gauge" && hasError(dwmFields)
+			if gaugeError {
+				if p.GaugeErrorPolicy != "tag" {
+					continue
+				}
+				tags["error"] = "true"
+			}
+
 			measurementWithTags := measurementName
 			for tagName, tagValue := range tags {
 				tagKeyValue := fmt.Sprintf("%s=%s", keyEscaper.Replace(tagName), keyEscaper.Replace(tagValue))
@@ -221,11 +587,74 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 			fields := make([]string, 0)
 			switch t := dwmFields.(type) {
 			case map[string]interface{}: // json object
+				if metricType == "gauge" && p.FlattenObjectGauges {
+					t = p.flatten("", t, make(map[string]interface{}))
+				}
+				if (metricType == "histogram" || metricType == "timer") && len(p.SamplePercentiles) > 0 {
+					if computed, ok := p.sampleFields(t["values"]); ok {
+						// the computed fields replace the reported ones
+						merged := make(map[string]interface{}, len(t)+len(computed))
+						for k, v := range t {
+							merged[k] = v
+						}
+						for k, v := range computed {
+							merged[k] = v
+						}
+						t = merged
+					}
+				}
+				var durationSuffix, rateSuffix string
+				if p.UnitSuffixes {
+					durationSuffix, rateSuffix = unitSuffixes(metricType, t)
+				}
+				scale, toNanoseconds := durationScale(t)
+				toNanoseconds = toNanoseconds && metricType == "timer" && p.TimerNanoseconds
+				if toNanoseconds && p.UnitSuffixes {
+					durationSuffix = "ns"
+				}
+				fieldFilter := p.fieldFilters[metricType]
 				for fieldName, fieldValue := range t {
-					key := keyEscaper.Replace(fieldPrefix + fieldName)
+					if gaugeError && fieldName == "error" {
+						continue
+					}
+					if p.percentiles != nil && (metricType == "histogram" || metricType == "timer") &&
+						isPercentile(fieldName) && !p.percentiles[fieldName] {
+						continue
+					}
+					if fieldFilter != nil && !fieldFilter.Match(fieldName) {
+						continue
+					}
+					if p.omitFields != nil && p.omitFields.Match(fieldName) {
+						continue
+					}
+					key := keyEscaper.Replace(fieldPrefix + fieldName +
+						unitSuffix(fieldName, durationSuffix, rateSuffix))
+					if n, ok := fieldValue.(json.Number); ok {
+						fieldValue = p.numberValue(metricType, n)
+					}
+					if isNonFinite(fieldValue) {
+						switch p.NaNPolicy {
+						case "zero":
+							fieldValue = float64(0)
+						case "emit":
+							// line protocol has no representation of
+							// non-finite floats, keep the string
+						default:
+							continue
+						}
+					}
+					if v, ok := fieldValue.(float64); ok && toNanoseconds && isDuration(fieldName) {
+						fieldValue = nanoseconds(v, scale)
+					}
+					fieldValue, ok := p.convertField(fieldPrefix+fieldName, fieldValue)
+					if !ok {
+						continue
+					}
 					switch v := fieldValue.(type) {
+					case int64:
+						fields = append(fields, fmt.Sprintf("%s=%di", key, v))
 					case float64:
-						fields = append(fields, fmt.Sprintf("%s=%f", key, v))
+						fields = append(fields, fmt.Sprintf("%s=%s", key, strconv.FormatFloat(v, 'f', -1, 64)))
 					case string:
 						fields = append(fields, fmt.Sprintf("%s=\"%s\"", key, fieldEscaper.Replace(v)))
 					case bool:
@@ -236,6 +665,10 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 			default: // ignore
 			}
 
+			if len(fields) == 0 {
+				continue
+			}
+
 			metricsBuffer.WriteString(fmt.Sprintf("%s,metric_type=%s ", measurementWithTags, metricType))
 			metricsBuffer.WriteString(strings.Join(fields, ","))
 			metricsBuffer.WriteString("\n")
@@ -251,6 +684,250 @@ func (p *Parser) readDWMetrics(metricType string, dwms interface{}, metrics []te
 
 }
 
+// readDerivedMetrics evaluates the derived metrics, a derived metric is
+// left out if a metric it refers to is missing or on a division by zero.
+func (p *Parser) readDerivedMetrics(dwr map[string]interface{}, metrics []telegraf.Metric, t time.Time) []telegraf.Metric {
+	names := make([]string, 0, len(p.derived))
+	for name := range p.derived {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		v, ok := p.derived[name].eval(dwr)
+		if !ok {
+			log.Printf("D! unable to evaluate derived metric %s\n", name)
+			continue
+		}
+		m, err := metric.New(name,
+			map[string]string{"metric_type": "derived"},
+			map[string]interface{}{"value": v},
+			t)
+		if err != nil {
+			log.Printf("W! failed to create derived metric %s: %s\n", name, err)
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// flatten adds the members of the object to the flattened fields, the
+// members of nested objects are added with the names of their parents.
+func (p *Parser) flatten(prefix string, object, fields map[string]interface{}) map[string]interface{} {
+	separator := p.FlattenSeparator
+	if separator == "" {
+		separator = "_"
+	}
+	for name, value := range object {
+		if prefix != "" {
+			name = prefix + separator + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			p.flatten(name, nested, fields)
+		} else {
+			fields[name] = value
+		}
+	}
+	return fields
+}
+
+// stripPrefix removes the first of StripPrefixes the name starts with, as
+// long as the remaining name is not empty.
+func (p *Parser) stripPrefix(name string) string {
+	for _, prefix := range p.StripPrefixes {
+		if len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// rename applies the rename rules to the metric name.
+func (p *Parser) rename(name string) string {
+	for _, r := range p.Renames {
+		if r.re != nil {
+			name = r.re.ReplaceAllString(name, r.Replacement)
+		}
+	}
+	return name
+}
+
+// convertField converts the value of a field to the type configured in
+// FieldTypes. The returned bool is false if the value cannot be converted.
+func (p *Parser) convertField(name string, value interface{}) (interface{}, bool) {
+	fieldType, ok := p.FieldTypes[name]
+	if !ok {
+		return value, true
+	}
+
+	switch v := value.(type) {
+	case int64:
+		switch fieldType {
+		case "int":
+			return v, true
+		case "float":
+			return float64(v), true
+		case "string":
+			return strconv.FormatInt(v, 10), true
+		case "bool":
+			return v != 0, true
+		}
+	case float64:
+		switch fieldType {
+		case "int":
+			return int64(v), true
+		case "float":
+			return v, true
+		case "string":
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case "bool":
+			return v != 0, true
+		}
+	case string:
+		switch fieldType {
+		case "int":
+			if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return i, true
+			}
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return int64(f), true
+			}
+		case "float":
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return f, true
+			}
+		case "string":
+			return v, true
+		case "bool":
+			if b, err := strconv.ParseBool(v); err == nil {
+				return b, true
+			}
+		}
+	case bool:
+		switch fieldType {
+		case "int":
+			if v {
+				return int64(1), true
+			}
+			return int64(0), true
+		case "float":
+			if v {
+				return float64(1), true
+			}
+			return float64(0), true
+		case "string":
+			return strconv.FormatBool(v), true
+		case "bool":
+			return v, true
+		}
+	}
+	log.Printf("D! unable to convert value %v of field %s to %s\n", value, name, fieldType)
+	return nil, false
+}
+
+// decodeJSON decodes the numbers of the document as json.Number, to keep
+// integers exact.
+func decodeJSON(buf []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(buf))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// nonFiniteLiterals are the representations of non-finite numbers written
+// by serializers, ie, Jackson with ALLOW_NON_NUMERIC_NUMBERS.
+var nonFiniteLiterals = []string{"-Infinity", "+Infinity", "Infinity", "NaN"}
+
+// quoteNonFinite quotes the NaN and Infinity literals of the document, which
+// are not valid JSON, so that the document can be decoded.
+func quoteNonFinite(buf []byte) []byte {
+	if !bytes.Contains(buf, []byte("NaN")) && !bytes.Contains(buf, []byte("Infinity")) {
+		return buf
+	}
+
+	out := make([]byte, 0, len(buf)+16)
+	inString := false
+	for i := 0; i < len(buf); i++ {
+		c := buf[i]
+		if inString {
+			out = append(out, c)
+			if c == '\\' && i+1 < len(buf) {
+				i++
+				out = append(out, buf[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			out = append(out, c)
+			continue
+		}
+
+		quoted := false
+		for _, literal := range nonFiniteLiterals {
+			if bytes.HasPrefix(buf[i:], []byte(literal)) {
+				out = append(out, '"')
+				out = append(out, literal...)
+				out = append(out, '"')
+				i += len(literal) - 1
+				quoted = true
+				break
+			}
+		}
+		if !quoted {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// isPercentile returns true if the field name is a percentile of a snapshot,
+// ie, p50 or p999.
+func isPercentile(name string) bool {
+	if len(name) < 2 || name[0] != 'p' {
+		return false
+	}
+	for _, c := range name[1:] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNonFinite returns true if the value is one of the non-finite literals.
+func isNonFinite(value interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	for _, literal := range nonFiniteLiterals {
+		if s == literal {
+			return true
+		}
+	}
+	return false
+}
+
+// numberValue returns the value of a number of the registry. The integer
+// values of gauges are exact int64 values, ie, byte counters above 2^53,
+// unless GaugeValueType is set, other numbers are float64 values.
+func (p *Parser) numberValue(metricType string, n json.Number) interface{} {
+	if metricType == "gauge" && p.GaugeValueType != "float" {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+	}
+	f, _ := n.Float64()
+	if metricType == "gauge" && p.GaugeValueType == "int" &&
+		f >= math.MinInt64 && f <= math.MaxInt64 {
+		return int64(f)
+	}
+	return f
+}
+
 func arraymap(vs []string, f func(string) string) []string {
 	vsm := make([]string, len(vs))
 	for i, v := range vs {
@@ -1,6 +1,8 @@
 package dropwizard
 
 import (
+	"math"
+	"sort"
 	"testing"
 
 	"github.com/influxdata/telegraf"
@@ -459,14 +461,14 @@ func TestParseSampleTemplateJSON(t *testing.T) {
 	vmMemoryHeapCommitted := search(metrics, "vm_memory", map[string]string{"pool": "heap"}, "committed_value")
 	assert.NotNil(t, vmMemoryHeapCommitted)
 	assert.Equal(t, map[string]interface{}{
-		"committed_value": float64(1),
+		"committed_value": int64(1),
 	}, vmMemoryHeapCommitted.Fields())
 	assert.Equal(t, map[string]string{"metric_type": "gauge", "pool": "heap"}, vmMemoryHeapCommitted.Tags())
 
 	vmMemoryNonHeapCommitted := search(metrics, "vm_memory", map[string]string{"pool": "non-heap"}, "committed_value")
 	assert.NotNil(t, vmMemoryNonHeapCommitted)
 	assert.Equal(t, map[string]interface{}{
-		"committed_value": float64(6),
+		"committed_value": int64(6),
 	}, vmMemoryNonHeapCommitted.Fields())
 	assert.Equal(t, map[string]string{"metric_type": "gauge", "pool": "non-heap"}, vmMemoryNonHeapCommitted.Tags())
 }
@@ -493,3 +495,663 @@ func containsAll(t1 map[string]string, t2 map[string]string) bool {
 	}
 	return true
 }
+
+const fieldTypesJSON = `
+{
+	"version": 		"3.0.0",
+	"counters" : 	{
+		"a" : {
+			"count" : 1.5
+		},
+		"b" : {
+			"count" : "2"
+		}
+	},
+	"meters" : 		{},
+	"gauges" : 		{
+		"c" : {
+			"value" : "not a number"
+		},
+		"d" : {
+			"value" : true
+		}
+	},
+	"histograms" : 	{},
+	"timers" : 		{}
+}
+`
+
+func TestParseFieldTypes(t *testing.T) {
+	parser := Parser{
+		FieldTypes: map[string]string{
+			"count": "int",
+			"value": "float",
+		},
+	}
+	assert.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(fieldTypesJSON))
+	assert.NoError(t, err)
+	// the gauge c is dropped as it has no field left
+	assert.Len(t, metrics, 3)
+
+	fields := make(map[string]map[string]interface{})
+	for _, m := range metrics {
+		fields[m.Name()] = m.Fields()
+	}
+	assert.Equal(t, map[string]interface{}{"count": int64(1)}, fields["a"])
+	assert.Equal(t, map[string]interface{}{"count": int64(2)}, fields["b"])
+	assert.Equal(t, map[string]interface{}{"value": float64(1)}, fields["d"])
+}
+
+func TestInvalidFieldType(t *testing.T) {
+	parser := Parser{
+		FieldTypes: map[string]string{"count": "integer"},
+	}
+	assert.Error(t, parser.Init())
+}
+
+const jvmJSON = `
+{
+	"version": 		"3.0.0",
+	"counters" : 	{
+		"com.example.requests" : {
+			"count" : 1
+		},
+		"io.dropwizard.jetty.MutableServletContextHandler.active-requests" : {
+			"count" : 1
+		}
+	},
+	"meters" : 		{},
+	"gauges" : 		{
+		"jvm.memory.heap.used" : {
+			"value" : 1
+		}
+	},
+	"histograms" : 	{},
+	"timers" : 		{
+		"org.eclipse.jetty.server.HttpConnectionFactory.8080.connections" : {
+			"count" : 1
+		}
+	}
+}
+`
+
+func TestParseExcludeJVMMetrics(t *testing.T) {
+	parser := Parser{}
+	metrics, err := parser.Parse([]byte(jvmJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 4)
+
+	parser = Parser{ExcludeJVMMetrics: true}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(jvmJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, "com.example.requests", metrics[0].Name())
+}
+
+func TestParseStripPrefixes(t *testing.T) {
+	parser := Parser{
+		StripPrefixes: []string{"com.example.", "com."},
+	}
+	metrics, err := parser.Parse([]byte(jvmJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 4)
+
+	names := make(map[string]bool)
+	for _, m := range metrics {
+		names[m.Name()] = true
+	}
+	assert.True(t, names["requests"])
+	assert.True(t, names["jvm.memory.heap.used"])
+}
+
+func TestParseRenames(t *testing.T) {
+	parser := Parser{
+		StripPrefixes: []string{"com."},
+		Renames: []Rename{
+			{Pattern: `^example\.(.*)$`, Replacement: "app.${1}"},
+			{Pattern: `^jvm\.memory\.heap\.`, Replacement: "heap_"},
+		},
+	}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(jvmJSON))
+	assert.NoError(t, err)
+
+	names := make(map[string]bool)
+	for _, m := range metrics {
+		names[m.Name()] = true
+	}
+	assert.True(t, names["app.requests"])
+	assert.True(t, names["heap_used"])
+
+	parser = Parser{Renames: []Rename{{Pattern: "("}}}
+	assert.Error(t, parser.Init())
+}
+
+func TestParseFieldsPerType(t *testing.T) {
+	parser := Parser{
+		Fields: map[string][]string{
+			"timer": {"count", "p99*"},
+		},
+	}
+	assert.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(validTimerJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, []string{"count", "p99", "p999"}, sortedKeys(metrics[0].Fields()))
+
+	metrics, err = parser.Parse([]byte(validHistogramJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Len(t, metrics[0].Fields(), 11)
+
+	parser = Parser{Fields: map[string][]string{"timers": {"count"}}}
+	assert.Error(t, parser.Init())
+}
+
+func sortedKeys(fields map[string]interface{}) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestParseDerived(t *testing.T) {
+	parser := Parser{
+		Derived: map[string]string{
+			"errors_ratio": `counters["com.example.requests"].count / gauges["jvm.memory.heap.used"].value`,
+			"missing":      `counters["nonexistent"].count`,
+		},
+		DefaultTags: map[string]string{"app": "example"},
+	}
+	assert.NoError(t, parser.Init())
+
+	metrics, err := parser.Parse([]byte(jvmJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+
+	derived := metrics[4]
+	assert.Equal(t, "errors_ratio", derived.Name())
+	assert.Equal(t, map[string]interface{}{"value": float64(1)}, derived.Fields())
+	assert.Equal(t, map[string]string{"metric_type": "derived", "app": "example"}, derived.Tags())
+
+	parser = Parser{Derived: map[string]string{"bad": "1 +"}}
+	assert.Error(t, parser.Init())
+}
+
+const registriesJSON = `
+{
+	"registries" : {
+		"app" : {
+			"counters" : {
+				"requests" : {
+					"count" : 1
+				}
+			}
+		},
+		"admin" : {
+			"counters" : {
+				"requests" : {
+					"count" : 2
+				}
+			}
+		},
+		"version" : "3.0.0"
+	}
+}
+`
+
+func TestParseRegistries(t *testing.T) {
+	parser := Parser{RegistriesPath: "registries"}
+
+	metrics, err := parser.Parse([]byte(registriesJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.Equal(t, map[string]string{"metric_type": "counter", "registry": "admin"}, metrics[0].Tags())
+	assert.Equal(t, map[string]interface{}{"count": float64(2)}, metrics[0].Fields())
+	assert.Equal(t, map[string]string{"metric_type": "counter", "registry": "app"}, metrics[1].Tags())
+
+	parser = Parser{RegistriesPath: "missing"}
+	_, err = parser.Parse([]byte(registriesJSON))
+	assert.Error(t, err)
+}
+
+const largeNumbersJSON = `
+{
+	"version": "3.0.0",
+	"counters" : {
+		"requests" : { "count" : 9007199254740993 }
+	},
+	"gauges" : {
+		"bytes.max" : { "value" : 9223372036854775807 },
+		"bytes.above" : { "value" : 9007199254740993 },
+		"ratio" : { "value" : 0.1234567891 },
+		"overflow" : { "value" : 18446744073709551615 }
+	}
+}
+`
+
+func TestParseLargeIntegerGauges(t *testing.T) {
+	parser := Parser{}
+	metrics, err := parser.Parse([]byte(largeNumbersJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+
+	values := make(map[string]interface{})
+	for _, m := range metrics {
+		values[m.Name()] = m.Fields()["value"]
+	}
+	assert.Equal(t, int64(math.MaxInt64), values["bytes.max"])
+	assert.Equal(t, int64(9007199254740993), values["bytes.above"])
+	assert.Equal(t, 0.1234567891, values["ratio"])
+	// integers out of the int64 range are floats
+	assert.Equal(t, float64(math.MaxUint64), values["overflow"])
+
+	// other metric types keep float fields
+	requests := search(metrics, "requests", nil, "")
+	assert.NotNil(t, requests)
+	assert.Equal(t, float64(9007199254740993), requests.Fields()["count"])
+}
+
+const nonFiniteJSON = `
+{
+	"version": "3.0.0",
+	"gauges" : {
+		"ratio" : { "value" : NaN },
+		"label" : { "value" : "not NaN, Infinity" },
+		"up" : { "value" : 1 },
+		"rate" : { "value" : "Infinity" }
+	},
+	"histograms" : {
+		"sizes" : { "count" : 0, "mean" : -Infinity, "max" : 0 }
+	}
+}
+`
+
+func TestParseNonFinite(t *testing.T) {
+	parser := Parser{}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(nonFiniteJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 3)
+	assert.Nil(t, search(metrics, "ratio", nil, ""))
+	assert.Nil(t, search(metrics, "rate", nil, ""))
+	assert.Equal(t, "not NaN, Infinity", search(metrics, "label", nil, "").Fields()["value"])
+	assert.Equal(t, map[string]interface{}{"count": float64(0), "max": float64(0)},
+		search(metrics, "sizes", nil, "").Fields())
+
+	parser = Parser{NaNPolicy: "zero"}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(nonFiniteJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+	assert.Equal(t, float64(0), search(metrics, "ratio", nil, "").Fields()["value"])
+	assert.Equal(t, float64(0), search(metrics, "sizes", nil, "").Fields()["mean"])
+
+	parser = Parser{NaNPolicy: "emit"}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(nonFiniteJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+	assert.Equal(t, "NaN", search(metrics, "ratio", nil, "").Fields()["value"])
+	assert.Equal(t, "-Infinity", search(metrics, "sizes", nil, "").Fields()["mean"])
+
+	parser = Parser{NaNPolicy: "ignore"}
+	assert.Error(t, parser.Init())
+}
+
+const objectGaugesJSON = `
+{
+	"version": "3.0.0",
+	"gauges" : {
+		"cache.hit-ratio" : { "value" : { "numerator" : 3, "denominator" : 4 } },
+		"pool" : { "value" : { "size" : 10, "usage" : { "active" : 2, "idle" : 8 } } },
+		"up" : { "value" : 1 }
+	}
+}
+`
+
+func TestParseFlattenObjectGauges(t *testing.T) {
+	parser := Parser{}
+	metrics, err := parser.Parse([]byte(objectGaugesJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+
+	parser = Parser{FlattenObjectGauges: true}
+	metrics, err = parser.Parse([]byte(objectGaugesJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 3)
+	assert.Equal(t, map[string]interface{}{
+		"value_numerator":   int64(3),
+		"value_denominator": int64(4),
+	}, search(metrics, "cache.hit-ratio", nil, "").Fields())
+
+	parser = Parser{FlattenObjectGauges: true, FlattenSeparator: "."}
+	metrics, err = parser.Parse([]byte(objectGaugesJSON))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"value.size":         int64(10),
+		"value.usage.active": int64(2),
+		"value.usage.idle":   int64(8),
+	}, search(metrics, "pool", nil, "").Fields())
+}
+
+const gaugeErrorsJSON = `
+{
+	"version": "3.0.0",
+	"gauges" : {
+		"queue.size" : { "error" : "java.lang.IllegalStateException: closed" },
+		"cache.size" : { "value" : 1, "error" : "java.lang.RuntimeException: stale" },
+		"up" : { "value" : 1 }
+	}
+}
+`
+
+func TestParseGaugeErrors(t *testing.T) {
+	parser := Parser{GaugeErrorsMetric: true}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(gaugeErrorsJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	assert.NotNil(t, search(metrics, "up", nil, ""))
+	errors := search(metrics, "dropwizard_gauge_errors", nil, "")
+	assert.NotNil(t, errors)
+	assert.Equal(t, map[string]interface{}{"count": int64(2)}, errors.Fields())
+
+	parser = Parser{GaugeErrorPolicy: "tag"}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(gaugeErrorsJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	cache := search(metrics, "cache.size", nil, "")
+	assert.NotNil(t, cache)
+	assert.Equal(t, map[string]string{"metric_type": "gauge", "error": "true"}, cache.Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(1)}, cache.Fields())
+
+	parser = Parser{GaugeErrorPolicy: "emit"}
+	assert.Error(t, parser.Init())
+}
+
+func TestParseMetricTypes(t *testing.T) {
+	parser := Parser{MetricTypes: []string{"timers", "meter"}}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(validAllJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+	types := make([]string, 0, len(metrics))
+	for _, m := range metrics {
+		types = append(types, m.Tags()["metric_type"])
+	}
+	sort.Strings(types)
+	assert.Equal(t, []string{"meter", "timer"}, types)
+
+	parser = Parser{MetricTypes: []string{"summaries"}}
+	assert.Error(t, parser.Init())
+}
+
+func TestParsePercentiles(t *testing.T) {
+	parser := Parser{Percentiles: []string{"p50", "p99", "p999"}}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(sampleTemplateJSON))
+	assert.NoError(t, err)
+
+	histogram := search(metrics, "jenkins.job.building.duration", nil, "")
+	assert.NotNil(t, histogram)
+	assert.Equal(t, []string{"count", "max", "mean", "min", "p50", "p99", "p999", "stddev"},
+		sortedKeys(histogram.Fields()))
+
+	parser = Parser{Percentiles: []string{"99th"}}
+	assert.Error(t, parser.Init())
+}
+
+const extendedCounterJSON = `
+{
+	"version": "3.0.0",
+	"counters" : {
+		"connections" : { "count" : 12, "max" : 40, "limit" : 64.5 }
+	}
+}
+`
+
+func TestParseExtendedCounterFields(t *testing.T) {
+	parser := Parser{}
+	metrics, err := parser.Parse([]byte(extendedCounterJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"count": float64(12),
+		"max":   float64(40),
+		"limit": float64(64.5),
+	}, metrics[0].Fields())
+}
+
+const samplesJSON = `
+{
+	"version": "3.0.0",
+	"histograms" : {
+		"sizes" : {
+			"count" : 5,
+			"max" : 50,
+			"min" : 10,
+			"p50" : 30,
+			"values" : [ 50, 10, 40, 20, 30 ]
+		}
+	}
+}
+`
+
+func TestParseSamples(t *testing.T) {
+	// the samples are ignored by default
+	parser := Parser{}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(samplesJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, []string{"count", "max", "min", "p50"}, sortedKeys(metrics[0].Fields()))
+
+	parser = Parser{SamplePercentiles: []string{"p50", "p90"}}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(samplesJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, map[string]interface{}{
+		"count": float64(5),
+		"max":   float64(50),
+		"min":   float64(10),
+		"p50":   float64(30),
+		"p90":   float64(50),
+	}, metrics[0].Fields())
+
+	parser = Parser{SamplePercentiles: []string{"p0"}}
+	assert.Error(t, parser.Init())
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{10, 20, 30, 40}
+	assert.Equal(t, float64(10), quantile(values, 0.1))
+	assert.Equal(t, float64(25), quantile(values, 0.5))
+	assert.Equal(t, float64(40), quantile(values, 0.99))
+}
+
+const unitsJSON = `
+{
+	"version": "3.0.0",
+	"meters" : {
+		"requests" : { "count" : 1, "m1_rate" : 2, "mean_rate" : 3, "units" : "events/minute" }
+	},
+	"timers" : {
+		"latency" : {
+			"count" : 1,
+			"max" : 2,
+			"p99" : 3,
+			"m1_rate" : 4,
+			"duration_units" : "milliseconds",
+			"rate_units" : "calls/second"
+		}
+	}
+}
+`
+
+func TestParseUnitSuffixes(t *testing.T) {
+	parser := Parser{UnitSuffixes: true, Fields: map[string][]string{"timer": {"count", "max", "p99", "m1_rate"}}}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(unitsJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 2)
+
+	assert.Equal(t, map[string]interface{}{
+		"count":             float64(1),
+		"m1_rate_per_min":   float64(2),
+		"mean_rate_per_min": float64(3),
+		"units":             "events/minute",
+	}, search(metrics, "requests", nil, "").Fields())
+	assert.Equal(t, map[string]interface{}{
+		"count":           float64(1),
+		"max_ms":          float64(2),
+		"p99_ms":          float64(3),
+		"m1_rate_per_sec": float64(4),
+	}, search(metrics, "latency", nil, "").Fields())
+}
+
+const mixedGaugesJSON = `
+{
+	"version": "3.0.0",
+	"gauges" : {
+		"load" : { "value" : 1.5 },
+		"threads" : { "value" : 12 }
+	}
+}
+`
+
+func TestParseGaugeValueType(t *testing.T) {
+	values := func(valueType string) map[string]interface{} {
+		parser := Parser{GaugeValueType: valueType}
+		assert.NoError(t, parser.Init())
+		metrics, err := parser.Parse([]byte(mixedGaugesJSON))
+		assert.NoError(t, err)
+		values := make(map[string]interface{})
+		for _, m := range metrics {
+			values[m.Name()] = m.Fields()["value"]
+		}
+		return values
+	}
+
+	assert.Equal(t, map[string]interface{}{"load": 1.5, "threads": int64(12)}, values("auto"))
+	assert.Equal(t, map[string]interface{}{"load": 1.5, "threads": float64(12)}, values("float"))
+	assert.Equal(t, map[string]interface{}{"load": int64(1), "threads": int64(12)}, values("int"))
+
+	parser := Parser{GaugeValueType: "string"}
+	assert.Error(t, parser.Init())
+}
+
+const jvmGaugesJSON = `
+{
+	"version": "3.0.0",
+	"gauges": {
+		"jvm.memory.heap.used": {"value": 1024},
+		"jvm.memory.heap.max": {"value": 4096},
+		"jvm.memory.pools.Metaspace.usage": {"value": 0.5},
+		"jvm.gc.PS-MarkSweep.count": {"value": 3},
+		"jvm.gc.PS-MarkSweep.time": {"value": 120},
+		"jvm.threads.count": {"value": 40},
+		"jvm.threads.blocked.count": {"value": 2},
+		"requests.active":               {"value": 7}
+	},
+	"counters": {},
+	"histograms": {},
+	"meters": {},
+	"timers": {}
+}
+`
+
+func TestParseNormalizeJVMMetrics(t *testing.T) {
+	parser := Parser{NormalizeJVMMetrics: true}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(jvmGaugesJSON))
+	assert.NoError(t, err)
+
+	fields := make(map[string]map[string]interface{})
+	for _, m := range metrics {
+		key := m.Name()
+		for _, tag := range []string{"area", "pool", "collector", "state"} {
+			if v, ok := m.Tags()[tag]; ok {
+				key += "," + tag + "=" + v
+			}
+		}
+		assert.Equal(t, "gauge", m.Tags()["metric_type"])
+		fields[key] = m.Fields()
+	}
+
+	assert.Equal(t, map[string]map[string]interface{}{
+		"jvm_memory,area=heap":          {"used": int64(1024), "max": int64(4096)},
+		"jvm_memory,pool=Metaspace":     {"usage": 0.5},
+		"jvm_gc,collector=PS-MarkSweep": {"count": int64(3), "time": int64(120)},
+		"jvm_threads":                   {"count": int64(40)},
+		"jvm_threads,state=blocked":     {"count": int64(2)},
+		"requests.active":               {"value": int64(7)},
+	}, fields)
+}
+
+func TestParseOmitFields(t *testing.T) {
+	parser := Parser{OmitFields: []string{"stddev", "mean*"}}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(sampleTemplateJSON))
+	assert.NoError(t, err)
+
+	histogram := search(metrics, "jenkins.job.building.duration", nil, "")
+	assert.NotNil(t, histogram)
+	assert.Equal(t, []string{"count", "max", "min", "p50", "p75", "p95", "p98", "p99", "p999"},
+		sortedKeys(histogram.Fields()))
+
+	parser = Parser{OmitFields: []string{"["}}
+	assert.Error(t, parser.Init())
+}
+
+func TestParseTimerNanoseconds(t *testing.T) {
+	parser := Parser{TimerNanoseconds: true, Fields: map[string][]string{"timer": {"count", "max", "p99", "m1_rate"}}}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(unitsJSON))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"count":   float64(1),
+		"max":     int64(2000000),
+		"p99":     int64(3000000),
+		"m1_rate": float64(4),
+	}, search(metrics, "latency", nil, "").Fields())
+
+	parser.UnitSuffixes = true
+	metrics, err = parser.Parse([]byte(validTimerJSON))
+	assert.NoError(t, err)
+	fields := metrics[0].Fields()
+	assert.Equal(t, int64(9000000000), fields["p99_ns"])
+	assert.Equal(t, int64(2000000000), fields["max_ns"])
+	assert.Equal(t, float64(13), fields["m1_rate_per_sec"])
+}
+
+func TestParseRegistryVersion(t *testing.T) {
+	parser := Parser{RegistryVersion: "tag"}
+	assert.NoError(t, parser.Init())
+	metrics, err := parser.Parse([]byte(validAllJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 5)
+	for _, m := range metrics {
+		assert.Equal(t, "3.0.0", m.Tags()["registry_version"])
+	}
+
+	parser = Parser{RegistryVersion: "metric"}
+	assert.NoError(t, parser.Init())
+	metrics, err = parser.Parse([]byte(validAllJSON))
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 6)
+	registry := search(metrics, "dropwizard_registry", nil, "")
+	assert.NotNil(t, registry)
+	assert.Equal(t, map[string]interface{}{"version": "3.0.0"}, registry.Fields())
+
+	parser = Parser{RegistryVersion: "field"}
+	assert.Error(t, parser.Init())
+}
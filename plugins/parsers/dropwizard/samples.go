@@ -0,0 +1,73 @@
+package dropwizard
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// percentileQuantile returns the quantile of a percentile field name, the
+// digits following the "p" being the decimals of the quantile, ie, p99 is
+// 0.99 and p999 is 0.999.
+func percentileQuantile(name string) (float64, bool) {
+	if !isPercentile(name) {
+		return 0, false
+	}
+	q, err := strconv.ParseFloat("0."+name[1:], 64)
+	if err != nil || q == 0 {
+		return 0, false
+	}
+	return q, true
+}
+
+// sampleFields computes the min, max and percentile fields of the raw
+// samples of a histogram or timer, the returned bool is false if there are
+// no samples.
+func (p *Parser) sampleFields(samples interface{}) (map[string]interface{}, bool) {
+	array, ok := samples.([]interface{})
+	if !ok || len(array) == 0 {
+		return nil, false
+	}
+
+	values := make([]float64, 0, len(array))
+	for _, sample := range array {
+		switch v := sample.(type) {
+		case json.Number:
+			if f, err := v.Float64(); err == nil {
+				values = append(values, f)
+			}
+		case float64:
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	sort.Float64s(values)
+
+	fields := map[string]interface{}{
+		"min": values[0],
+		"max": values[len(values)-1],
+	}
+	for _, percentile := range p.SamplePercentiles {
+		q, _ := percentileQuantile(percentile)
+		fields[percentile] = quantile(values, q)
+	}
+	return fields, true
+}
+
+// quantile returns the quantile of the sorted values, interpolated as the
+// snapshots of the dropwizard metrics library do.
+func quantile(values []float64, q float64) float64 {
+	pos := q * float64(len(values)+1)
+	index := int(pos)
+	switch {
+	case index < 1:
+		return values[0]
+	case index >= len(values):
+		return values[len(values)-1]
+	}
+	lower := values[index-1]
+	upper := values[index]
+	return lower + (pos-float64(index))*(upper-lower)
+}
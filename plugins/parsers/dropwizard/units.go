@@ -0,0 +1,96 @@
+package dropwizard
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+// durationUnits are the abbreviations of the time units of the dropwizard
+// metrics library, as written by its JSON serializer.
+var durationUnits = map[string]string{
+	"nanoseconds":  "ns",
+	"microseconds": "us",
+	"milliseconds": "ms",
+	"seconds":      "s",
+	"minutes":      "min",
+	"hours":        "h",
+	"days":         "d",
+}
+
+// durationScales are the lengths of the time units of the dropwizard
+// metrics library.
+var durationScales = map[string]time.Duration{
+	"nanoseconds":  time.Nanosecond,
+	"microseconds": time.Microsecond,
+	"milliseconds": time.Millisecond,
+	"seconds":      time.Second,
+	"minutes":      time.Minute,
+	"hours":        time.Hour,
+	"days":         24 * time.Hour,
+}
+
+// rateUnits are the suffixes of the rates per time unit.
+var rateUnits = map[string]string{
+	"nanosecond":  "per_ns",
+	"microsecond": "per_us",
+	"millisecond": "per_ms",
+	"second":      "per_sec",
+	"minute":      "per_min",
+	"hour":        "per_hour",
+	"day":         "per_day",
+}
+
+// unitSuffixes returns the suffixes of the duration and rate fields of a
+// metric, from the duration_units and rate_units members of timers and the
+// units member of meters. Suffixes of unknown units are empty.
+func unitSuffixes(metricType string, fields map[string]interface{}) (string, string) {
+	var durations, rates string
+	switch metricType {
+	case "timer":
+		durations, _ = fields["duration_units"].(string)
+		rates, _ = fields["rate_units"].(string)
+	case "meter":
+		rates, _ = fields["units"].(string)
+	default:
+		return "", ""
+	}
+
+	durationSuffix := durationUnits[durations]
+	var rateSuffix string
+	if i := strings.LastIndexByte(rates, '/'); i >= 0 {
+		rateSuffix = rateUnits[rates[i+1:]]
+	}
+	return durationSuffix, rateSuffix
+}
+
+// unitSuffix returns the suffix of the field, the rates are the mean_rate
+// and mX_rate fields, the durations are the statistics of the timers.
+func unitSuffix(name, durationSuffix, rateSuffix string) string {
+	switch {
+	case rateSuffix != "" && strings.HasSuffix(name, "_rate"):
+		return "_" + rateSuffix
+	case durationSuffix != "" && isDuration(name):
+		return "_" + durationSuffix
+	}
+	return ""
+}
+
+// isDuration returns true for the statistics of timers, which are
+// durations in their duration_units.
+func isDuration(name string) bool {
+	return name == "min" || name == "max" || name == "mean" || name == "stddev" || isPercentile(name)
+}
+
+// durationScale returns the length of the duration_units of a timer, the
+// returned bool is false if the unit is missing or unknown.
+func durationScale(fields map[string]interface{}) (time.Duration, bool) {
+	units, _ := fields["duration_units"].(string)
+	scale, ok := durationScales[units]
+	return scale, ok
+}
+
+// nanoseconds converts a duration in the given unit to integer nanoseconds.
+func nanoseconds(value float64, scale time.Duration) int64 {
+	return int64(math.Floor(value*float64(scale) + 0.5))
+}
@@ -21,8 +21,48 @@ import (
 	"github.com/prometheus/common/expfmt"
 )
 
+// Parser parses the Prometheus text exposition format.
+type Parser struct {
+	DefaultTags map[string]string
+}
+
+// Parse returns a slice of Metrics from the text representation of the
+// Prometheus metrics.
+func (p *Parser) Parse(buf []byte) ([]telegraf.Metric, error) {
+	metrics, err := Parse(buf, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range metrics {
+		for k, v := range p.DefaultTags {
+			if !m.HasTag(k) {
+				m.AddTag(k, v)
+			}
+		}
+	}
+	return metrics, nil
+}
+
+func (p *Parser) ParseLine(line string) (telegraf.Metric, error) {
+	metrics, err := p.Parse([]byte(line + "\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(metrics) < 1 {
+		return nil, fmt.Errorf("Can not parse the line: %s, for data format: prometheus", line)
+	}
+
+	return metrics[0], nil
+}
+
+func (p *Parser) SetDefaultTags(tags map[string]string) {
+	p.DefaultTags = tags
+}
+
 // Parse returns a slice of Metrics from a text representation of a
-// metrics
+// metrics, or from the delimited protocol buffer format according to the
+// Content-Type header.
 func Parse(buf []byte, header http.Header) ([]telegraf.Metric, error) {
 	var metrics []telegraf.Metric
 	var parser expfmt.TextParser
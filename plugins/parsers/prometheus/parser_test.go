@@ -1,11 +1,17 @@
 package prometheus
 
 import (
+	"bytes"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/influxdata/telegraf"
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var exptime = time.Date(2009, time.November, 10, 23, 0, 0, 0, time.UTC)
@@ -163,3 +169,54 @@ func TestParseValidPrometheus(t *testing.T) {
 		metrics[0].Tags())
 
 }
+
+func TestParseValidPrometheusProtobuf(t *testing.T) {
+	families := []*dto.MetricFamily{
+		{
+			Name: proto.String("go_goroutines"),
+			Type: dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{
+				Label: []*dto.LabelPair{{Name: proto.String("job"), Value: proto.String("api")}},
+				Gauge: &dto.Gauge{Value: proto.Float64(42)},
+			}},
+		},
+		{
+			Name: proto.String("http_requests_total"),
+			Type: dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{
+				Label:       []*dto.LabelPair{{Name: proto.String("code"), Value: proto.String("200")}},
+				Counter:     &dto.Counter{Value: proto.Float64(1027)},
+				TimestampMs: proto.Int64(exptime.UnixNano() / 1000000),
+			}},
+		},
+	}
+	var buf bytes.Buffer
+	for _, mf := range families {
+		_, err := pbutil.WriteDelimited(&buf, mf)
+		require.NoError(t, err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", `application/vnd.google.protobuf; proto=io.prometheus.client.MetricFamily; encoding=delimited`)
+	metrics, err := Parse(buf.Bytes(), header)
+	require.NoError(t, err)
+	require.Len(t, metrics, 2)
+
+	byName := make(map[string]telegraf.Metric)
+	for _, m := range metrics {
+		byName[m.Name()] = m
+	}
+
+	gauge := byName["go_goroutines"]
+	require.NotNil(t, gauge)
+	assert.Equal(t, telegraf.Gauge, gauge.Type())
+	assert.Equal(t, map[string]interface{}{"gauge": float64(42)}, gauge.Fields())
+	assert.Equal(t, map[string]string{"job": "api"}, gauge.Tags())
+
+	counter := byName["http_requests_total"]
+	require.NotNil(t, counter)
+	assert.Equal(t, telegraf.Counter, counter.Type())
+	assert.Equal(t, map[string]interface{}{"counter": float64(1027)}, counter.Fields())
+	assert.Equal(t, map[string]string{"code": "200"}, counter.Tags())
+	assert.Equal(t, exptime, counter.Time().UTC())
+}
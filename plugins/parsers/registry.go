@@ -11,6 +11,7 @@ import (
 	"github.com/influxdata/telegraf/plugins/parsers/influx"
 	"github.com/influxdata/telegraf/plugins/parsers/json"
 	"github.com/influxdata/telegraf/plugins/parsers/nagios"
+	"github.com/influxdata/telegraf/plugins/parsers/prometheus"
 	"github.com/influxdata/telegraf/plugins/parsers/value"
 )
 
@@ -83,6 +84,51 @@ type Config struct {
 	// an optional map containing tag names as keys and json paths to retrieve the tag values from as values
 	// used if TagsPath is empty or doesn't return any tags
 	DropwizardTagPathsMap map[string]string
+	// an optional map of field names to the type the field values are
+	// converted to, one of "int", "float", "string" or "bool"
+	DropwizardFieldTypes map[string]string
+	// drop the standard JVM, Jetty and servlet metrics
+	DropwizardExcludeJVMMetrics bool
+	// prefixes removed from the metric names
+	DropwizardStripPrefixes []string
+	// rules rewriting the metric names using regular expressions
+	DropwizardRenames []dropwizard.Rename
+	// an optional map of metric types to the fields kept for that type
+	DropwizardFields map[string][]string
+	// an optional map of derived metric names to arithmetic expressions
+	DropwizardDerived map[string]string
+	// an optional json path containing an object of named metric registries
+	DropwizardRegistriesPath string
+	// the tag holding the name of the registry of the metrics
+	DropwizardRegistryTag string
+	// the handling of the NaN and Infinity values
+	DropwizardNaNPolicy string
+	// flatten the gauges whose value is a json object
+	DropwizardFlattenObjectGauges bool
+	// the separator of the flattened gauge field names
+	DropwizardFlattenSeparator string
+	// the handling of the gauges reporting an error
+	DropwizardGaugeErrorPolicy string
+	// add a metric counting the gauges reporting an error
+	DropwizardGaugeErrorsMetric bool
+	// the types of metrics parsed
+	DropwizardMetricTypes []string
+	// the percentile fields of histograms and timers kept
+	DropwizardPercentiles []string
+	// the percentiles computed from the raw samples of histograms and timers
+	DropwizardSamplePercentiles []string
+	// append the units of durations and rates to the field names
+	DropwizardUnitSuffixes bool
+	// the type of the numeric values of gauges
+	DropwizardGaugeValueType string
+	// map the JVM gauges into structured measurements
+	DropwizardNormalizeJVMMetrics bool
+	// fields dropped from the metrics of all types
+	DropwizardOmitFields []string
+	// convert the statistics of timers to integer nanoseconds
+	DropwizardTimerNanoseconds bool
+	// handling of the version of the registry
+	DropwizardRegistryVersion string
 }
 
 // NewParser returns a Parser interface based on the given config.
@@ -107,9 +153,9 @@ func NewParser(config *Config) (Parser, error) {
 		parser, err = NewCollectdParser(config.CollectdAuthFile,
 			config.CollectdSecurityLevel, config.CollectdTypesDB)
 	case "dropwizard":
-		parser, err = NewDropwizardParser(config.DropwizardMetricRegistryPath,
-			config.DropwizardTimePath, config.DropwizardTimeFormat, config.DropwizardTagsPath, config.DropwizardTagPathsMap, config.DefaultTags,
-			config.Separator, config.Templates)
+		parser, err = newDropwizardParser(config)
+	case "prometheus":
+		parser, err = NewPrometheusParser(config.DefaultTags)
 	default:
 		err = fmt.Errorf("Invalid data format: %s", config.DataFormat)
 	}
@@ -157,6 +203,12 @@ func NewValueParser(
 	}, nil
 }
 
+func NewPrometheusParser(defaultTags map[string]string) (Parser, error) {
+	return &prometheus.Parser{
+		DefaultTags: defaultTags,
+	}, nil
+}
+
 func NewCollectdParser(
 	authFile string,
 	securityLevel string,
@@ -190,3 +242,43 @@ func NewDropwizardParser(
 
 	return parser, err
 }
+
+// newDropwizardParser creates a dropwizard parser with all the dropwizard
+// options of the config.
+func newDropwizardParser(config *Config) (Parser, error) {
+	parser := &dropwizard.Parser{
+		MetricRegistryPath:  config.DropwizardMetricRegistryPath,
+		TimePath:            config.DropwizardTimePath,
+		TimeFormat:          config.DropwizardTimeFormat,
+		TagsPath:            config.DropwizardTagsPath,
+		TagPathsMap:         config.DropwizardTagPathsMap,
+		DefaultTags:         config.DefaultTags,
+		Separator:           config.Separator,
+		Templates:           config.Templates,
+		FieldTypes:          config.DropwizardFieldTypes,
+		ExcludeJVMMetrics:   config.DropwizardExcludeJVMMetrics,
+		StripPrefixes:       config.DropwizardStripPrefixes,
+		Renames:             config.DropwizardRenames,
+		Fields:              config.DropwizardFields,
+		Derived:             config.DropwizardDerived,
+		RegistriesPath:      config.DropwizardRegistriesPath,
+		RegistryTag:         config.DropwizardRegistryTag,
+		NaNPolicy:           config.DropwizardNaNPolicy,
+		FlattenObjectGauges: config.DropwizardFlattenObjectGauges,
+		FlattenSeparator:    config.DropwizardFlattenSeparator,
+		GaugeErrorPolicy:    config.DropwizardGaugeErrorPolicy,
+		GaugeErrorsMetric:   config.DropwizardGaugeErrorsMetric,
+		MetricTypes:         config.DropwizardMetricTypes,
+		Percentiles:         config.DropwizardPercentiles,
+		SamplePercentiles:   config.DropwizardSamplePercentiles,
+		UnitSuffixes:        config.DropwizardUnitSuffixes,
+		GaugeValueType:      config.DropwizardGaugeValueType,
+		NormalizeJVMMetrics: config.DropwizardNormalizeJVMMetrics,
+		OmitFields:          config.DropwizardOmitFields,
+		TimerNanoseconds:    config.DropwizardTimerNanoseconds,
+		RegistryVersion:     config.DropwizardRegistryVersion,
+	}
+	err := parser.Init()
+
+	return parser, err
+}
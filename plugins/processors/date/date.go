@@ -0,0 +1,160 @@
+package date
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## New tag, or field with field_key, holding the date of the metric.
+  tag_key = "weekday"
+  # field_key = ""
+
+  ## Layout of the date, in the Go reference time Mon Jan 2 15:04:05 2006,
+  ## ie, "15" for the hour or "Monday" for the weekday.
+  date_format = "Monday"
+
+  ## Timezone of the date, "UTC", "Local" or a location of the IANA Time
+  ## Zone database, ie, "Europe/Paris".
+  # timezone = "UTC"
+
+  ## Named periods of the day, ie, the shifts, written instead of the date.
+  ## A period ending before its start wraps around midnight. The metrics out
+  ## of the periods are left unchanged.
+  # [[processors.date.period]]
+  #   name = "day"
+  #   start = "06:00"
+  #   end = "18:00"
+  # [[processors.date.period]]
+  #   name = "night"
+  #   start = "18:00"
+  #   end = "06:00"
+`
+
+type Period struct {
+	Name  string `toml:"name"`
+	Start string `toml:"start"`
+	End   string `toml:"end"`
+
+	start, end time.Duration
+}
+
+type Date struct {
+	TagKey     string    `toml:"tag_key"`
+	FieldKey   string    `toml:"field_key"`
+	DateFormat string    `toml:"date_format"`
+	Timezone   string    `toml:"timezone"`
+	Periods    []*Period `toml:"period"`
+
+	location *time.Location
+}
+
+func (d *Date) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Date) Description() string {
+	return "Add a tag or a field from the date of the metrics."
+}
+
+func (d *Date) Init() error {
+	if (d.TagKey == "") == (d.FieldKey == "") {
+		return fmt.Errorf("one of tag_key or field_key must be set")
+	}
+	if (d.DateFormat == "") == (len(d.Periods) == 0) {
+		return fmt.Errorf("one of date_format or period must be set")
+	}
+
+	timezone := d.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	var err error
+	if d.location, err = time.LoadLocation(timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %s", d.Timezone, err)
+	}
+
+	for _, p := range d.Periods {
+		if p.Name == "" {
+			return fmt.Errorf("period %s-%s has no name", p.Start, p.End)
+		}
+		if p.start, err = parseTimeOfDay(p.Start); err != nil {
+			return fmt.Errorf("invalid start of period %q: %s", p.Name, err)
+		}
+		if p.end, err = parseTimeOfDay(p.End); err != nil {
+			return fmt.Errorf("invalid end of period %q: %s", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (d *Date) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		value, ok := d.date(m.Time())
+		if !ok {
+			continue
+		}
+
+		tags := m.Tags()
+		fields := m.Fields()
+		if d.TagKey != "" {
+			tags[d.TagKey] = value
+		} else {
+			fields[d.FieldKey] = value
+		}
+		dated, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.date] could not add date to metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = dated
+	}
+	return in
+}
+
+// date returns the formatted date of t, or the name of its period, the
+// returned bool is false if t is out of the periods.
+func (d *Date) date(t time.Time) (string, bool) {
+	t = t.In(d.location)
+	if d.DateFormat != "" {
+		return t.Format(d.DateFormat), true
+	}
+
+	hour, min, sec := t.Clock()
+	tod := time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second
+	for _, p := range d.Periods {
+		if p.contains(tod) {
+			return p.Name, true
+		}
+	}
+	return "", false
+}
+
+func (p *Period) contains(tod time.Duration) bool {
+	if p.start <= p.end {
+		return tod >= p.start && tod < p.end
+	}
+	return tod >= p.start || tod < p.end
+}
+
+// parseTimeOfDay parses a time of the day, HH:MM, into its duration since
+// midnight.
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func init() {
+	processors.Add("date", func() telegraf.Processor {
+		return &Date{}
+	})
+}
@@ -0,0 +1,98 @@
+package date
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("sales",
+	map[string]string{"shop": "a"},
+	map[string]interface{}{"value": 1},
+	time.Date(2018, 5, 4, 10, 0, 0, 0, time.UTC),
+)
+
+func TestDateFormat(t *testing.T) {
+	d := &Date{TagKey: "weekday", DateFormat: "Monday"}
+	require.NoError(t, d.Init())
+
+	out := d.Apply(m1.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, map[string]string{"shop": "a", "weekday": "Friday"}, out[0].Tags())
+	assert.Equal(t, time.Date(2018, 5, 4, 10, 0, 0, 0, time.UTC), out[0].Time().UTC())
+}
+
+func TestDateField(t *testing.T) {
+	d := &Date{FieldKey: "hour", DateFormat: "15", Timezone: "America/New_York"}
+	require.NoError(t, d.Init())
+
+	out := d.Apply(m1.Copy())
+	assert.Equal(t, map[string]interface{}{"value": int64(1), "hour": "06"}, out[0].Fields())
+}
+
+func TestPeriods(t *testing.T) {
+	d := &Date{
+		TagKey: "shift",
+		Periods: []*Period{
+			{Name: "morning", Start: "06:00", End: "14:00"},
+			{Name: "evening", Start: "14:00", End: "22:00"},
+			{Name: "night", Start: "22:00", End: "02:00"},
+		},
+	}
+	require.NoError(t, d.Init())
+
+	tests := []struct {
+		hour, min int
+		shift     string
+	}{
+		{6, 0, "morning"},
+		{13, 59, "morning"},
+		{14, 0, "evening"},
+		{23, 30, "night"},
+		{1, 0, "night"},
+		{3, 0, ""},
+	}
+	for _, tt := range tests {
+		m, err := metric.New("sales",
+			map[string]string{"shop": "a"},
+			map[string]interface{}{"value": 1},
+			time.Date(2018, 5, 4, tt.hour, tt.min, 0, 0, time.UTC))
+		require.NoError(t, err)
+		out := d.Apply(m)
+		shift, ok := out[0].Tags()["shift"]
+		if tt.shift == "" {
+			assert.False(t, ok, "%02d:%02d", tt.hour, tt.min)
+		} else {
+			assert.Equal(t, tt.shift, shift, "%02d:%02d", tt.hour, tt.min)
+		}
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		date *Date
+		err  string
+	}{
+		{"no key", &Date{DateFormat: "15"},
+			"one of tag_key or field_key must be set"},
+		{"both keys", &Date{TagKey: "a", FieldKey: "b", DateFormat: "15"},
+			"one of tag_key or field_key must be set"},
+		{"no format", &Date{TagKey: "a"},
+			"one of date_format or period must be set"},
+		{"bad timezone", &Date{TagKey: "a", DateFormat: "15", Timezone: "Mars/Olympus"},
+			`invalid timezone "Mars/Olympus": unknown time zone Mars/Olympus`},
+		{"bad period", &Date{TagKey: "a", Periods: []*Period{{Name: "a", Start: "25:00", End: "01:00"}}},
+			`invalid start of period "a": parsing time "25:00": hour out of range`},
+		{"unnamed period", &Date{TagKey: "a", Periods: []*Period{{Start: "01:00", End: "02:00"}}},
+			"period 01:00-02:00 has no name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, tt.date.Init(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package dedup
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Maximum time to suppress the metrics whose field values haven't
+  ## changed, a metric is passed at least once per interval.
+  dedup_interval = "600s"
+`
+
+type Dedup struct {
+	DedupInterval internal.Duration `toml:"dedup_interval"`
+
+	// cache is the last passed metric of each series
+	cache       map[uint64]telegraf.Metric
+	lastCleanup time.Time
+}
+
+func (d *Dedup) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *Dedup) Description() string {
+	return "Drop metrics whose field values haven't changed within an interval."
+}
+
+func (d *Dedup) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := in[:0]
+	for _, m := range in {
+		id := m.HashID()
+		cached, ok := d.cache[id]
+		if ok && !d.changed(cached, m) {
+			continue
+		}
+		d.cache[id] = m
+		out = append(out, m)
+	}
+	d.cleanup()
+	return out
+}
+
+// changed returns true if the fields of the metric differ from the cached
+// one, or if the cached metric is older than the interval.
+func (d *Dedup) changed(cached, m telegraf.Metric) bool {
+	if m.Time().Sub(cached.Time()) >= d.DedupInterval.Duration {
+		return true
+	}
+	return !reflect.DeepEqual(cached.Fields(), m.Fields())
+}
+
+// cleanup removes the series which have not been passed within the
+// interval, once per interval.
+func (d *Dedup) cleanup() {
+	now := time.Now()
+	if now.Sub(d.lastCleanup) < d.DedupInterval.Duration {
+		return
+	}
+	d.lastCleanup = now
+
+	threshold := now.Add(-d.DedupInterval.Duration)
+	for id, m := range d.cache {
+		if m.Time().Before(threshold) {
+			delete(d.cache, id)
+		}
+	}
+}
+
+func init() {
+	processors.Add("dedup", func() telegraf.Processor {
+		return &Dedup{
+			DedupInterval: internal.Duration{Duration: 10 * time.Minute},
+			cache:         make(map[uint64]telegraf.Metric),
+		}
+	})
+}
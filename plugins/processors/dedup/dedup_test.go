@@ -0,0 +1,98 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDedup() *Dedup {
+	return &Dedup{
+		DedupInterval: internal.Duration{Duration: 10 * time.Minute},
+		cache:         make(map[uint64]telegraf.Metric),
+	}
+}
+
+var now = time.Now()
+
+var a10, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(10)},
+	now,
+)
+var a10At1m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(time.Minute),
+)
+var b10At1m, _ = metric.New("pool",
+	map[string]string{"pool": "b"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(time.Minute),
+)
+var a12At2m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(12)},
+	now.Add(2*time.Minute),
+)
+var a12At3m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(12)},
+	now.Add(3*time.Minute),
+)
+var a10At9m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(9*time.Minute),
+)
+var a10At10m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(10*time.Minute),
+)
+var a10At15m, _ = metric.New("pool",
+	map[string]string{"pool": "a"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(15*time.Minute),
+)
+var old10, _ = metric.New("pool",
+	map[string]string{"pool": "old"},
+	map[string]interface{}{"size": int64(10)},
+	now.Add(-time.Hour),
+)
+var new10, _ = metric.New("pool",
+	map[string]string{"pool": "new"},
+	map[string]interface{}{"size": int64(10)},
+	now,
+)
+
+func TestSuppressUnchanged(t *testing.T) {
+	d := newDedup()
+	assert.Len(t, d.Apply(a10), 1)
+	// unchanged within the interval
+	assert.Len(t, d.Apply(a10At1m), 0)
+	// another series
+	assert.Len(t, d.Apply(b10At1m), 1)
+	// changed
+	assert.Len(t, d.Apply(a12At2m), 1)
+	assert.Len(t, d.Apply(a12At3m), 0)
+}
+
+func TestPassOncePerInterval(t *testing.T) {
+	d := newDedup()
+	assert.Len(t, d.Apply(a10), 1)
+	assert.Len(t, d.Apply(a10At9m), 0)
+	assert.Len(t, d.Apply(a10At10m), 1)
+	assert.Len(t, d.Apply(a10At15m), 0)
+}
+
+func TestCleanup(t *testing.T) {
+	d := newDedup()
+	d.Apply(old10)
+	d.Apply(new10)
+	assert.Len(t, d.cache, 1)
+}
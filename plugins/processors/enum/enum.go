@@ -0,0 +1,144 @@
+package enum
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Map the values of a string field to numbers.
+  [[processors.enum.mapping]]
+    ## Name of the field to map
+    field = "status"
+
+    ## Destination field of the number, by default the field is replaced.
+    # dest = "status_code"
+
+    ## Number of the values without a mapping, by default they are left
+    ## unchanged.
+    # default = 0
+
+    ## Table of the numbers of the values. The numbers are all written as
+    ## floats if one of them is a float.
+    [processors.enum.mapping.value_mappings]
+      healthy = 1
+      degraded = 0.5
+      down = 0
+`
+
+type Mapping struct {
+	Field         string                 `toml:"field"`
+	Dest          string                 `toml:"dest"`
+	Default       interface{}            `toml:"default"`
+	ValueMappings map[string]interface{} `toml:"value_mappings"`
+}
+
+type EnumMapper struct {
+	Mappings []*Mapping `toml:"mapping"`
+}
+
+func (e *EnumMapper) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *EnumMapper) Description() string {
+	return "Map the values of string fields to numbers."
+}
+
+func (e *EnumMapper) Init() error {
+	for _, m := range e.Mappings {
+		if m.Field == "" {
+			return fmt.Errorf("mapping has no field")
+		}
+
+		floats := false
+		values := []interface{}{m.Default}
+		for _, v := range m.ValueMappings {
+			values = append(values, v)
+		}
+		for _, v := range values {
+			switch v.(type) {
+			case nil, int64:
+			case float64:
+				floats = true
+			default:
+				return fmt.Errorf("mapping of field %q has a value %v which is not a number", m.Field, v)
+			}
+		}
+
+		// a field has a single type, the integers are written as floats
+		// along the floats
+		if floats {
+			for k, v := range m.ValueMappings {
+				m.ValueMappings[k] = toFloat(v)
+			}
+			if m.Default != nil {
+				m.Default = toFloat(m.Default)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *EnumMapper) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		fields := m.Fields()
+		changed := false
+		for _, mapping := range e.Mappings {
+			if mapping.apply(fields) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		mapped, err := metric.New(m.Name(), m.Tags(), fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.enum] could not map metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = mapped
+	}
+	return in
+}
+
+// apply writes the number of the value of the field, and returns true if
+// the value has a mapping or a default.
+func (m *Mapping) apply(fields map[string]interface{}) bool {
+	value, ok := fields[m.Field].(string)
+	if !ok {
+		return false
+	}
+	number, ok := m.ValueMappings[value]
+	if !ok {
+		if m.Default == nil {
+			return false
+		}
+		number = m.Default
+	}
+
+	dest := m.Field
+	if m.Dest != "" {
+		dest = m.Dest
+	}
+	fields[dest] = number
+	return true
+}
+
+func toFloat(v interface{}) float64 {
+	if i, ok := v.(int64); ok {
+		return float64(i)
+	}
+	return v.(float64)
+}
+
+func init() {
+	processors.Add("enum", func() telegraf.Processor {
+		return &EnumMapper{}
+	})
+}
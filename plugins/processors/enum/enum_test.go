@@ -0,0 +1,89 @@
+package enum
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("health",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": "amber", "message": "slow"},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("health",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": "unknown"},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("health",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": int64(7)},
+	time.Unix(0, 0),
+)
+var m4, _ = metric.New("health",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": "healthy"},
+	time.Unix(0, 0),
+)
+var m5, _ = metric.New("health",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"status": "degraded"},
+	time.Unix(0, 0),
+)
+
+func TestMapping(t *testing.T) {
+	e := &EnumMapper{Mappings: []*Mapping{{
+		Field:         "status",
+		ValueMappings: map[string]interface{}{"green": int64(1), "amber": int64(2), "red": int64(3)},
+	}}}
+	require.NoError(t, e.Init())
+
+	out := e.Apply(
+		m1.Copy(),
+		m2.Copy(),
+		m3.Copy(),
+	)
+	require.Len(t, out, 3)
+	assert.Equal(t, map[string]interface{}{"status": int64(2), "message": "slow"}, out[0].Fields())
+	// values without a mapping are left unchanged
+	assert.Equal(t, map[string]interface{}{"status": "unknown"}, out[1].Fields())
+	assert.Equal(t, map[string]interface{}{"status": int64(7)}, out[2].Fields())
+	assert.Equal(t, map[string]string{"app": "shop"}, out[0].Tags())
+}
+
+func TestMappingDestAndDefault(t *testing.T) {
+	e := &EnumMapper{Mappings: []*Mapping{{
+		Field:         "status",
+		Dest:          "status_code",
+		Default:       int64(0),
+		ValueMappings: map[string]interface{}{"healthy": int64(1), "degraded": 0.5, "down": int64(0)},
+	}}}
+	require.NoError(t, e.Init())
+
+	out := e.Apply(
+		m4.Copy(),
+		m5.Copy(),
+		m2.Copy(),
+	)
+	// the integers are floats along the float of degraded
+	assert.Equal(t, map[string]interface{}{"status": "healthy", "status_code": 1.0}, out[0].Fields())
+	assert.Equal(t, map[string]interface{}{"status": "degraded", "status_code": 0.5}, out[1].Fields())
+	assert.Equal(t, map[string]interface{}{"status": "unknown", "status_code": 0.0}, out[2].Fields())
+}
+
+func TestInvalidMappings(t *testing.T) {
+	e := &EnumMapper{Mappings: []*Mapping{{
+		ValueMappings: map[string]interface{}{"a": int64(1)},
+	}}}
+	assert.EqualError(t, e.Init(), "mapping has no field")
+
+	e = &EnumMapper{Mappings: []*Mapping{{
+		Field:         "status",
+		ValueMappings: map[string]interface{}{"a": "b"},
+	}}}
+	assert.EqualError(t, e.Init(), `mapping of field "status" has a value b which is not a number`)
+}
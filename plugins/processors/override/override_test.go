@@ -78,3 +78,14 @@ func TestNameSuffix(t *testing.T) {
 
 	assert.Equal(t, "m1-suff", processed[0].Name(), "Suffix was not applied")
 }
+
+func TestKeepsTagsEndingWithAddedKey(t *testing.T) {
+	processor := Override{Tags: map[string]string{"tag": "from_config"}}
+
+	tags := calculateProcessedTags(processor, createTestMetric())
+
+	assert.Equal(t, map[string]string{
+		"metric_tag": "from_metric",
+		"tag":        "from_config",
+	}, tags)
+}
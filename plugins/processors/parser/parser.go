@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+// pluginName is the name of the metrics of the data formats without
+// measurement names, the parsers are named after the plugin.
+const pluginName = "parser"
+
+var sampleConfig = `
+  ## The string fields to parse.
+  parse_fields = ["message"]
+
+  ## Drop the original metric, only the parsed metrics are passed.
+  # drop_original = false
+
+  ## Merge the fields and tags of the parsed metrics into the original
+  ## metric, overriding the fields and tags of the same keys, instead of
+  ## passing the parsed metrics separately. Valid values are "" and
+  ## "override".
+  # merge = ""
+
+  ## Data format of the fields to consume.
+  ## Each data format has its own unique set of configuration options, read
+  ## more about them here:
+  ## https://github.com/influxdata/telegraf/blob/master/docs/DATA_FORMATS_INPUT.md
+  data_format = "json"
+`
+
+type Parser struct {
+	ParseFields  []string `toml:"parse_fields"`
+	DropOriginal bool     `toml:"drop_original"`
+	Merge        string   `toml:"merge"`
+
+	parser parsers.Parser
+}
+
+func (p *Parser) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Parser) Description() string {
+	return "Parse a string field of the metrics with a data format."
+}
+
+func (p *Parser) SetParser(parser parsers.Parser) {
+	p.parser = parser
+}
+
+func (p *Parser) Init() error {
+	if p.parser == nil {
+		return fmt.Errorf("no parser is set")
+	}
+	if len(p.ParseFields) == 0 {
+		return fmt.Errorf("parse_fields is empty")
+	}
+	if p.Merge != "" && p.Merge != "override" {
+		return fmt.Errorf("invalid merge %q, must be empty or \"override\"", p.Merge)
+	}
+	if p.Merge != "" && p.DropOriginal {
+		return fmt.Errorf("merge and drop_original cannot be set together")
+	}
+	return nil
+}
+
+func (p *Parser) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		var parsed []telegraf.Metric
+		fields := m.Fields()
+		for _, key := range p.ParseFields {
+			value, ok := fields[key].(string)
+			if !ok {
+				continue
+			}
+			metrics, err := p.parser.Parse([]byte(value))
+			if err != nil {
+				log.Printf("E! [processors.parser] could not parse field %s of metric %s: %s",
+					key, m.Name(), err)
+				continue
+			}
+			parsed = append(parsed, metrics...)
+		}
+
+		switch {
+		case p.Merge == "override":
+			out = append(out, merge(m, parsed))
+		case p.DropOriginal:
+			out = append(out, p.inherit(m, parsed)...)
+		default:
+			out = append(out, m)
+			out = append(out, p.inherit(m, parsed)...)
+		}
+	}
+	return out
+}
+
+// merge returns the original metric with the fields and tags of the parsed
+// metrics.
+func merge(m telegraf.Metric, parsed []telegraf.Metric) telegraf.Metric {
+	if len(parsed) == 0 {
+		return m
+	}
+	tags := m.Tags()
+	fields := m.Fields()
+	for _, pm := range parsed {
+		for k, v := range pm.Tags() {
+			tags[k] = v
+		}
+		for k, v := range pm.Fields() {
+			fields[k] = v
+		}
+	}
+	merged, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+	if err != nil {
+		log.Printf("E! [processors.parser] could not merge metric %s: %s", m.Name(), err)
+		return m
+	}
+	return merged
+}
+
+// inherit returns the parsed metrics with the tags of the original metric,
+// the metrics of the data formats without measurement names are named after
+// the original metric.
+func (p *Parser) inherit(m telegraf.Metric, parsed []telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(parsed))
+	for _, pm := range parsed {
+		name := pm.Name()
+		if name == pluginName {
+			name = m.Name()
+		}
+		tags := m.Tags()
+		for k, v := range pm.Tags() {
+			tags[k] = v
+		}
+		inherited, err := metric.New(name, tags, pm.Fields(), pm.Time(), pm.Type())
+		if err != nil {
+			log.Printf("E! [processors.parser] could not create metric %s: %s", name, err)
+			continue
+		}
+		out = append(out, inherited)
+	}
+	return out
+}
+
+func init() {
+	processors.Add("parser", func() telegraf.Processor {
+		return &Parser{}
+	})
+}
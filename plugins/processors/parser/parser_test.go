@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("events",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"message": `{"level": "warn", "latency": 12.5}`, "count": int64(1)},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("events",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"message": "cpu,host=a usage=0.5 1000000000\nmem,host=a used=10i 1000000000\n", "count": int64(1)},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("events",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"message": `{"level": "warn", "count": 3}`, "count": int64(1)},
+	time.Unix(0, 0),
+)
+var m4, _ = metric.New("events",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"message": "not json", "count": int64(1)},
+	time.Unix(0, 0),
+)
+
+func newParser(t *testing.T, dataFormat string) parsers.Parser {
+	parser, err := parsers.NewParser(&parsers.Config{
+		DataFormat: dataFormat,
+		MetricName: pluginName,
+		TagKeys:    []string{"level"},
+	})
+	require.NoError(t, err)
+	return parser
+}
+
+func TestParseSeparately(t *testing.T) {
+	p := &Parser{ParseFields: []string{"message"}}
+	p.SetParser(newParser(t, "json"))
+	require.NoError(t, p.Init())
+
+	out := p.Apply(m1.Copy())
+	require.Len(t, out, 2)
+	assert.Equal(t, "shop", out[0].Tags()["app"])
+	assert.Equal(t, int64(1), out[0].Fields()["count"])
+
+	// named after the original metric, with its tags
+	assert.Equal(t, "events", out[1].Name())
+	assert.Equal(t, map[string]string{"app": "shop", "level": "warn"}, out[1].Tags())
+	assert.Equal(t, map[string]interface{}{"latency": 12.5}, out[1].Fields())
+}
+
+func TestDropOriginal(t *testing.T) {
+	p := &Parser{ParseFields: []string{"message"}, DropOriginal: true}
+	p.SetParser(newParser(t, "influx"))
+	require.NoError(t, p.Init())
+
+	out := p.Apply(m2.Copy())
+	require.Len(t, out, 2)
+	assert.Equal(t, "cpu", out[0].Name())
+	assert.Equal(t, map[string]string{"app": "shop", "host": "a"}, out[0].Tags())
+	assert.Equal(t, "mem", out[1].Name())
+	assert.Equal(t, time.Unix(1, 0), out[1].Time())
+}
+
+func TestMergeOverride(t *testing.T) {
+	p := &Parser{ParseFields: []string{"message"}, Merge: "override"}
+	p.SetParser(newParser(t, "json"))
+	require.NoError(t, p.Init())
+
+	out := p.Apply(m3.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, "events", out[0].Name())
+	assert.Equal(t, map[string]string{"app": "shop", "level": "warn"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"message": `{"level": "warn", "count": 3}`,
+		"count":   3.0,
+	}, out[0].Fields())
+	assert.Equal(t, time.Unix(0, 0), out[0].Time())
+}
+
+func TestParseError(t *testing.T) {
+	p := &Parser{ParseFields: []string{"message"}, DropOriginal: true}
+	p.SetParser(newParser(t, "json"))
+	require.NoError(t, p.Init())
+
+	out := p.Apply(m4.Copy())
+	assert.Len(t, out, 0)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	p := &Parser{ParseFields: []string{"message"}}
+	assert.EqualError(t, p.Init(), "no parser is set")
+
+	p.SetParser(newParser(t, "json"))
+	p.Merge = "append"
+	assert.EqualError(t, p.Init(), `invalid merge "append", must be empty or "override"`)
+
+	p.Merge = "override"
+	p.DropOriginal = true
+	assert.EqualError(t, p.Init(), "merge and drop_original cannot be set together")
+
+	p = &Parser{}
+	p.SetParser(newParser(t, "json"))
+	assert.EqualError(t, p.Init(), "parse_fields is empty")
+}
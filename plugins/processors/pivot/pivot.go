@@ -0,0 +1,61 @@
+package pivot
+
+import (
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Tag to use for naming the new field.
+  tag_key = "name"
+  ## Field to use as the value of the new field.
+  value_key = "value"
+`
+
+type Pivot struct {
+	TagKey   string `toml:"tag_key"`
+	ValueKey string `toml:"value_key"`
+}
+
+func (p *Pivot) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Pivot) Description() string {
+	return "Rotate a single valued metric using the tag key as the field key."
+}
+
+func (p *Pivot) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		tags := m.Tags()
+		key, ok := tags[p.TagKey]
+		if !ok {
+			continue
+		}
+		fields := m.Fields()
+		value, ok := fields[p.ValueKey]
+		if !ok {
+			continue
+		}
+
+		delete(tags, p.TagKey)
+		delete(fields, p.ValueKey)
+		fields[key] = value
+		pivoted, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.pivot] could not pivot metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = pivoted
+	}
+	return in
+}
+
+func init() {
+	processors.Add("pivot", func() telegraf.Processor {
+		return &Pivot{}
+	})
+}
@@ -0,0 +1,41 @@
+package pivot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("dropwizard",
+	map[string]string{"name": "pool_size", "app": "shop"},
+	map[string]interface{}{"value": int64(10)},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("dropwizard",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"value": int64(10)},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("dropwizard",
+	map[string]string{"name": "pool_size"},
+	map[string]interface{}{"count": int64(10)},
+	time.Unix(0, 0),
+)
+
+func TestPivot(t *testing.T) {
+	p := &Pivot{TagKey: "name", ValueKey: "value"}
+
+	out := p.Apply(m1.Copy(), m2.Copy(), m3.Copy())
+	require.Len(t, out, 3)
+	assert.Equal(t, map[string]string{"app": "shop"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"pool_size": int64(10)}, out[0].Fields())
+	assert.Equal(t, time.Unix(0, 0), out[0].Time())
+
+	// metrics without the tag or the field are left unchanged
+	assert.Equal(t, map[string]interface{}{"value": int64(10)}, out[1].Fields())
+	assert.Equal(t, map[string]string{"name": "pool_size"}, out[2].Tags())
+	assert.Equal(t, map[string]interface{}{"count": int64(10)}, out[2].Fields())
+}
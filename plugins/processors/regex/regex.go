@@ -0,0 +1,218 @@
+package regex
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Rewrite the values of tags, the key may be a glob pattern.
+  ## The replacement may reference the groups of the pattern, ie, ${1}.
+  # [[processors.regex.tags]]
+  #   key = "resp_code"
+  #   pattern = "^(\\d)\\d\\d$"
+  #   replacement = "${1}xx"
+  #   ## Write the new value to another tag and keep the original.
+  #   # result_key = "resp_class"
+
+  ## Rewrite the values of string fields.
+  # [[processors.regex.fields]]
+  #   key = "request"
+  #   pattern = "^/api(?P<method>/[\\w/]+)\\S*"
+  #   replacement = "${method}"
+  #   # result_key = "method"
+
+  ## Rename the tags, fields and measurements matching the pattern, ie, the
+  ## long dotted names of the dropwizard metrics.
+  # [[processors.regex.tag_rename]]
+  #   pattern = "\\."
+  #   replacement = "_"
+  # [[processors.regex.field_rename]]
+  #   pattern = "^(.*)_ms$"
+  #   replacement = "${1}"
+  # [[processors.regex.metric_rename]]
+  #   pattern = "^com\\.example\\.(.*)$"
+  #   replacement = "${1}"
+`
+
+// Converter rewrites the values of the tags or fields matching Key with the
+// regular expression.
+type Converter struct {
+	Key         string `toml:"key"`
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+	ResultKey   string `toml:"result_key"`
+
+	key   filter.Filter
+	regex *regexp.Regexp
+}
+
+// Renamer renames the tags, fields or measurements matching the regular
+// expression.
+type Renamer struct {
+	Pattern     string `toml:"pattern"`
+	Replacement string `toml:"replacement"`
+
+	regex *regexp.Regexp
+}
+
+type Regex struct {
+	Tags         []*Converter `toml:"tags"`
+	Fields       []*Converter `toml:"fields"`
+	TagRename    []*Renamer   `toml:"tag_rename"`
+	FieldRename  []*Renamer   `toml:"field_rename"`
+	MetricRename []*Renamer   `toml:"metric_rename"`
+}
+
+func (r *Regex) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Regex) Description() string {
+	return "Transform tags, fields and measurement names with regex patterns."
+}
+
+func (r *Regex) Init() error {
+	var converters []*Converter
+	converters = append(converters, r.Tags...)
+	converters = append(converters, r.Fields...)
+	for _, c := range converters {
+		if c.Key == "" {
+			return fmt.Errorf("conversion of pattern %q has no key", c.Pattern)
+		}
+		var err error
+		if c.key, err = filter.Compile([]string{c.Key}); err != nil {
+			return err
+		}
+		if c.regex, err = regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %s", c.Pattern, err)
+		}
+	}
+	for _, rn := range r.renamers() {
+		var err error
+		if rn.regex, err = regexp.Compile(rn.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %s", rn.Pattern, err)
+		}
+	}
+	return nil
+}
+
+func (r *Regex) renamers() []*Renamer {
+	var all []*Renamer
+	all = append(all, r.TagRename...)
+	all = append(all, r.FieldRename...)
+	return append(all, r.MetricRename...)
+}
+
+func (r *Regex) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		name := m.Name()
+		tags := m.Tags()
+		fields := m.Fields()
+		changed := false
+
+		// the result keys are added after matching the keys, so that a new
+		// tag or field is not rewritten again
+		for _, c := range r.Tags {
+			results := make(map[string]string)
+			for key, value := range tags {
+				if c.key.Match(key) {
+					if v, ok := c.replace(value); ok {
+						results[c.resultKey(key)] = v
+					}
+				}
+			}
+			for key, value := range results {
+				tags[key] = value
+				changed = true
+			}
+		}
+		for _, c := range r.Fields {
+			results := make(map[string]interface{})
+			for key, value := range fields {
+				s, ok := value.(string)
+				if ok && c.key.Match(key) {
+					if v, ok := c.replace(s); ok {
+						results[c.resultKey(key)] = v
+					}
+				}
+			}
+			for key, value := range results {
+				fields[key] = value
+				changed = true
+			}
+		}
+
+		for _, rn := range r.TagRename {
+			renamed := make(map[string]string, len(tags))
+			for key, value := range tags {
+				if newKey := rn.rename(key); newKey != key {
+					changed = true
+					key = newKey
+				}
+				renamed[key] = value
+			}
+			tags = renamed
+		}
+		for _, rn := range r.FieldRename {
+			renamed := make(map[string]interface{}, len(fields))
+			for key, value := range fields {
+				if newKey := rn.rename(key); newKey != key {
+					changed = true
+					key = newKey
+				}
+				renamed[key] = value
+			}
+			fields = renamed
+		}
+		for _, rn := range r.MetricRename {
+			if newName := rn.rename(name); newName != name {
+				changed = true
+				name = newName
+			}
+		}
+
+		if !changed {
+			continue
+		}
+		rewritten, err := metric.New(name, tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.regex] could not rewrite metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = rewritten
+	}
+	return in
+}
+
+// replace returns the value rewritten with the replacement, and false if the
+// value does not match the pattern.
+func (c *Converter) replace(value string) (string, bool) {
+	if !c.regex.MatchString(value) {
+		return "", false
+	}
+	return c.regex.ReplaceAllString(value, c.Replacement), true
+}
+
+func (c *Converter) resultKey(key string) string {
+	if c.ResultKey != "" {
+		return c.ResultKey
+	}
+	return key
+}
+
+func (rn *Renamer) rename(name string) string {
+	return rn.regex.ReplaceAllString(name, rn.Replacement)
+}
+
+func init() {
+	processors.Add("regex", func() telegraf.Processor {
+		return &Regex{}
+	})
+}
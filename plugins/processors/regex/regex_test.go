@@ -0,0 +1,104 @@
+package regex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("access",
+	map[string]string{"resp_code": "404", "path": "/api/users/1"},
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("access",
+	map[string]string{"resp_code": "ok"},
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("access",
+	nil,
+	map[string]interface{}{
+		"request": "GET /index.html",
+		"bytes":   int64(512),
+	},
+	time.Unix(0, 0),
+)
+var m4, _ = metric.New("com.example.requests",
+	map[string]string{"app.name": "shop"},
+	map[string]interface{}{"max_ms": 2.5, "count": int64(3)},
+	time.Unix(0, 0),
+)
+var m5, _ = metric.New("cpu",
+	nil,
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+
+func TestTagConversion(t *testing.T) {
+	r := &Regex{Tags: []*Converter{
+		{Key: "resp_code", Pattern: `^(\d)\d\d$`, Replacement: "${1}xx"},
+		{Key: "path", Pattern: `^/api/(\w+).*$`, Replacement: "${1}", ResultKey: "api"},
+	}}
+	require.NoError(t, r.Init())
+
+	out := r.Apply(m1.Copy(), m2.Copy())
+	require.Len(t, out, 2)
+	assert.Equal(t, map[string]string{
+		"resp_code": "4xx",
+		"path":      "/api/users/1",
+		"api":       "users",
+	}, out[0].Tags())
+	// values not matching the pattern are left unchanged
+	assert.Equal(t, map[string]string{"resp_code": "ok"}, out[1].Tags())
+}
+
+func TestFieldConversion(t *testing.T) {
+	r := &Regex{Fields: []*Converter{
+		{Key: "req*", Pattern: `^(GET|POST) .*$`, Replacement: "${1}", ResultKey: "method"},
+	}}
+	require.NoError(t, r.Init())
+
+	out := r.Apply(m3.Copy())
+	assert.Equal(t, map[string]interface{}{
+		"request": "GET /index.html",
+		"method":  "GET",
+		"bytes":   int64(512),
+	}, out[0].Fields())
+}
+
+func TestRename(t *testing.T) {
+	r := &Regex{
+		TagRename:    []*Renamer{{Pattern: `\.`, Replacement: "_"}},
+		FieldRename:  []*Renamer{{Pattern: `^(.*)_ms$`, Replacement: "${1}"}},
+		MetricRename: []*Renamer{{Pattern: `^com\.example\.(.*)$`, Replacement: "${1}"}},
+	}
+	require.NoError(t, r.Init())
+
+	out := r.Apply(m4.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, "requests", out[0].Name())
+	assert.Equal(t, map[string]string{"app_name": "shop"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"max": 2.5, "count": int64(3)}, out[0].Fields())
+}
+
+func TestNoChange(t *testing.T) {
+	r := &Regex{MetricRename: []*Renamer{{Pattern: `^jvm\.`, Replacement: "jvm_"}}}
+	require.NoError(t, r.Init())
+
+	m := m5.Copy()
+	out := r.Apply(m)
+	assert.True(t, m == out[0])
+}
+
+func TestInvalidPattern(t *testing.T) {
+	r := &Regex{FieldRename: []*Renamer{{Pattern: `(`}}}
+	assert.EqualError(t, r.Init(),
+		"invalid pattern \"(\": error parsing regexp: missing closing ): `(`")
+
+	r = &Regex{Tags: []*Converter{{Pattern: `a`}}}
+	assert.EqualError(t, r.Init(), `conversion of pattern "a" has no key`)
+}
@@ -0,0 +1,113 @@
+package rename
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Each replacement renames one measurement, tag key or field key to dest.
+  ## The replacements are applied in order.
+  # [[processors.rename.replace]]
+  #   measurement = "com.example.shop.OrderService.requests"
+  #   dest = "order_requests"
+
+  # [[processors.rename.replace]]
+  #   tag = "hostname"
+  #   dest = "host"
+
+  # [[processors.rename.replace]]
+  #   field = "lower"
+  #   dest = "min"
+`
+
+type Replace struct {
+	Measurement string `toml:"measurement"`
+	Tag         string `toml:"tag"`
+	Field       string `toml:"field"`
+	Dest        string `toml:"dest"`
+}
+
+type Rename struct {
+	Replaces []Replace `toml:"replace"`
+}
+
+func (r *Rename) SampleConfig() string {
+	return sampleConfig
+}
+
+func (r *Rename) Description() string {
+	return "Rename measurements, tags, and fields that pass through this filter."
+}
+
+func (r *Rename) Init() error {
+	for _, replace := range r.Replaces {
+		set := 0
+		for _, s := range []string{replace.Measurement, replace.Tag, replace.Field} {
+			if s != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("replace to %q must set one of measurement, tag or field", replace.Dest)
+		}
+		if replace.Dest == "" {
+			// only one of them is set
+			source := replace.Measurement + replace.Tag + replace.Field
+			return fmt.Errorf("replace of %q has no dest", source)
+		}
+	}
+	return nil
+}
+
+func (r *Rename) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		name := m.Name()
+		tags := m.Tags()
+		fields := m.Fields()
+		changed := false
+
+		for _, replace := range r.Replaces {
+			switch {
+			case replace.Measurement != "":
+				if name == replace.Measurement {
+					name = replace.Dest
+					changed = true
+				}
+			case replace.Tag != "":
+				if value, ok := tags[replace.Tag]; ok {
+					delete(tags, replace.Tag)
+					tags[replace.Dest] = value
+					changed = true
+				}
+			case replace.Field != "":
+				if value, ok := fields[replace.Field]; ok {
+					delete(fields, replace.Field)
+					fields[replace.Dest] = value
+					changed = true
+				}
+			}
+		}
+
+		if !changed {
+			continue
+		}
+		renamed, err := metric.New(name, tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.rename] could not rename metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = renamed
+	}
+	return in
+}
+
+func init() {
+	processors.Add("rename", func() telegraf.Processor {
+		return &Rename{}
+	})
+}
@@ -0,0 +1,73 @@
+package rename
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("com.example.OrderService.requests",
+	map[string]string{"hostname": "a", "region": "eu"},
+	map[string]interface{}{"lower": 1.0, "count": int64(2)},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("a",
+	nil,
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("cpu",
+	map[string]string{"host": "a"},
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+
+func TestRename(t *testing.T) {
+	r := &Rename{Replaces: []Replace{
+		{Measurement: "com.example.OrderService.requests", Dest: "order_requests"},
+		{Tag: "hostname", Dest: "host"},
+		{Field: "lower", Dest: "min"},
+		{Field: "upper", Dest: "max"},
+	}}
+	require.NoError(t, r.Init())
+
+	out := r.Apply(m1.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, "order_requests", out[0].Name())
+	assert.Equal(t, map[string]string{"host": "a", "region": "eu"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"min": 1.0, "count": int64(2)}, out[0].Fields())
+}
+
+func TestRenameInOrder(t *testing.T) {
+	r := &Rename{Replaces: []Replace{
+		{Measurement: "a", Dest: "b"},
+		{Measurement: "b", Dest: "c"},
+	}}
+	require.NoError(t, r.Init())
+
+	out := r.Apply(m2.Copy())
+	assert.Equal(t, "c", out[0].Name())
+}
+
+func TestRenameNoMatch(t *testing.T) {
+	r := &Rename{Replaces: []Replace{{Tag: "hostname", Dest: "host"}}}
+	require.NoError(t, r.Init())
+
+	m := m3.Copy()
+	out := r.Apply(m)
+	assert.True(t, m == out[0])
+}
+
+func TestInvalidReplaces(t *testing.T) {
+	r := &Rename{Replaces: []Replace{{Tag: "a", Field: "b", Dest: "c"}}}
+	assert.EqualError(t, r.Init(), `replace to "c" must set one of measurement, tag or field`)
+
+	r = &Rename{Replaces: []Replace{{Dest: "c"}}}
+	assert.EqualError(t, r.Init(), `replace to "c" must set one of measurement, tag or field`)
+
+	r = &Rename{Replaces: []Replace{{Tag: "a"}}}
+	assert.EqualError(t, r.Init(), `replace of "a" has no dest`)
+}
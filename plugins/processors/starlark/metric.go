@@ -0,0 +1,251 @@
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"go.starlark.net/starlark"
+)
+
+// Metric is the Starlark value of a metric, its tags and fields are plain
+// dicts the script may mutate.
+type Metric struct {
+	name   string
+	tags   *starlark.Dict
+	fields *starlark.Dict
+	time   int64
+	typ    telegraf.ValueType
+	frozen bool
+}
+
+var metricAttrs = []string{"fields", "name", "tags", "time"}
+
+// fromMetric converts a metric to its Starlark value.
+func fromMetric(m telegraf.Metric) (*Metric, error) {
+	// the items of the dicts are iterated in the order of their keys
+	mtags := m.Tags()
+	tags := starlark.NewDict(len(mtags))
+	for _, k := range sortedKeys(mtags) {
+		tags.SetKey(starlark.String(k), starlark.String(mtags[k]))
+	}
+	mfields := m.Fields()
+	fields := starlark.NewDict(len(mfields))
+	for _, k := range sortedFieldKeys(mfields) {
+		value, err := toValue(mfields[k])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %s", k, err)
+		}
+		fields.SetKey(starlark.String(k), value)
+	}
+	return &Metric{
+		name:   m.Name(),
+		tags:   tags,
+		fields: fields,
+		time:   m.Time().UnixNano(),
+		typ:    m.Type(),
+	}, nil
+}
+
+// toMetric converts the Starlark value back to a metric.
+func (m *Metric) toMetric() (telegraf.Metric, error) {
+	tags := make(map[string]string, m.tags.Len())
+	for _, item := range m.tags.Items() {
+		k, ok := item[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("tag key %s is not a string", item[0])
+		}
+		v, ok := item[1].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("tag %q is not a string: %s", string(k), item[1].Type())
+		}
+		tags[string(k)] = string(v)
+	}
+	fields := make(map[string]interface{}, m.fields.Len())
+	for _, item := range m.fields.Items() {
+		k, ok := item[0].(starlark.String)
+		if !ok {
+			return nil, fmt.Errorf("field key %s is not a string", item[0])
+		}
+		v, err := fromValue(item[1])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %s", string(k), err)
+		}
+		fields[string(k)] = v
+	}
+	return metric.New(m.name, tags, fields, time.Unix(0, m.time), m.typ)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedFieldKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func toValue(v interface{}) (starlark.Value, error) {
+	switch v := v.(type) {
+	case int64:
+		return starlark.MakeInt64(v), nil
+	case uint64:
+		return starlark.MakeUint64(v), nil
+	case float64:
+		return starlark.Float(v), nil
+	case string:
+		return starlark.String(v), nil
+	case bool:
+		return starlark.Bool(v), nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", v)
+}
+
+func fromValue(v starlark.Value) (interface{}, error) {
+	switch v := v.(type) {
+	case starlark.Int:
+		if i, ok := v.Int64(); ok {
+			return i, nil
+		}
+		if u, ok := v.Uint64(); ok {
+			return u, nil
+		}
+		return nil, fmt.Errorf("integer %s out of range", v)
+	case starlark.Float:
+		return float64(v), nil
+	case starlark.String:
+		return string(v), nil
+	case starlark.Bool:
+		return bool(v), nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func (m *Metric) String() string {
+	return fmt.Sprintf("Metric(%q, tags=%s, fields=%s, time=%d)", m.name, m.tags, m.fields, m.time)
+}
+
+func (m *Metric) Type() string {
+	return "Metric"
+}
+
+func (m *Metric) Freeze() {
+	m.frozen = true
+	m.tags.Freeze()
+	m.fields.Freeze()
+}
+
+func (m *Metric) Truth() starlark.Bool {
+	return true
+}
+
+func (m *Metric) Hash() (uint32, error) {
+	return 0, errors.New("unhashable type: Metric")
+}
+
+func (m *Metric) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "name":
+		return starlark.String(m.name), nil
+	case "tags":
+		return m.tags, nil
+	case "fields":
+		return m.fields, nil
+	case "time":
+		return starlark.MakeInt64(m.time), nil
+	}
+	return nil, nil
+}
+
+func (m *Metric) AttrNames() []string {
+	return metricAttrs
+}
+
+func (m *Metric) SetField(name string, value starlark.Value) error {
+	if m.frozen {
+		return errors.New("cannot modify frozen Metric")
+	}
+	switch name {
+	case "name":
+		s, ok := value.(starlark.String)
+		if !ok {
+			return fmt.Errorf("name must be a string, not %s", value.Type())
+		}
+		m.name = string(s)
+	case "tags", "fields":
+		d, ok := value.(*starlark.Dict)
+		if !ok {
+			return fmt.Errorf("%s must be a dict, not %s", name, value.Type())
+		}
+		if name == "tags" {
+			m.tags = d
+		} else {
+			m.fields = d
+		}
+	case "time":
+		i, ok := value.(starlark.Int)
+		if !ok {
+			return fmt.Errorf("time must be an int, not %s", value.Type())
+		}
+		t, ok := i.Int64()
+		if !ok {
+			return fmt.Errorf("time %s out of range", i)
+		}
+		m.time = t
+	default:
+		return fmt.Errorf("Metric has no attribute %q", name)
+	}
+	return nil
+}
+
+// builtinMetric implements the Metric(name) builtin, creating a metric without
+// tags and fields at the current time.
+func builtinMetric(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	return &Metric{
+		name:   name,
+		tags:   starlark.NewDict(0),
+		fields: starlark.NewDict(0),
+		time:   time.Now().UnixNano(),
+		typ:    telegraf.Untyped,
+	}, nil
+}
+
+// deepcopy implements the deepcopy(metric) builtin, copying a metric with
+// its tags and fields.
+func deepcopy(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var m *Metric
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "metric", &m); err != nil {
+		return nil, err
+	}
+	return &Metric{
+		name:   m.name,
+		tags:   copyDict(m.tags),
+		fields: copyDict(m.fields),
+		time:   m.time,
+		typ:    m.typ,
+	}, nil
+}
+
+func copyDict(d *starlark.Dict) *starlark.Dict {
+	c := starlark.NewDict(d.Len())
+	for _, item := range d.Items() {
+		c.SetKey(item[0], item[1])
+	}
+	return c
+}
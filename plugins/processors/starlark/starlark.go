@@ -0,0 +1,160 @@
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/processors"
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+)
+
+const pluginName = "processors.starlark"
+
+var sampleConfig = `
+  ## The Starlark source of the processor, it must define a function
+  ## apply(metric) returning the metric, a list of metrics, or None to drop
+  ## the metric.
+  source = '''
+def apply(metric):
+    return metric
+'''
+
+  ## File holding the Starlark source, instead of the inline source.
+  # script = "/etc/telegraf/processor.star"
+`
+
+type Starlark struct {
+	Source string `toml:"source"`
+	Script string `toml:"script"`
+
+	thread *starlark.Thread
+	apply  *starlark.Function
+}
+
+func (s *Starlark) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Starlark) Description() string {
+	return "Process metrics with a Starlark script."
+}
+
+func (s *Starlark) Init() error {
+	if (s.Source == "") == (s.Script == "") {
+		return errors.New("exactly one of source or script must be set")
+	}
+	filename := s.Script
+	var src interface{}
+	if s.Source != "" {
+		filename, src = pluginName, s.Source
+	}
+
+	s.thread = &starlark.Thread{
+		Name: pluginName,
+		Print: func(_ *starlark.Thread, msg string) {
+			log.Printf("I! [%s] %s", pluginName, msg)
+		},
+	}
+	// the state dict is not frozen with the globals of the script, it keeps
+	// the values of the stateful computations between the calls
+	predeclared := starlark.StringDict{
+		"Metric":   starlark.NewBuiltin("Metric", builtinMetric),
+		"deepcopy": starlark.NewBuiltin("deepcopy", deepcopy),
+		"state":    starlark.NewDict(0),
+	}
+	globals, err := starlark.ExecFile(s.thread, filename, src, predeclared)
+	if err != nil {
+		return fmt.Errorf("could not load the script: %s", errorTrace(err))
+	}
+
+	apply, ok := globals["apply"].(*starlark.Function)
+	if !ok {
+		return errors.New("the script must define a function apply(metric)")
+	}
+	if apply.NumParams() != 1 {
+		return fmt.Errorf("apply must take one argument, not %d", apply.NumParams())
+	}
+	s.apply = apply
+	return nil
+}
+
+func (s *Starlark) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		results, err := s.call(m)
+		if err != nil {
+			log.Printf("E! [%s] could not process metric %s: %s", pluginName, m.Name(), err)
+			continue
+		}
+		out = append(out, results...)
+	}
+	return out
+}
+
+// call runs the apply function of the script with the metric, and returns
+// the metrics it returned.
+func (s *Starlark) call(m telegraf.Metric) ([]telegraf.Metric, error) {
+	arg, err := fromMetric(m)
+	if err != nil {
+		return nil, err
+	}
+	rv, err := starlark.Call(s.thread, s.apply, starlark.Tuple{arg}, nil)
+	if err != nil {
+		return nil, errors.New(errorTrace(err))
+	}
+
+	var values []starlark.Value
+	switch rv := rv.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case *Metric:
+		values = append(values, rv)
+	case *starlark.List:
+		for i := 0; i < rv.Len(); i++ {
+			values = append(values, rv.Index(i))
+		}
+	case starlark.Tuple:
+		values = rv
+	default:
+		return nil, fmt.Errorf("apply returned %s, not a Metric, a list or None", rv.Type())
+	}
+
+	results := make([]telegraf.Metric, 0, len(values))
+	for _, v := range values {
+		sm, ok := v.(*Metric)
+		if !ok {
+			return nil, fmt.Errorf("apply returned a list holding %s, not a Metric", v.Type())
+		}
+		result, err := sm.toMetric()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// errorTrace returns the message of the error, with the backtrace of the
+// script for the evaluation errors.
+func errorTrace(err error) string {
+	if evalErr, ok := err.(*starlark.EvalError); ok {
+		return evalErr.Backtrace()
+	}
+	return err.Error()
+}
+
+func init() {
+	// the dialect of the scripts, the loops remain bounded as the while
+	// statements and recursion are not enabled
+	resolve.AllowFloat = true
+	resolve.AllowSet = true
+	resolve.AllowLambda = true
+	resolve.AllowNestedDef = true
+
+	processors.Add("starlark", func() telegraf.Processor {
+		return &Starlark{}
+	})
+}
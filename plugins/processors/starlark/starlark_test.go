@@ -0,0 +1,201 @@
+package starlark
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var failed5, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"failed": int64(5), "total": int64(20)},
+	time.Unix(10, 0),
+)
+var total0, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"total": int64(0)},
+	time.Unix(10, 0),
+)
+var total1, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"total": int64(1)},
+	time.Unix(10, 0),
+)
+var total2, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"total": int64(2)},
+	time.Unix(10, 0),
+)
+var total3, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"total": int64(3)},
+	time.Unix(10, 0),
+)
+var total4, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"total": int64(4)},
+	time.Unix(10, 0),
+)
+var okFailed, _ = metric.New("requests",
+	map[string]string{"app": "shop"},
+	map[string]interface{}{"ok": int64(3), "failed": true},
+	time.Unix(10, 0),
+)
+var total100, _ = metric.New("requests",
+	nil,
+	map[string]interface{}{"total": int64(100)},
+	time.Unix(10, 0),
+)
+var total150, _ = metric.New("requests",
+	nil,
+	map[string]interface{}{"total": int64(150)},
+	time.Unix(20, 0),
+)
+
+func newStarlark(t *testing.T, source string) *Starlark {
+	s := &Starlark{Source: source}
+	require.NoError(t, s.Init())
+	return s
+}
+
+func TestMutate(t *testing.T) {
+	s := newStarlark(t, `
+def apply(metric):
+    metric.name = "http_" + metric.name
+    metric.tags["env"] = "prod"
+    metric.fields["errors"] = metric.fields.pop("failed")
+    metric.fields["ratio"] = metric.fields["errors"] / metric.fields["total"]
+    metric.time += 1000000000
+    return metric
+`)
+
+	out := s.Apply(failed5.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, "http_requests", out[0].Name())
+	assert.Equal(t, map[string]string{"app": "shop", "env": "prod"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{
+		"errors": int64(5),
+		"total":  int64(20),
+		"ratio":  0.25,
+	}, out[0].Fields())
+	assert.Equal(t, time.Unix(11, 0), out[0].Time())
+}
+
+func TestFilter(t *testing.T) {
+	s := newStarlark(t, `
+def apply(metric):
+    if metric.fields["total"] == 0:
+        return None
+    return metric
+`)
+
+	out := s.Apply(
+		total0.Copy(),
+		total3.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, int64(3), out[0].Fields()["total"])
+}
+
+func TestNewMetrics(t *testing.T) {
+	s := newStarlark(t, `
+def apply(metric):
+    split = []
+    for key, value in metric.fields.items():
+        m = deepcopy(metric)
+        m.tags["field"] = key
+        m.fields = {"value": value}
+        split.append(m)
+    summary = Metric("summary")
+    summary.fields["count"] = len(split)
+    summary.time = metric.time
+    return split + [summary]
+`)
+
+	out := s.Apply(okFailed.Copy())
+	require.Len(t, out, 3)
+	assert.Equal(t, map[string]string{"app": "shop", "field": "failed"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"value": true}, out[0].Fields())
+	assert.Equal(t, map[string]string{"app": "shop", "field": "ok"}, out[1].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(3)}, out[1].Fields())
+	assert.Equal(t, "summary", out[2].Name())
+	assert.Equal(t, map[string]interface{}{"count": int64(2)}, out[2].Fields())
+	assert.Equal(t, time.Unix(10, 0), out[2].Time())
+}
+
+func TestState(t *testing.T) {
+	s := newStarlark(t, `
+def apply(metric):
+    last = state.get(metric.name)
+    state[metric.name] = (metric.time, metric.fields["total"])
+    if last == None:
+        return None
+    elapsed = (metric.time - last[0]) / 1e9
+    metric.fields["rate"] = (metric.fields["total"] - last[1]) / elapsed
+    return metric
+`)
+
+	assert.Len(t, s.Apply(total100.Copy()), 0)
+	out := s.Apply(total150.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, 5.0, out[0].Fields()["rate"])
+}
+
+func TestErrors(t *testing.T) {
+	s := newStarlark(t, `
+def apply(metric):
+    if metric.fields["total"] == 1:
+        fail("bad metric")
+    if metric.fields["total"] == 2:
+        metric.fields["list"] = []
+    if metric.fields["total"] == 3:
+        return "metric"
+    return metric
+`)
+
+	out := s.Apply(
+		total1.Copy(),
+		total2.Copy(),
+		total3.Copy(),
+		total4.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, int64(4), out[0].Fields()["total"])
+}
+
+func TestScript(t *testing.T) {
+	f, err := ioutil.TempFile("", "processor")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("def apply(metric):\n    metric.tags.clear()\n    return metric\n")
+	require.NoError(t, err)
+	f.Close()
+
+	s := &Starlark{Script: f.Name()}
+	require.NoError(t, s.Init())
+	out := s.Apply(total1.Copy())
+	require.Len(t, out, 1)
+	assert.Empty(t, out[0].Tags())
+}
+
+func TestInvalidScripts(t *testing.T) {
+	s := &Starlark{}
+	assert.EqualError(t, s.Init(), "exactly one of source or script must be set")
+
+	s = &Starlark{Source: "def apply(metric):\n    return metric\n", Script: "processor.star"}
+	assert.EqualError(t, s.Init(), "exactly one of source or script must be set")
+
+	s = &Starlark{Source: "def process(metric):\n    return metric\n"}
+	assert.EqualError(t, s.Init(), "the script must define a function apply(metric)")
+
+	s = &Starlark{Source: "def apply(metric, other):\n    return metric\n"}
+	assert.EqualError(t, s.Init(), "apply must take one argument, not 2")
+
+	s = &Starlark{Source: "def apply(metric)\n"}
+	err := s.Init()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not load the script: ")
+}
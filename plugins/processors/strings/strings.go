@@ -0,0 +1,265 @@
+package strings
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Each transform applies to the measurement names, the values of the
+  ## tags, or the values of the string fields, matching its glob pattern of
+  ## measurement, tag or field. The tags and fields may be written to dest.
+
+  ## Convert a tag value to lowercase
+  # [[processors.strings.lowercase]]
+  #   tag = "method"
+
+  ## Convert a field value to uppercase, into a new field
+  # [[processors.strings.uppercase]]
+  #   field = "uri_stem"
+  #   dest = "uri_stem_upper"
+
+  ## Trim leading and trailing whitespace, or the characters of cutset
+  # [[processors.strings.trim]]
+  #   field = "message"
+
+  ## Trim leading characters
+  # [[processors.strings.trim_left]]
+  #   field = "message"
+  #   cutset = "\t"
+
+  ## Trim trailing characters
+  # [[processors.strings.trim_right]]
+  #   field = "message"
+  #   cutset = "\r\n"
+
+  ## Trim a prefix
+  # [[processors.strings.trim_prefix]]
+  #   measurement = "*"
+  #   prefix = "com.example."
+
+  ## Trim a suffix
+  # [[processors.strings.trim_suffix]]
+  #   field = "*"
+  #   suffix = "_count"
+
+  ## Replace all the occurrences of old with new
+  # [[processors.strings.replace]]
+  #   measurement = "*"
+  #   old = "."
+  #   new = "_"
+
+  ## Keep the first characters, up to width
+  # [[processors.strings.left]]
+  #   tag = "message"
+  #   width = 32
+
+  ## Keep the last characters, up to width
+  # [[processors.strings.right]]
+  #   tag = "class"
+  #   width = 32
+`
+
+type converter struct {
+	Measurement string `toml:"measurement"`
+	Tag         string `toml:"tag"`
+	Field       string `toml:"field"`
+	Dest        string `toml:"dest"`
+	Cutset      string `toml:"cutset"`
+	Prefix      string `toml:"prefix"`
+	Suffix      string `toml:"suffix"`
+	Old         string `toml:"old"`
+	New         string `toml:"new"`
+	Width       int    `toml:"width"`
+
+	filter filter.Filter
+	fn     func(string) string
+}
+
+type Strings struct {
+	Lowercase  []*converter `toml:"lowercase"`
+	Uppercase  []*converter `toml:"uppercase"`
+	Trim       []*converter `toml:"trim"`
+	TrimLeft   []*converter `toml:"trim_left"`
+	TrimRight  []*converter `toml:"trim_right"`
+	TrimPrefix []*converter `toml:"trim_prefix"`
+	TrimSuffix []*converter `toml:"trim_suffix"`
+	Replace    []*converter `toml:"replace"`
+	Left       []*converter `toml:"left"`
+	Right      []*converter `toml:"right"`
+
+	converters []*converter
+}
+
+func (s *Strings) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *Strings) Description() string {
+	return "Perform string processing on tags, fields, and measurements."
+}
+
+func (s *Strings) Init() error {
+	s.converters = nil
+	add := func(converters []*converter, fn func(c *converter) func(string) string) {
+		for _, c := range converters {
+			c.fn = fn(c)
+			s.converters = append(s.converters, c)
+		}
+	}
+	add(s.Lowercase, func(c *converter) func(string) string {
+		return strings.ToLower
+	})
+	add(s.Uppercase, func(c *converter) func(string) string {
+		return strings.ToUpper
+	})
+	add(s.Trim, func(c *converter) func(string) string {
+		if c.Cutset == "" {
+			return strings.TrimSpace
+		}
+		return func(v string) string { return strings.Trim(v, c.Cutset) }
+	})
+	add(s.TrimLeft, func(c *converter) func(string) string {
+		if c.Cutset == "" {
+			return func(v string) string { return strings.TrimLeftFunc(v, unicode.IsSpace) }
+		}
+		return func(v string) string { return strings.TrimLeft(v, c.Cutset) }
+	})
+	add(s.TrimRight, func(c *converter) func(string) string {
+		if c.Cutset == "" {
+			return func(v string) string { return strings.TrimRightFunc(v, unicode.IsSpace) }
+		}
+		return func(v string) string { return strings.TrimRight(v, c.Cutset) }
+	})
+	add(s.TrimPrefix, func(c *converter) func(string) string {
+		return func(v string) string { return strings.TrimPrefix(v, c.Prefix) }
+	})
+	add(s.TrimSuffix, func(c *converter) func(string) string {
+		return func(v string) string { return strings.TrimSuffix(v, c.Suffix) }
+	})
+	add(s.Replace, func(c *converter) func(string) string {
+		return func(v string) string { return strings.Replace(v, c.Old, c.New, -1) }
+	})
+	add(s.Left, func(c *converter) func(string) string {
+		return func(v string) string {
+			if r := []rune(v); len(r) > c.Width {
+				return string(r[:c.Width])
+			}
+			return v
+		}
+	})
+	add(s.Right, func(c *converter) func(string) string {
+		return func(v string) string {
+			if r := []rune(v); len(r) > c.Width {
+				return string(r[len(r)-c.Width:])
+			}
+			return v
+		}
+	})
+
+	for _, c := range s.converters {
+		var pattern string
+		set := 0
+		for _, p := range []string{c.Measurement, c.Tag, c.Field} {
+			if p != "" {
+				pattern = p
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("a transform must set one of measurement, tag or field")
+		}
+		if c.Measurement != "" && c.Dest != "" {
+			return fmt.Errorf("dest cannot be set for the measurement %q", c.Measurement)
+		}
+		if c.Width < 0 {
+			return fmt.Errorf("invalid width %d", c.Width)
+		}
+
+		var err error
+		if c.filter, err = filter.Compile([]string{pattern}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Strings) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		name := m.Name()
+		tags := m.Tags()
+		fields := m.Fields()
+		changed := false
+
+		for _, c := range s.converters {
+			switch {
+			case c.Measurement != "":
+				if c.filter.Match(name) {
+					if v := c.fn(name); v != name {
+						name = v
+						changed = true
+					}
+				}
+			case c.Tag != "":
+				// the results are added after matching the keys, so that a
+				// dest tag is not transformed again
+				results := make(map[string]string)
+				for key, value := range tags {
+					if c.filter.Match(key) {
+						results[c.dest(key)] = c.fn(value)
+					}
+				}
+				for key, value := range results {
+					if old, ok := tags[key]; !ok || old != value {
+						tags[key] = value
+						changed = true
+					}
+				}
+			case c.Field != "":
+				results := make(map[string]interface{})
+				for key, value := range fields {
+					if v, ok := value.(string); ok && c.filter.Match(key) {
+						results[c.dest(key)] = c.fn(v)
+					}
+				}
+				for key, value := range results {
+					if old, ok := fields[key]; !ok || old != value {
+						fields[key] = value
+						changed = true
+					}
+				}
+			}
+		}
+
+		if !changed {
+			continue
+		}
+		processed, err := metric.New(name, tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.strings] could not process metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = processed
+	}
+	return in
+}
+
+func (c *converter) dest(key string) string {
+	if c.Dest != "" {
+		return c.Dest
+	}
+	return key
+}
+
+func init() {
+	processors.Add("strings", func() telegraf.Processor {
+		return &Strings{}
+	})
+}
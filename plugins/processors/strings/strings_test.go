@@ -0,0 +1,112 @@
+package strings
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("com.example.Requests",
+	map[string]string{"method": "GET", "class": "com.example.shop.OrderService"},
+	map[string]interface{}{"message": "  Slow Request\r\n", "count": int64(3)},
+	time.Unix(0, 0),
+)
+
+func TestTransforms(t *testing.T) {
+	tests := []struct {
+		name   string
+		plugin *Strings
+		check  func(t *testing.T, m telegraf.Metric)
+	}{
+		{
+			"lowercase tag",
+			&Strings{Lowercase: []*converter{{Tag: "method"}}},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "get", m.Tags()["method"])
+			},
+		},
+		{
+			"uppercase field into dest",
+			&Strings{Uppercase: []*converter{{Field: "message", Dest: "upper"}}},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "  SLOW REQUEST\r\n", m.Fields()["upper"])
+				assert.Equal(t, "  Slow Request\r\n", m.Fields()["message"])
+			},
+		},
+		{
+			"trim",
+			&Strings{Trim: []*converter{{Field: "message"}}},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "Slow Request", m.Fields()["message"])
+			},
+		},
+		{
+			"trim left and right",
+			&Strings{
+				TrimLeft:  []*converter{{Field: "message", Cutset: " S"}},
+				TrimRight: []*converter{{Field: "message", Cutset: "\r\n"}},
+			},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "low Request", m.Fields()["message"])
+			},
+		},
+		{
+			"trim prefix of measurement",
+			&Strings{TrimPrefix: []*converter{{Measurement: "*", Prefix: "com.example."}}},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "Requests", m.Name())
+			},
+		},
+		{
+			"replace",
+			&Strings{Replace: []*converter{{Measurement: "*", Old: ".", New: "_"}}},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "com_example_Requests", m.Name())
+			},
+		},
+		{
+			"left and right",
+			&Strings{
+				Left:  []*converter{{Tag: "method", Width: 2}},
+				Right: []*converter{{Tag: "class", Width: 12}},
+			},
+			func(t *testing.T, m telegraf.Metric) {
+				assert.Equal(t, "GE", m.Tags()["method"])
+				assert.Equal(t, "OrderService", m.Tags()["class"])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.plugin.Init())
+			out := tt.plugin.Apply(m1.Copy())
+			require.Len(t, out, 1)
+			assert.Equal(t, int64(3), out[0].Fields()["count"])
+			tt.check(t, out[0])
+		})
+	}
+}
+
+func TestNoChange(t *testing.T) {
+	s := &Strings{Lowercase: []*converter{{Tag: "host"}}}
+	require.NoError(t, s.Init())
+
+	m := m1.Copy()
+	out := s.Apply(m)
+	assert.True(t, m == out[0])
+}
+
+func TestInvalidTransforms(t *testing.T) {
+	s := &Strings{Lowercase: []*converter{{}}}
+	assert.EqualError(t, s.Init(), "a transform must set one of measurement, tag or field")
+
+	s = &Strings{Lowercase: []*converter{{Tag: "a", Field: "b"}}}
+	assert.EqualError(t, s.Init(), "a transform must set one of measurement, tag or field")
+
+	s = &Strings{Lowercase: []*converter{{Measurement: "*", Dest: "b"}}}
+	assert.EqualError(t, s.Init(), `dest cannot be set for the measurement "*"`)
+}
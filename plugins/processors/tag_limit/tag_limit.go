@@ -0,0 +1,86 @@
+package tag_limit
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Maximum number of tags of the metrics
+  limit = 10
+
+  ## Tags to keep when over the limit, the other tags are dropped in the
+  ## order of their keys until the metric is within the limit.
+  keep = ["host", "app"]
+`
+
+type TagLimit struct {
+	Limit int      `toml:"limit"`
+	Keep  []string `toml:"keep"`
+
+	keep map[string]bool
+}
+
+func (d *TagLimit) SampleConfig() string {
+	return sampleConfig
+}
+
+func (d *TagLimit) Description() string {
+	return "Drop the tags of the metrics over a maximum number of tags."
+}
+
+func (d *TagLimit) Init() error {
+	if d.Limit < 1 {
+		return fmt.Errorf("limit must be at least 1")
+	}
+	if len(d.Keep) > d.Limit {
+		return fmt.Errorf("%d tags to keep over the limit of %d", len(d.Keep), d.Limit)
+	}
+	d.keep = make(map[string]bool, len(d.Keep))
+	for _, key := range d.Keep {
+		d.keep[key] = true
+	}
+	return nil
+}
+
+func (d *TagLimit) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		tags := m.Tags()
+		if len(tags) <= d.Limit {
+			continue
+		}
+
+		var others []string
+		kept := 0
+		for key := range tags {
+			if d.keep[key] {
+				kept++
+			} else {
+				others = append(others, key)
+			}
+		}
+		sort.Strings(others)
+		for _, key := range others[d.Limit-kept:] {
+			delete(tags, key)
+		}
+
+		limited, err := metric.New(m.Name(), tags, m.Fields(), m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.tag_limit] could not limit the tags of metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = limited
+	}
+	return in
+}
+
+func init() {
+	processors.Add("tag_limit", func() telegraf.Processor {
+		return &TagLimit{}
+	})
+}
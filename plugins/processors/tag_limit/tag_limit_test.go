@@ -0,0 +1,59 @@
+package tag_limit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("pods",
+	map[string]string{"a": "1", "b": "2", "c": "3"},
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("pods",
+	map[string]string{
+		"pod":       "shop-1",
+		"app":       "shop",
+		"container": "main",
+		"region":    "eu",
+		"node":      "n1",
+	},
+	map[string]interface{}{"value": 1},
+	time.Unix(0, 0),
+)
+
+func TestUnderLimit(t *testing.T) {
+	d := &TagLimit{Limit: 3, Keep: []string{"app"}}
+	require.NoError(t, d.Init())
+
+	m := m1.Copy()
+	out := d.Apply(m)
+	assert.True(t, m == out[0])
+}
+
+func TestOverLimit(t *testing.T) {
+	d := &TagLimit{Limit: 3, Keep: []string{"app", "region"}}
+	require.NoError(t, d.Init())
+
+	out := d.Apply(m2.Copy())
+	require.Len(t, out, 1)
+	// the kept tags, then the first other tag in the order of the keys
+	assert.Equal(t, map[string]string{
+		"app":       "shop",
+		"region":    "eu",
+		"container": "main",
+	}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(1)}, out[0].Fields())
+}
+
+func TestInvalidLimit(t *testing.T) {
+	d := &TagLimit{Limit: 1, Keep: []string{"a", "b"}}
+	assert.EqualError(t, d.Init(), "2 tags to keep over the limit of 1")
+
+	d = &TagLimit{}
+	assert.EqualError(t, d.Init(), "limit must be at least 1")
+}
@@ -0,0 +1,233 @@
+package units
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/filter"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Conversions of the fields matching the glob patterns of fields, from a
+  ## unit to another of the same kind. The units are:
+  ##   data: B, KB, MB, GB, TB, KiB, MiB, GiB, TiB
+  ##   time: ns, us, ms, s, min, h, d, or nanoseconds to days
+  ##   ratio: ratio, percent
+  [[processors.units.conversion]]
+    fields = ["*_bytes"]
+    from = "B"
+    to = "MB"
+
+  ## The unit may be read from a string field or a tag of the metrics, ie,
+  ## the duration_units of the dropwizard timers, which is updated to the
+  ## new unit. Metrics without a known unit are left unchanged.
+  # [[processors.units.conversion]]
+  #   fields = ["min", "max", "mean", "stddev", "p*"]
+  #   from_field = "duration_units"
+  #   to = "ms"
+  #
+  #   ## Replace the unit suffix of the field names, ie, _ns of max_ns, with
+  #   ## the suffix of the new unit.
+  #   # rename = false
+`
+
+// unit is a unit of measurement, its scale is its size in the base unit of
+// its kind.
+type unit struct {
+	kind  string
+	short string
+	scale float64
+}
+
+var units = map[string]unit{
+	"B":   {"data", "B", 1},
+	"KB":  {"data", "KB", 1e3},
+	"MB":  {"data", "MB", 1e6},
+	"GB":  {"data", "GB", 1e9},
+	"TB":  {"data", "TB", 1e12},
+	"KiB": {"data", "KiB", 1 << 10},
+	"MiB": {"data", "MiB", 1 << 20},
+	"GiB": {"data", "GiB", 1 << 30},
+	"TiB": {"data", "TiB", 1 << 40},
+
+	"ns":  {"time", "ns", 1},
+	"us":  {"time", "us", 1e3},
+	"ms":  {"time", "ms", 1e6},
+	"s":   {"time", "s", 1e9},
+	"min": {"time", "min", 60e9},
+	"h":   {"time", "h", 3600e9},
+	"d":   {"time", "d", 86400e9},
+
+	"nanoseconds":  {"time", "ns", 1},
+	"microseconds": {"time", "us", 1e3},
+	"milliseconds": {"time", "ms", 1e6},
+	"seconds":      {"time", "s", 1e9},
+	"minutes":      {"time", "min", 60e9},
+	"hours":        {"time", "h", 3600e9},
+	"days":         {"time", "d", 86400e9},
+
+	"ratio":   {"ratio", "ratio", 1},
+	"percent": {"ratio", "percent", 0.01},
+}
+
+// longNames are the names of the time units written by the dropwizard
+// metrics library, a unit read from a field keeps this form.
+var longNames = map[string]string{
+	"ns":  "nanoseconds",
+	"us":  "microseconds",
+	"ms":  "milliseconds",
+	"s":   "seconds",
+	"min": "minutes",
+	"h":   "hours",
+	"d":   "days",
+}
+
+type Conversion struct {
+	Fields    []string `toml:"fields"`
+	From      string   `toml:"from"`
+	FromField string   `toml:"from_field"`
+	To        string   `toml:"to"`
+	Rename    bool     `toml:"rename"`
+
+	fields filter.Filter
+	from   unit
+	to     unit
+}
+
+type Units struct {
+	Conversions []*Conversion `toml:"conversion"`
+}
+
+func (u *Units) SampleConfig() string {
+	return sampleConfig
+}
+
+func (u *Units) Description() string {
+	return "Convert fields from a unit to another."
+}
+
+func (u *Units) Init() error {
+	for _, c := range u.Conversions {
+		if len(c.Fields) == 0 {
+			return fmt.Errorf("conversion to %q has no fields", c.To)
+		}
+		var err error
+		c.fields, err = filter.Compile(c.Fields)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		if c.to, ok = units[c.To]; !ok {
+			return fmt.Errorf("unknown unit %q", c.To)
+		}
+		switch {
+		case c.From != "" && c.FromField != "":
+			return fmt.Errorf("conversion to %q sets both from and from_field", c.To)
+		case c.From != "":
+			if c.from, ok = units[c.From]; !ok {
+				return fmt.Errorf("unknown unit %q", c.From)
+			}
+			if c.from.kind != c.to.kind {
+				return fmt.Errorf("cannot convert %s to %s", c.From, c.To)
+			}
+		case c.FromField == "":
+			return fmt.Errorf("conversion to %q sets neither from nor from_field", c.To)
+		}
+	}
+	return nil
+}
+
+func (u *Units) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	for i, m := range in {
+		tags := m.Tags()
+		fields := m.Fields()
+		changed := false
+		for _, c := range u.Conversions {
+			if c.convert(tags, fields) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+
+		converted, err := metric.New(m.Name(), tags, fields, m.Time(), m.Type())
+		if err != nil {
+			log.Printf("E! [processors.units] could not convert metric %s: %s", m.Name(), err)
+			continue
+		}
+		in[i] = converted
+	}
+	return in
+}
+
+// convert converts the matching fields in place, and returns true if any
+// field was converted.
+func (c *Conversion) convert(tags map[string]string, fields map[string]interface{}) bool {
+	from := c.from
+	if c.FromField != "" {
+		name, isTag := tags[c.FromField]
+		if !isTag {
+			name, _ = fields[c.FromField].(string)
+		}
+		var ok bool
+		if from, ok = units[name]; !ok || from.kind != c.to.kind {
+			return false
+		}
+
+		to := c.to.short
+		if name == longNames[from.short] {
+			to = longNames[to]
+		}
+		if isTag {
+			tags[c.FromField] = to
+		} else {
+			fields[c.FromField] = to
+		}
+	}
+
+	// the renamed fields must not be converted again
+	var names []string
+	for name := range fields {
+		if c.fields.Match(name) {
+			names = append(names, name)
+		}
+	}
+
+	changed := false
+	fromSuffix := "_" + strings.ToLower(from.short)
+	toSuffix := "_" + strings.ToLower(c.to.short)
+	for _, name := range names {
+		var v float64
+		switch value := fields[name].(type) {
+		case int64:
+			v = float64(value)
+		case uint64:
+			v = float64(value)
+		case float64:
+			v = value
+		default:
+			continue
+		}
+
+		v = v * from.scale / c.to.scale
+		if c.Rename && strings.HasSuffix(name, fromSuffix) {
+			delete(fields, name)
+			name = strings.TrimSuffix(name, fromSuffix) + toSuffix
+		}
+		fields[name] = v
+		changed = true
+	}
+	return changed || c.FromField != "" && from != c.to
+}
+
+func init() {
+	processors.Add("units", func() telegraf.Processor {
+		return &Units{}
+	})
+}
@@ -0,0 +1,99 @@
+package units
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var m1, _ = metric.New("m1",
+	nil,
+	map[string]interface{}{
+		"heap_bytes": int64(2500000),
+		"usage":      0.25,
+		"count":      int64(3),
+	},
+	time.Unix(0, 0),
+)
+var m2, _ = metric.New("m1",
+	nil,
+	map[string]interface{}{
+		"max_ns":         int64(2500000),
+		"p99_ns":         int64(1000000),
+		"duration_units": "nanoseconds",
+	},
+	time.Unix(0, 0),
+)
+var m3, _ = metric.New("m1",
+	map[string]string{"duration_units": "s"},
+	map[string]interface{}{"max": 1.5},
+	time.Unix(0, 0),
+)
+var m4, _ = metric.New("m1",
+	nil,
+	map[string]interface{}{"max": 1.5},
+	time.Unix(0, 0),
+)
+
+func TestConvertFixedUnit(t *testing.T) {
+	u := &Units{Conversions: []*Conversion{
+		{Fields: []string{"*_bytes"}, From: "B", To: "MB"},
+		{Fields: []string{"usage"}, From: "ratio", To: "percent"},
+	}}
+	require.NoError(t, u.Init())
+
+	out := u.Apply(m1.Copy())
+	require.Len(t, out, 1)
+	assert.Equal(t, map[string]interface{}{
+		"heap_bytes": 2.5,
+		"usage":      25.0,
+		"count":      int64(3),
+	}, out[0].Fields())
+}
+
+func TestConvertFromField(t *testing.T) {
+	u := &Units{Conversions: []*Conversion{
+		{Fields: []string{"max*", "p*"}, FromField: "duration_units", To: "ms", Rename: true},
+	}}
+	require.NoError(t, u.Init())
+
+	out := u.Apply(m2.Copy(), m3.Copy(), m4.Copy())
+	require.Len(t, out, 3)
+	assert.Equal(t, map[string]interface{}{
+		"max_ms":         2.5,
+		"p99_ms":         1.0,
+		"duration_units": "milliseconds",
+	}, out[0].Fields())
+	assert.Equal(t, map[string]interface{}{"max": 1500.0}, out[1].Fields())
+	assert.Equal(t, map[string]string{"duration_units": "ms"}, out[1].Tags())
+	// without a unit the metric is left unchanged
+	assert.Equal(t, map[string]interface{}{"max": 1.5}, out[2].Fields())
+}
+
+func TestInvalidConversions(t *testing.T) {
+	tests := []struct {
+		name       string
+		conversion Conversion
+		err        string
+	}{
+		{"no fields", Conversion{From: "B", To: "MB"},
+			`conversion to "MB" has no fields`},
+		{"unknown unit", Conversion{Fields: []string{"a"}, From: "B", To: "parsec"},
+			`unknown unit "parsec"`},
+		{"different kinds", Conversion{Fields: []string{"a"}, From: "B", To: "ms"},
+			"cannot convert B to ms"},
+		{"no from", Conversion{Fields: []string{"a"}, To: "ms"},
+			`conversion to "ms" sets neither from nor from_field`},
+		{"both from", Conversion{Fields: []string{"a"}, From: "s", FromField: "unit", To: "ms"},
+			`conversion to "ms" sets both from and from_field`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &Units{Conversions: []*Conversion{&tt.conversion}}
+			assert.EqualError(t, u.Init(), tt.err)
+		})
+	}
+}
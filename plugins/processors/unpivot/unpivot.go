@@ -0,0 +1,61 @@
+package unpivot
+
+import (
+	"log"
+	"sort"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/metric"
+	"github.com/influxdata/telegraf/plugins/processors"
+)
+
+var sampleConfig = `
+  ## Tag to use for the name.
+  tag_key = "name"
+  ## Field to use for the name of the value.
+  value_key = "value"
+`
+
+type Unpivot struct {
+	TagKey   string `toml:"tag_key"`
+	ValueKey string `toml:"value_key"`
+}
+
+func (p *Unpivot) SampleConfig() string {
+	return sampleConfig
+}
+
+func (p *Unpivot) Description() string {
+	return "Rotate multi field metric into several single field metrics."
+}
+
+func (p *Unpivot) Apply(in ...telegraf.Metric) []telegraf.Metric {
+	out := make([]telegraf.Metric, 0, len(in))
+	for _, m := range in {
+		fields := m.Fields()
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			tags := m.Tags()
+			tags[p.TagKey] = key
+			single, err := metric.New(m.Name(), tags,
+				map[string]interface{}{p.ValueKey: fields[key]}, m.Time(), m.Type())
+			if err != nil {
+				log.Printf("E! [processors.unpivot] could not unpivot metric %s: %s", m.Name(), err)
+				continue
+			}
+			out = append(out, single)
+		}
+	}
+	return out
+}
+
+func init() {
+	processors.Add("unpivot", func() telegraf.Processor {
+		return &Unpivot{}
+	})
+}
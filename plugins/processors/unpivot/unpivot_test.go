@@ -0,0 +1,29 @@
+package unpivot
+
+import (
+	"testing"
+	"time"
+
+	"github.com/influxdata/telegraf/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnpivot(t *testing.T) {
+	p := &Unpivot{TagKey: "name", ValueKey: "value"}
+
+	m, err := metric.New("jvm_memory",
+		map[string]string{"area": "heap"},
+		map[string]interface{}{"used": int64(10), "max": int64(20)},
+		time.Unix(0, 0))
+	require.NoError(t, err)
+
+	out := p.Apply(m)
+	require.Len(t, out, 2)
+	assert.Equal(t, "jvm_memory", out[0].Name())
+	assert.Equal(t, map[string]string{"area": "heap", "name": "max"}, out[0].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(20)}, out[0].Fields())
+	assert.Equal(t, map[string]string{"area": "heap", "name": "used"}, out[1].Tags())
+	assert.Equal(t, map[string]interface{}{"value": int64(10)}, out[1].Fields())
+	assert.Equal(t, time.Unix(0, 0), out[1].Time())
+}
@@ -0,0 +1,136 @@
+package aws_secrets_manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	"github.com/influxdata/telegraf"
+	internalaws "github.com/influxdata/telegraf/internal/config/aws"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+type SecretsManager struct {
+	Region    string `toml:"region"`
+	AccessKey string `toml:"access_key"`
+	SecretKey string `toml:"secret_key"`
+	RoleARN   string `toml:"role_arn"`
+	Profile   string `toml:"profile"`
+	Filename  string `toml:"shared_credential_file"`
+	Token     string `toml:"token"`
+
+	svc *secretsmanager.SecretsManager
+	// secret strings by secret id, each secret is read once
+	secrets map[string]string
+}
+
+var sampleConfig = `
+  ## The id referencing the store in the options, ie, "@{aws:db/password}".
+  ## The key is the name or ARN of the secret, a key of a JSON secret is
+  ## selected after a '#', ie, "@{aws:db#password}".
+  id = "aws"
+
+  ## Amazon REGION
+  region = "us-east-1"
+
+  ## Amazon Credentials
+  ## Credentials are loaded in the following order
+  ## 1) Assumed credentials via STS if role_arn is specified
+  ## 2) explicit credentials from 'access_key' and 'secret_key'
+  ## 3) shared profile from 'profile'
+  ## 4) environment variables
+  ## 5) shared credentials file
+  ## 6) EC2 Instance Profile
+  #access_key = ""
+  #secret_key = ""
+  #token = ""
+  #role_arn = ""
+  #profile = ""
+  #shared_credential_file = ""
+`
+
+func (s *SecretsManager) SampleConfig() string {
+	return sampleConfig
+}
+
+func (s *SecretsManager) Description() string {
+	return "Read the secrets from AWS Secrets Manager."
+}
+
+func (s *SecretsManager) Init() error {
+	credentialConfig := &internalaws.CredentialConfig{
+		Region:    s.Region,
+		AccessKey: s.AccessKey,
+		SecretKey: s.SecretKey,
+		RoleARN:   s.RoleARN,
+		Profile:   s.Profile,
+		Filename:  s.Filename,
+		Token:     s.Token,
+	}
+	s.svc = secretsmanager.New(credentialConfig.Credentials())
+	s.secrets = make(map[string]string)
+	return nil
+}
+
+func (s *SecretsManager) Get(key string) (string, error) {
+	id, field := splitKey(key)
+	secret, ok := s.secrets[id]
+	if !ok {
+		out, err := s.svc.GetSecretValue(&secretsmanager.GetSecretValueInput{
+			SecretId: aws.String(id),
+		})
+		if err != nil {
+			return "", err
+		}
+		if out.SecretString == nil {
+			return "", fmt.Errorf("secret %s is not a string", id)
+		}
+		secret = *out.SecretString
+		s.secrets[id] = secret
+	}
+
+	if field == "" {
+		return secret, nil
+	}
+	return jsonField(secret, field)
+}
+
+// splitKey splits a key into the id of the secret, and the key of a JSON
+// secret after a '#'.
+func splitKey(key string) (string, string) {
+	i := strings.LastIndex(key, "#")
+	if i < 0 {
+		return key, ""
+	}
+	return key[:i], key[i+1:]
+}
+
+// jsonField returns a field of a secret holding a JSON object, as the
+// secrets of the key/value pairs of the console.
+func jsonField(secret, field string) (string, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %s", err)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("no key %q in the secret", field)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64, bool:
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("key %q of the secret is not a string", field)
+	}
+}
+
+func init() {
+	secretstores.Add("aws_secrets_manager", func() telegraf.SecretStore {
+		return &SecretsManager{}
+	})
+}
@@ -0,0 +1,36 @@
+package aws_secrets_manager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitKey(t *testing.T) {
+	id, field := splitKey("prod/db")
+	assert.Equal(t, "prod/db", id)
+	assert.Equal(t, "", field)
+
+	id, field = splitKey("prod/db#password")
+	assert.Equal(t, "prod/db", id)
+	assert.Equal(t, "password", field)
+}
+
+func TestJSONField(t *testing.T) {
+	secret := `{"username": "telegraf", "password": "secret", "port": 5432}`
+
+	value, err := jsonField(secret, "password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = jsonField(secret, "port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", value)
+
+	_, err = jsonField(secret, "token")
+	assert.Error(t, err)
+
+	_, err = jsonField("secret", "password")
+	assert.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+type Env struct {
+	Prefix string `toml:"prefix"`
+}
+
+var sampleConfig = `
+  ## The id referencing the store in the options, ie, "@{env:PASSWORD}".
+  id = "env"
+
+  ## The prefix of the environment variables, ie, with "TELEGRAF_" the key
+  ## PASSWORD is read from TELEGRAF_PASSWORD.
+  # prefix = ""
+`
+
+func (e *Env) SampleConfig() string {
+	return sampleConfig
+}
+
+func (e *Env) Description() string {
+	return "Read the secrets from environment variables."
+}
+
+func (e *Env) Get(key string) (string, error) {
+	name := e.Prefix + key
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+func init() {
+	secretstores.Add("env", func() telegraf.SecretStore {
+		return &Env{}
+	})
+}
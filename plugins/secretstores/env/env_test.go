@@ -0,0 +1,22 @@
+package env
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	os.Setenv("TELEGRAF_TEST_PASSWORD", "secret")
+	defer os.Unsetenv("TELEGRAF_TEST_PASSWORD")
+
+	e := &Env{Prefix: "TELEGRAF_TEST_"}
+	secret, err := e.Get("PASSWORD")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	_, err = e.Get("TOKEN")
+	assert.Error(t, err)
+}
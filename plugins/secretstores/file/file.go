@@ -0,0 +1,57 @@
+package file
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+type File struct {
+	Directory string `toml:"directory"`
+}
+
+var sampleConfig = `
+  ## The id referencing the store in the options, ie, "@{file:password}".
+  id = "file"
+
+  ## The directory of the secret files, each key is the name of a file of
+  ## the directory, as the docker and kubernetes secrets.
+  directory = "/run/secrets"
+`
+
+func (f *File) SampleConfig() string {
+	return sampleConfig
+}
+
+func (f *File) Description() string {
+	return "Read the secrets from the files of a directory."
+}
+
+func (f *File) Init() error {
+	if f.Directory == "" {
+		return fmt.Errorf("no directory of the secret files")
+	}
+	return nil
+}
+
+func (f *File) Get(key string) (string, error) {
+	// keys are file names, they can not refer to files out of the directory
+	if key == "." || key == ".." || strings.ContainsAny(key, `/\`) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(f.Directory, key))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func init() {
+	secretstores.Add("file", func() telegraf.SecretStore {
+		return &File{}
+	})
+}
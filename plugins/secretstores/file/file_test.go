@@ -0,0 +1,35 @@
+package file
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "password"), []byte("secret\n"), 0600))
+
+	f := &File{Directory: dir}
+	require.NoError(t, f.Init())
+
+	secret, err := f.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	_, err = f.Get("token")
+	assert.Error(t, err)
+	_, err = f.Get("../password")
+	assert.Error(t, err)
+}
+
+func TestInit(t *testing.T) {
+	f := &File{}
+	assert.Error(t, f.Init())
+}
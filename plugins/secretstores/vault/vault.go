@@ -0,0 +1,183 @@
+package vault
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/influxdata/telegraf"
+	"github.com/influxdata/telegraf/internal"
+	"github.com/influxdata/telegraf/plugins/secretstores"
+)
+
+type Vault struct {
+	Address   string            `toml:"address"`
+	Token     string            `toml:"token"`
+	TokenFile string            `toml:"token_file"`
+	Path      string            `toml:"path"`
+	Timeout   internal.Duration `toml:"timeout"`
+
+	// Path to CA file
+	SSLCA string `toml:"ssl_ca"`
+	// Path to host cert file
+	SSLCert string `toml:"ssl_cert"`
+	// Path to cert key file
+	SSLKey string `toml:"ssl_key"`
+	// Use SSL but skip chain & host verification
+	InsecureSkipVerify bool `toml:"insecure_skip_verify"`
+
+	client *http.Client
+	// data of the secret, read once by the first Get
+	data map[string]interface{}
+}
+
+var sampleConfig = `
+  ## The id referencing the store in the options, ie, "@{vault:password}".
+  id = "vault"
+
+  ## The address of the Vault server.
+  address = "https://vault.example.com:8200"
+
+  ## The token authenticating the requests, either given or read from a
+  ## file, ie, the sink of a Vault agent.
+  # token = ""
+  # token_file = "/var/run/vault/token"
+
+  ## The path of the secret, the keys are the keys of its data. Both the KV
+  ## version 1 and version 2 engines are supported, the path of a version 2
+  ## secret includes "data/", ie, "secret/data/telegraf".
+  path = "secret/data/telegraf"
+
+  ## The timeout of the requests.
+  # timeout = "5s"
+
+  ## Optional SSL Config
+  # ssl_ca = "/etc/telegraf/ca.pem"
+  # ssl_cert = "/etc/telegraf/cert.pem"
+  # ssl_key = "/etc/telegraf/key.pem"
+  ## Use SSL but skip chain & host verification
+  # insecure_skip_verify = false
+`
+
+func (v *Vault) SampleConfig() string {
+	return sampleConfig
+}
+
+func (v *Vault) Description() string {
+	return "Read the secrets from a HashiCorp Vault key/value secret."
+}
+
+func (v *Vault) Init() error {
+	if v.Address == "" {
+		return fmt.Errorf("no address of the Vault server")
+	}
+	if v.Path == "" {
+		return fmt.Errorf("no path of the secret")
+	}
+	if v.Token == "" && v.TokenFile == "" {
+		return fmt.Errorf("no token or token_file authenticating the requests")
+	}
+	if v.TokenFile != "" {
+		token, err := ioutil.ReadFile(v.TokenFile)
+		if err != nil {
+			return err
+		}
+		v.Token = strings.TrimSpace(string(token))
+	}
+
+	tlsCfg, err := internal.GetTLSConfig(v.SSLCert, v.SSLKey, v.SSLCA, v.InsecureSkipVerify)
+	if err != nil {
+		return err
+	}
+	v.client = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsCfg,
+		},
+		Timeout: v.Timeout.Duration,
+	}
+	return nil
+}
+
+func (v *Vault) Get(key string) (string, error) {
+	if v.data == nil {
+		data, err := v.read()
+		if err != nil {
+			return "", err
+		}
+		v.data = data
+	}
+
+	value, ok := v.data[key]
+	if !ok {
+		return "", fmt.Errorf("no key %q in the secret %s", key, v.Path)
+	}
+	switch value := value.(type) {
+	case string:
+		return value, nil
+	case float64, bool:
+		return fmt.Sprint(value), nil
+	default:
+		return "", fmt.Errorf("key %q of the secret %s is not a string", key, v.Path)
+	}
+}
+
+// response is the response of a read of a KV secret. The data of a version
+// 2 secret is nested along with its metadata.
+type response struct {
+	Data struct {
+		Data     map[string]interface{} `json:"data"`
+		Metadata map[string]interface{} `json:"metadata"`
+	} `json:"data"`
+}
+
+func (v *Vault) read() (map[string]interface{}, error) {
+	url := strings.TrimSuffix(v.Address, "/") + "/v1/" + strings.TrimPrefix(v.Path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.Token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading the secret %s returned HTTP status %s",
+			v.Path, resp.Status)
+	}
+
+	var r response
+	if err := json.Unmarshal(body, &r); err != nil {
+		return nil, fmt.Errorf("invalid response reading the secret %s: %s", v.Path, err)
+	}
+	if r.Data.Metadata != nil {
+		return r.Data.Data, nil
+	}
+
+	// version 1 secrets hold their data directly
+	var v1 struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &v1); err != nil {
+		return nil, fmt.Errorf("invalid response reading the secret %s: %s", v.Path, err)
+	}
+	return v1.Data, nil
+}
+
+func init() {
+	secretstores.Add("vault", func() telegraf.SecretStore {
+		return &Vault{
+			Timeout: internal.Duration{Duration: 5 * time.Second},
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package vault
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/telegraf" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+}
+
+func newVault(t *testing.T, address string) *Vault {
+	v := &Vault{
+		Address: address,
+		Token:   "token",
+		Path:    "secret/data/telegraf",
+	}
+	require.NoError(t, v.Init())
+	return v
+}
+
+func TestGetVersion2(t *testing.T) {
+	ts := newServer(t, `{"data": {"data": {"password": "secret", "port": 5432}, "metadata": {"version": 3}}}`)
+	defer ts.Close()
+
+	v := newVault(t, ts.URL)
+	secret, err := v.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+
+	port, err := v.Get("port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", port)
+
+	_, err = v.Get("token")
+	assert.Error(t, err)
+}
+
+func TestGetVersion1(t *testing.T) {
+	ts := newServer(t, `{"data": {"password": "secret"}}`)
+	defer ts.Close()
+
+	v := newVault(t, ts.URL)
+	secret, err := v.Get("password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", secret)
+}
+
+func TestGetForbidden(t *testing.T) {
+	ts := newServer(t, `{}`)
+	defer ts.Close()
+
+	v := newVault(t, ts.URL)
+	v.Token = "other"
+	_, err := v.Get("password")
+	assert.Error(t, err)
+}
+
+func TestInit(t *testing.T) {
+	v := &Vault{Path: "secret/telegraf", Token: "token"}
+	assert.Error(t, v.Init())
+
+	v = &Vault{Address: "http://localhost:8200", Token: "token"}
+	assert.Error(t, v.Init())
+
+	v = &Vault{Address: "http://localhost:8200", Path: "secret/telegraf"}
+	assert.Error(t, v.Init())
+}
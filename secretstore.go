@@ -0,0 +1,15 @@
+package telegraf
+
+// SecretStore resolves the secrets referenced in the plugin options as
+// @{store:key}, so that credentials do not have to be written in the
+// configuration files.
+type SecretStore interface {
+	// SampleConfig returns the default configuration of the SecretStore
+	SampleConfig() string
+
+	// Description returns a one-sentence description on the SecretStore
+	Description() string
+
+	// Get returns the secret of the given key
+	Get(key string) (string, error)
+}
@@ -0,0 +1,36 @@
+package testutil
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Logger is a telegraf.Logger keeping the lines logged by a plugin, with
+// their level prefix, ie, "D! GET http://localhost".
+type Logger struct {
+	sync.Mutex
+	Lines []string
+}
+
+func (l *Logger) print(level, msg string) {
+	l.Lock()
+	defer l.Unlock()
+	l.Lines = append(l.Lines, level+"! "+msg)
+}
+
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.print("E", fmt.Sprintf(format, args...))
+}
+func (l *Logger) Error(args ...interface{}) { l.print("E", fmt.Sprint(args...)) }
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.print("W", fmt.Sprintf(format, args...))
+}
+func (l *Logger) Warn(args ...interface{}) { l.print("W", fmt.Sprint(args...)) }
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.print("I", fmt.Sprintf(format, args...))
+}
+func (l *Logger) Info(args ...interface{}) { l.print("I", fmt.Sprint(args...)) }
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.print("D", fmt.Sprintf(format, args...))
+}
+func (l *Logger) Debug(args ...interface{}) { l.print("D", fmt.Sprint(args...)) }